@@ -0,0 +1,40 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package v1beta1
+
+// Condition types reported in the status of the worker resources. Ready summarizes the other
+// conditions a resource reports, so `kubectl wait --for=condition=Ready` can be used on all of them.
+const (
+	// ConditionReady reports whether the resource is fully reconciled and serving traffic
+	ConditionReady = "Ready"
+	// ConditionTunnelUp reports whether the gateway tunnels to the remote clusters are up
+	ConditionTunnelUp = "TunnelUp"
+	// ConditionRouterConnected reports whether the slice router is running and connected to the gateways
+	ConditionRouterConnected = "RouterConnected"
+	// ConditionDNSReady reports whether the slice DNS serves the records of the resource
+	ConditionDNSReady = "DNSReady"
+	// ConditionPoliciesApplied reports whether the network policies of the slice are installed
+	ConditionPoliciesApplied = "PoliciesApplied"
+	// ConditionEndpointsAvailable reports whether the service has at least one endpoint
+	ConditionEndpointsAvailable = "EndpointsAvailable"
+	// ConditionQosApplied reports whether the netop on every node applied the QoS profile of the slice
+	ConditionQosApplied = "QosApplied"
+	// ConditionSubnetsValid reports whether the slice and gateway subnets are free of overlaps with the cluster CIDRs
+	ConditionSubnetsValid = "SubnetsValid"
+)
@@ -124,6 +124,10 @@ type ServiceExportStatus struct {
 	// Alias names for the exported service. The service could be addressed by the alias names
 	// in addition to the slice.local name.
 	Aliases []string `json:"aliases,omitempty"`
+	// Conditions of the serviceexport
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -135,6 +139,7 @@ type ServiceExportStatus struct {
 // +kubebuilder:printcolumn:name="Endpoints",type=integer,JSONPath=`.status.availableEndpoints`
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.exportStatus`
 // +kubebuilder:printcolumn:name="Alias",type=string,JSONPath=`.spec.aliases`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:resource:path=serviceexports,singular=serviceexport,shortName=svcex
 
 // ServiceExport is the Schema for the serviceexports API
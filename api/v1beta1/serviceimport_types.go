@@ -78,6 +78,10 @@ type ServiceImportStatus struct {
 	AvailableEndpoints int `json:"availableEndpoints,omitempty"`
 	// Endpoints which provide the service
 	Endpoints []ServiceEndpoint `json:"endpoints,omitempty"`
+	// Conditions of the serviceimport
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -87,6 +91,7 @@ type ServiceImportStatus struct {
 // +kubebuilder:printcolumn:name="Endpoints",type=integer,JSONPath=`.status.availableEndpoints`
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.importStatus`
 // +kubebuilder:printcolumn:name="Alias",type=string,JSONPath=`.spec.aliases`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:resource:path=serviceimports,singular=serviceimport,shortName=svcim
 
 // ServiceImport is the Schema for the serviceimports API
@@ -25,6 +25,29 @@ import (
 
 // SliceSpec defines the desired state of Slice
 type SliceSpec struct {
+	// ApplicationNamespaceSelector onboards every namespace matching the selector to the slice,
+	// in addition to the application namespaces received from the hub cluster
+	// +optional
+	ApplicationNamespaceSelector *metav1.LabelSelector `json:"applicationNamespaceSelector,omitempty"`
+	// ConnectivityProbe enables synthetic probes from this cluster to the slice routers of the remote
+	// clusters and to the remote endpoints of selected serviceimports
+	// +optional
+	ConnectivityProbe *ConnectivityProbe `json:"connectivityProbe,omitempty"`
+	// Maintenance freezes the slice on this cluster for a planned change. The slice router, gateways
+	// and recyclers of the slice are not restarted, rebalanced, recycled or sent QoS profiles while
+	// it is set, the status of the slice components is still collected.
+	// +optional
+	Maintenance bool `json:"maintenance,omitempty"`
+}
+
+// ConnectivityProbe configures the connectivity probes of the slice
+type ConnectivityProbe struct {
+	// Interval between two rounds of probes, defaults to 30s
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// ServiceImports whose remote endpoints are probed over tcp, as <namespace>/<name>
+	// +optional
+	ServiceImports []string `json:"serviceImports,omitempty"`
 }
 
 // QosProfileDetails is the QOS Profile for the slice
@@ -131,14 +154,104 @@ type SliceStatus struct {
 	// NetworkPoliciesInstalled defines whether the netpol are installed in atleast one applicationNamespace
 	// +kubebuilder:default:=false
 	NetworkPoliciesInstalled bool `json:"networkPoliciesInstalled,omitempty"`
+	// NetworkPolicyBackend is the backend that installed the isolation policies of the slice
+	NetworkPolicyBackend string `json:"networkPolicyBackend,omitempty"`
 	// Slice Application Namespace list
 	ApplicationNamespaces []string `json:"applicationNamespaces,omitempty"`
 	// Slice Allowed Namespace list
 	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+	// OffboardingNamespaces contains the application namespaces that are leaving the slice
+	OffboardingNamespaces []OffboardingNamespace `json:"offboardingNamespaces,omitempty"`
+	// ProbeResults are the results of the last connectivity probes to the remote clusters
+	ProbeResults []ProbeResult `json:"probeResults,omitempty"`
+	// SubnetOverlaps are the slice and gateway subnets that overlap with the pod, service or other
+	// excluded CIDRs of this cluster. The slice router and gateways are not deployed while there are any.
+	SubnetOverlaps []SubnetOverlap `json:"subnetOverlaps,omitempty"`
+	// Conditions of the slice on this worker cluster
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// SliceConditionHubConnected reports whether the hub cluster is reachable. The slice keeps running
+	// with its last known configuration while it is not.
+	SliceConditionHubConnected = "HubConnected"
+	// SliceConditionMaintenance reports whether the slice is in maintenance on this cluster
+	SliceConditionMaintenance = "Maintenance"
+)
+
+// OffboardingPhase is the phase of an application namespace leaving the slice
+type OffboardingPhase string
+
+const (
+	// OffboardingPhaseDraining means new serviceexports are rejected while the drain period elapses
+	OffboardingPhaseDraining OffboardingPhase = "Draining"
+	// OffboardingPhaseRollingWorkloads means the workloads are being restarted to detach their pods from the slice
+	OffboardingPhaseRollingWorkloads OffboardingPhase = "RollingWorkloads"
+	// OffboardingPhaseWaitingForWorkloads means the pods of workloads that cannot be restarted are still attached
+	// to the slice, until they complete or are deleted
+	OffboardingPhaseWaitingForWorkloads OffboardingPhase = "WaitingForWorkloads"
+)
+
+// OffboardingNamespace tracks the progress of an application namespace leaving the slice
+type OffboardingNamespace struct {
+	// Namespace is the name of the application namespace
+	Namespace string `json:"namespace"`
+	// Phase is the current offboarding phase of the namespace
+	Phase OffboardingPhase `json:"phase"`
+	// StartedOn is the time when offboarding started
+	StartedOn int64 `json:"startedOn,omitempty"`
+	// Message describes the progress of the current phase
+	Message string `json:"message,omitempty"`
+	// UnrestartableWorkloads are the workloads whose pods are still attached to the slice and cannot be
+	// restarted by the operator
+	UnrestartableWorkloads []OffboardingWorkload `json:"unrestartableWorkloads,omitempty"`
+}
+
+// OffboardingWorkload is a workload of an offboarding namespace with pods attached to the slice
+type OffboardingWorkload struct {
+	// Kind of the workload, eg: Job or Pod for pods without a controller
+	Kind string `json:"kind"`
+	// Name of the workload
+	Name string `json:"name"`
+	// Pods are the pods of the workload that are attached to the slice
+	Pods []string `json:"pods,omitempty"`
+}
+
+// SubnetOverlap is a slice or gateway subnet that overlaps with a CIDR used in the cluster
+type SubnetOverlap struct {
+	// Name of the subnet, eg: sliceSubnet, clusterSubnetCIDR or sliceGatewayRemoteSubnet/<slice gateway>
+	Name string `json:"name"`
+	// Subnet is the overlapping slice or gateway subnet
+	Subnet string `json:"subnet"`
+	// ClusterCIDR is the pod, service or other excluded CIDR of the cluster the subnet overlaps with
+	ClusterCIDR string `json:"clusterCIDR"`
+}
+
+// ProbeResult is the result of the connectivity probe of a remote target
+type ProbeResult struct {
+	// Name of the target, eg: router or the name of the serviceimport endpoint
+	Name string `json:"name"`
+	// RemoteCluster is the cluster of the target
+	RemoteCluster string `json:"remoteCluster"`
+	// Address is the probed slice address of the target, with the port for tcp probes
+	Address string `json:"address"`
+	// Protocol is icmp or tcp
+	Protocol string `json:"protocol"`
+	// Reachable is whether the target answered the probe
+	Reachable bool `json:"reachable"`
+	// RTTMicroseconds is the round trip time of the probe
+	RTTMicroseconds int64 `json:"rttMicroseconds,omitempty"`
+	// Error is why the target did not answer
+	Error string `json:"error,omitempty"`
+	// LastProbed is the time of the probe
+	LastProbed metav1.Time `json:"lastProbed"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 //+kubebuilder:resource:path=slices,singular=slice
 
 // Slice is the Schema for the slices API
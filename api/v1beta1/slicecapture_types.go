@@ -0,0 +1,156 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CaptureTarget selects the pods of a slice in which the packets are captured
+// +kubebuilder:validation:Enum=Slice;Gateway;Router
+type CaptureTarget string
+
+const (
+	// CaptureTargetSlice captures in every gateway and router pod of the slice
+	CaptureTargetSlice CaptureTarget = "Slice"
+	// CaptureTargetGateway captures in the pods of one slice gateway
+	CaptureTargetGateway CaptureTarget = "Gateway"
+	// CaptureTargetRouter captures in the slice router pods
+	CaptureTargetRouter CaptureTarget = "Router"
+)
+
+// CaptureStorageType is where the pcap files of a capture are stored
+// +kubebuilder:validation:Enum=Secret;PersistentVolumeClaim
+type CaptureStorageType string
+
+const (
+	// CaptureStorageSecret stores the pcap files in the <capture>-pcap secret
+	CaptureStorageSecret CaptureStorageType = "Secret"
+	// CaptureStoragePersistentVolumeClaim copies the pcap files in the <capture> directory of a PVC
+	CaptureStoragePersistentVolumeClaim CaptureStorageType = "PersistentVolumeClaim"
+)
+
+// CaptureStorage defines where the pcap files are stored
+type CaptureStorage struct {
+	// Type of the storage
+	// +kubebuilder:default:=Secret
+	Type CaptureStorageType `json:"type,omitempty"`
+	// ClaimName is the PVC in the namespace of the capture, required for PersistentVolumeClaim storage
+	ClaimName string `json:"claimName,omitempty"`
+}
+
+// SliceCaptureSpec defines the desired state of SliceCapture
+type SliceCaptureSpec struct {
+	// SliceName is the slice in which the packets are captured
+	SliceName string `json:"sliceName"`
+	// Target selects the pods of the slice in which the packets are captured
+	// +kubebuilder:default:=Slice
+	Target CaptureTarget `json:"target,omitempty"`
+	// Gateway is the slice gateway to capture in, required for the Gateway target
+	Gateway string `json:"gateway,omitempty"`
+	// Filter is the BPF filter passed to tcpdump
+	Filter string `json:"filter,omitempty"`
+	// Interface is the interface to capture on, all interfaces if not set
+	Interface string `json:"interface,omitempty"`
+	// Duration of the capture, 60s if not set
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// MaxSize caps the pcap file of each pod, 512Ki if not set. The pcap files of a capture
+	// are staged in a secret, so they are capped to 1Mi in total.
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+	// Storage defines where the pcap files are stored
+	Storage CaptureStorage `json:"storage,omitempty"`
+}
+
+// CapturePhase is the phase of a SliceCapture
+type CapturePhase string
+
+const (
+	// CapturePhasePending means the target pods are not resolved yet
+	CapturePhasePending CapturePhase = "Pending"
+	// CapturePhaseRunning means the capture containers are running in the target pods
+	CapturePhaseRunning CapturePhase = "Running"
+	// CapturePhaseCollecting means the pcap files are being copied to the PVC
+	CapturePhaseCollecting CapturePhase = "Collecting"
+	// CapturePhaseSucceeded means the pcap files are stored
+	CapturePhaseSucceeded CapturePhase = "Succeeded"
+	// CapturePhaseFailed means no pcap file could be stored
+	CapturePhaseFailed CapturePhase = "Failed"
+)
+
+// PodCapture is the capture of one target pod
+type PodCapture struct {
+	// Pod is the name of the target pod
+	Pod string `json:"pod"`
+	// Container is the name of the ephemeral container running the capture
+	Container string `json:"container"`
+	// Size is the size of the pcap file in bytes
+	Size int64 `json:"size,omitempty"`
+	// Completed is true once the pcap file of the pod is collected or the capture failed
+	Completed bool `json:"completed,omitempty"`
+	// Message explains why the capture of the pod failed
+	Message string `json:"message,omitempty"`
+}
+
+// SliceCaptureStatus defines the observed state of SliceCapture
+type SliceCaptureStatus struct {
+	// Phase of the capture
+	Phase CapturePhase `json:"phase,omitempty"`
+	// StartTime is the time the capture containers were started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the capture succeeded or failed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Captures are the captures of the target pods
+	Captures []PodCapture `json:"captures,omitempty"`
+	// Location of the pcap files, secret/<name> or pvc/<claim>/<directory>
+	Location string `json:"location,omitempty"`
+	// Message explains the phase of the capture
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Slice",type=string,JSONPath=`.spec.sliceName`
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.target`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Location",type=string,JSONPath=`.status.location`
+// +kubebuilder:resource:path=slicecaptures,singular=slicecapture,shortName=scap
+
+// SliceCapture is the Schema for the slicecaptures API. It runs a time-boxed packet capture
+// in the gateway or router pods of a slice.
+type SliceCapture struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SliceCaptureSpec   `json:"spec,omitempty"`
+	Status SliceCaptureStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SliceCaptureList contains a list of SliceCapture
+type SliceCaptureList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SliceCapture `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SliceCapture{}, &SliceCaptureList{})
+}
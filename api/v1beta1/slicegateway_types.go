@@ -95,6 +95,24 @@ type SliceGatewayStatus struct {
 	ConnectionContextUpdatedOn int64 `json:"connectionContextUpdatedOn,omitempty"`
 	//gatewayPodStatus is a list that consists of status of individual gatewaypods
 	GatewayPodStatus []*GwPodInfo `json:"gatewayPodStatus,omitempty"`
+	// BandwidthUsage is the bandwidth used by the slice towards the remote cluster of the gateway
+	BandwidthUsage *BandwidthUsage `json:"bandwidthUsage,omitempty"`
+	// MTU is the MTU the gateway tunnels are configured with
+	MTU *TunnelMTU `json:"mtu,omitempty"`
+	// Conditions of the slice gateway
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// TunnelMTU is the MTU of the gateway tunnels derived from the MTU of the underlay network
+type TunnelMTU struct {
+	// Underlay is the MTU of the network between the gateway nodes of the clusters
+	Underlay int32 `json:"underlay"`
+	// Overlay is the MTU of the tunnel interfaces of the gateways and of the slice interfaces
+	Overlay int32 `json:"overlay"`
+	// MSSFix is the largest size of the encapsulated packets sent by the gateways
+	MSSFix int32 `json:"mssFix"`
 }
 
 // +kubebuilder:object:root=true
@@ -103,6 +121,7 @@ type SliceGatewayStatus struct {
 // +kubebuilder:printcolumn:name="Remote Subnet",type=string,JSONPath=`.status.config.sliceGatewayRemoteSubnet`
 // +kubebuilder:printcolumn:name="Remote Cluster",type=string,JSONPath=`.status.config.sliceGatewayRemoteClusterId`
 // +kubebuilder:printcolumn:name="GW Status",type=string,JSONPath=`.status.config.sliceGatewayStatus`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:resource:path=slicegateways,singular=slicegateway,shortName=gw;slicegw
 
 // SliceGateway is the Schema for the slicegateways API
@@ -140,6 +159,28 @@ type GwPodInfo struct {
 	RemotePort int32 `json:"remotePort,omitempty"`
 }
 
+// BandwidthUsage is the bandwidth used by the tunnels of a slice gateway
+type BandwidthUsage struct {
+	// TxRateKbps is the last transmit rate of the tunnels
+	TxRateKbps uint64 `json:"txRateKbps,omitempty"`
+	// RxRateKbps is the last receive rate of the tunnels
+	RxRateKbps uint64 `json:"rxRateKbps,omitempty"`
+	// PeakTxRateKbps is the highest transmit rate in the window
+	PeakTxRateKbps uint64 `json:"peakTxRateKbps,omitempty"`
+	// PeakRxRateKbps is the highest receive rate in the window
+	PeakRxRateKbps uint64 `json:"peakRxRateKbps,omitempty"`
+	// CeilingKbps is the bandwidth ceiling of the slice QoS profile
+	CeilingKbps uint64 `json:"ceilingKbps,omitempty"`
+	// CeilingHits is the number of samples in the window where the transmit rate reached the ceiling
+	CeilingHits int64 `json:"ceilingHits,omitempty"`
+	// Samples is the number of samples in the window
+	Samples int64 `json:"samples,omitempty"`
+	// WindowStart is the start of the window of the peak rates and ceiling hits
+	WindowStart metav1.Time `json:"windowStart,omitempty"`
+	// LastUpdated is the time of the last sample
+	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+}
+
 type TunnelStatus struct {
 	IntfName   string `json:"IntfName,omitempty"`
 	LocalIP    string `json:"LocalIP,omitempty"`
@@ -0,0 +1,100 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkerOperatorImages overrides the images used for the components deployed by the operator
+type WorkerOperatorImages struct {
+	// IstioProxy is the image used for the slice ingress and egress gateways
+	IstioProxy string `json:"istioProxy,omitempty"`
+	// SliceRouter is the image of the slice router (vl3)
+	SliceRouter string `json:"sliceRouter,omitempty"`
+	// SliceRouterSidecar is the image of the slice router sidecar
+	SliceRouterSidecar string `json:"sliceRouterSidecar,omitempty"`
+	// GatewaySidecar is the image of the slice gateway sidecar
+	GatewaySidecar string `json:"gatewaySidecar,omitempty"`
+	// OpenVPNServer is the image of the openvpn server in the slice gateway
+	OpenVPNServer string `json:"openVpnServer,omitempty"`
+	// OpenVPNClient is the image of the openvpn client in the slice gateway
+	OpenVPNClient string `json:"openVpnClient,omitempty"`
+	// SliceGatewayEdge is the image of the slice gateway edge
+	SliceGatewayEdge string `json:"sliceGatewayEdge,omitempty"`
+	// Probe is the image of the connectivity probe pods of the slices
+	Probe string `json:"probe,omitempty"`
+	// Capture is the image of the ephemeral containers running the packet captures of the slicecaptures
+	Capture string `json:"capture,omitempty"`
+}
+
+// WorkerOperatorConfigSpec defines the desired configuration of the worker operator.
+// Fields that are not set fall back to the environment of the operator.
+type WorkerOperatorConfigSpec struct {
+	// ReconcileInterval is the requeue interval of the slice and serviceexport reconcilers
+	ReconcileInterval *metav1.Duration `json:"reconcileInterval,omitempty"`
+	// SliceGatewayReconcileInterval is the requeue interval of the slicegateway reconciler
+	SliceGatewayReconcileInterval *metav1.Duration `json:"sliceGatewayReconcileInterval,omitempty"`
+	// ExcludedNamespaces are not reported to the hub by the namespace reconciler
+	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
+	// Images overrides the images of the components deployed by the operator
+	Images WorkerOperatorImages `json:"images,omitempty"`
+	// FeatureFlags enables or disables features. A flag set here takes precedence over FEATURE_<NAME>.
+	FeatureFlags map[string]bool `json:"featureFlags,omitempty"`
+	// LogLevel of the operator
+	// +kubebuilder:validation:Enum=DEBUG;INFO;WARNING;ERROR
+	LogLevel string `json:"logLevel,omitempty"`
+}
+
+// WorkerOperatorConfigStatus defines the observed state of WorkerOperatorConfig
+type WorkerOperatorConfigStatus struct {
+	// ObservedGeneration is the generation of the spec applied by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// EffectiveConfig is the configuration in use, after falling back to the environment of the operator
+	EffectiveConfig WorkerOperatorConfigSpec `json:"effectiveConfig,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Log Level",type=string,JSONPath=`.status.effectiveConfig.logLevel`
+// +kubebuilder:printcolumn:name="Reconcile Interval",type=string,JSONPath=`.status.effectiveConfig.reconcileInterval`
+// +kubebuilder:resource:path=workeroperatorconfigs,singular=workeroperatorconfig,scope=Cluster,shortName=woc
+
+// WorkerOperatorConfig is the Schema for the workeroperatorconfigs API.
+// Only the object named "default" is applied by the operator.
+type WorkerOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WorkerOperatorConfigSpec   `json:"spec,omitempty"`
+	Status WorkerOperatorConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WorkerOperatorConfigList contains a list of WorkerOperatorConfig
+type WorkerOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []WorkerOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&WorkerOperatorConfig{}, &WorkerOperatorConfigList{})
+}
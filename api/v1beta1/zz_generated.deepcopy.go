@@ -40,6 +40,63 @@ func (in *AppPod) DeepCopy() *AppPod {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthUsage) DeepCopyInto(out *BandwidthUsage) {
+	*out = *in
+	in.WindowStart.DeepCopyInto(&out.WindowStart)
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthUsage.
+func (in *BandwidthUsage) DeepCopy() *BandwidthUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CaptureStorage) DeepCopyInto(out *CaptureStorage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CaptureStorage.
+func (in *CaptureStorage) DeepCopy() *CaptureStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(CaptureStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectivityProbe) DeepCopyInto(out *ConnectivityProbe) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ServiceImports != nil {
+		in, out := &in.ServiceImports, &out.ServiceImports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectivityProbe.
+func (in *ConnectivityProbe) DeepCopy() *ConnectivityProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectivityProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalGatewayConfig) DeepCopyInto(out *ExternalGatewayConfig) {
 	*out = *in
@@ -150,6 +207,79 @@ func (in *NamespaceIsolationProfile) DeepCopy() *NamespaceIsolationProfile {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OffboardingNamespace) DeepCopyInto(out *OffboardingNamespace) {
+	*out = *in
+	if in.UnrestartableWorkloads != nil {
+		in, out := &in.UnrestartableWorkloads, &out.UnrestartableWorkloads
+		*out = make([]OffboardingWorkload, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OffboardingNamespace.
+func (in *OffboardingNamespace) DeepCopy() *OffboardingNamespace {
+	if in == nil {
+		return nil
+	}
+	out := new(OffboardingNamespace)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OffboardingWorkload) DeepCopyInto(out *OffboardingWorkload) {
+	*out = *in
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OffboardingWorkload.
+func (in *OffboardingWorkload) DeepCopy() *OffboardingWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(OffboardingWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodCapture) DeepCopyInto(out *PodCapture) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodCapture.
+func (in *PodCapture) DeepCopy() *PodCapture {
+	if in == nil {
+		return nil
+	}
+	out := new(PodCapture)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeResult) DeepCopyInto(out *ProbeResult) {
+	*out = *in
+	in.LastProbed.DeepCopyInto(&out.LastProbed)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeResult.
+func (in *ProbeResult) DeepCopy() *ProbeResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *QosProfileDetails) DeepCopyInto(out *QosProfileDetails) {
 	*out = *in
@@ -283,6 +413,13 @@ func (in *ServiceExportStatus) DeepCopyInto(out *ServiceExportStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportStatus.
@@ -387,6 +524,13 @@ func (in *ServiceImportStatus) DeepCopyInto(out *ServiceImportStatus) {
 		*out = make([]ServiceEndpoint, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceImportStatus.
@@ -434,7 +578,7 @@ func (in *Slice) DeepCopyInto(out *Slice) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -456,6 +600,119 @@ func (in *Slice) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SliceCapture) DeepCopyInto(out *SliceCapture) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SliceCapture.
+func (in *SliceCapture) DeepCopy() *SliceCapture {
+	if in == nil {
+		return nil
+	}
+	out := new(SliceCapture)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SliceCapture) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SliceCaptureList) DeepCopyInto(out *SliceCaptureList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SliceCapture, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SliceCaptureList.
+func (in *SliceCaptureList) DeepCopy() *SliceCaptureList {
+	if in == nil {
+		return nil
+	}
+	out := new(SliceCaptureList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SliceCaptureList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SliceCaptureSpec) DeepCopyInto(out *SliceCaptureSpec) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	out.Storage = in.Storage
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SliceCaptureSpec.
+func (in *SliceCaptureSpec) DeepCopy() *SliceCaptureSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SliceCaptureSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SliceCaptureStatus) DeepCopyInto(out *SliceCaptureStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Captures != nil {
+		in, out := &in.Captures, &out.Captures
+		*out = make([]PodCapture, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SliceCaptureStatus.
+func (in *SliceCaptureStatus) DeepCopy() *SliceCaptureStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SliceCaptureStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SliceConfig) DeepCopyInto(out *SliceConfig) {
 	*out = *in
@@ -622,6 +879,23 @@ func (in *SliceGatewayStatus) DeepCopyInto(out *SliceGatewayStatus) {
 			}
 		}
 	}
+	if in.BandwidthUsage != nil {
+		in, out := &in.BandwidthUsage, &out.BandwidthUsage
+		*out = new(BandwidthUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MTU != nil {
+		in, out := &in.MTU, &out.MTU
+		*out = new(TunnelMTU)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SliceGatewayStatus.
@@ -684,6 +958,16 @@ func (in *SliceList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SliceSpec) DeepCopyInto(out *SliceSpec) {
 	*out = *in
+	if in.ApplicationNamespaceSelector != nil {
+		in, out := &in.ApplicationNamespaceSelector, &out.ApplicationNamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectivityProbe != nil {
+		in, out := &in.ConnectivityProbe, &out.ConnectivityProbe
+		*out = new(ConnectivityProbe)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SliceSpec.
@@ -719,6 +1003,32 @@ func (in *SliceStatus) DeepCopyInto(out *SliceStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.OffboardingNamespaces != nil {
+		in, out := &in.OffboardingNamespaces, &out.OffboardingNamespaces
+		*out = make([]OffboardingNamespace, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProbeResults != nil {
+		in, out := &in.ProbeResults, &out.ProbeResults
+		*out = make([]ProbeResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SubnetOverlaps != nil {
+		in, out := &in.SubnetOverlaps, &out.SubnetOverlaps
+		*out = make([]SubnetOverlap, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SliceStatus.
@@ -731,6 +1041,36 @@ func (in *SliceStatus) DeepCopy() *SliceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetOverlap) DeepCopyInto(out *SubnetOverlap) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetOverlap.
+func (in *SubnetOverlap) DeepCopy() *SubnetOverlap {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetOverlap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TunnelMTU) DeepCopyInto(out *TunnelMTU) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelMTU.
+func (in *TunnelMTU) DeepCopy() *TunnelMTU {
+	if in == nil {
+		return nil
+	}
+	out := new(TunnelMTU)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TunnelStatus) DeepCopyInto(out *TunnelStatus) {
 	*out = *in
@@ -745,3 +1085,131 @@ func (in *TunnelStatus) DeepCopy() *TunnelStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkerOperatorConfig) DeepCopyInto(out *WorkerOperatorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkerOperatorConfig.
+func (in *WorkerOperatorConfig) DeepCopy() *WorkerOperatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkerOperatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkerOperatorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkerOperatorConfigList) DeepCopyInto(out *WorkerOperatorConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WorkerOperatorConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkerOperatorConfigList.
+func (in *WorkerOperatorConfigList) DeepCopy() *WorkerOperatorConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkerOperatorConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkerOperatorConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkerOperatorConfigSpec) DeepCopyInto(out *WorkerOperatorConfigSpec) {
+	*out = *in
+	if in.ReconcileInterval != nil {
+		in, out := &in.ReconcileInterval, &out.ReconcileInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.SliceGatewayReconcileInterval != nil {
+		in, out := &in.SliceGatewayReconcileInterval, &out.SliceGatewayReconcileInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ExcludedNamespaces != nil {
+		in, out := &in.ExcludedNamespaces, &out.ExcludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Images = in.Images
+	if in.FeatureFlags != nil {
+		in, out := &in.FeatureFlags, &out.FeatureFlags
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkerOperatorConfigSpec.
+func (in *WorkerOperatorConfigSpec) DeepCopy() *WorkerOperatorConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkerOperatorConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkerOperatorConfigStatus) DeepCopyInto(out *WorkerOperatorConfigStatus) {
+	*out = *in
+	in.EffectiveConfig.DeepCopyInto(&out.EffectiveConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkerOperatorConfigStatus.
+func (in *WorkerOperatorConfigStatus) DeepCopy() *WorkerOperatorConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkerOperatorConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkerOperatorImages) DeepCopyInto(out *WorkerOperatorImages) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkerOperatorImages.
+func (in *WorkerOperatorImages) DeepCopy() *WorkerOperatorImages {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkerOperatorImages)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,115 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Condition returns a condition of the given type for an object at the given generation
+func Condition(conditionType string, status bool, reason, message string, generation int64) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	}
+	if status {
+		condition.Status = metav1.ConditionTrue
+	}
+	return condition
+}
+
+// ReadyCondition derives the Ready condition from the conditions of the given types. The object is
+// ready when all of them are true, otherwise the first condition that is not true gives the reason.
+func ReadyCondition(conditions []metav1.Condition, generation int64, types ...string) metav1.Condition {
+	for _, t := range types {
+		c := meta.FindStatusCondition(conditions, t)
+		if c == nil {
+			return metav1.Condition{
+				Type:               kubeslicev1beta1.ConditionReady,
+				Status:             metav1.ConditionUnknown,
+				Reason:             "Reconciling",
+				Message:            fmt.Sprintf("%s condition not reported yet", t),
+				ObservedGeneration: generation,
+			}
+		}
+		if c.Status != metav1.ConditionTrue {
+			return metav1.Condition{
+				Type:               kubeslicev1beta1.ConditionReady,
+				Status:             metav1.ConditionFalse,
+				Reason:             c.Reason,
+				Message:            fmt.Sprintf("%s: %s", t, c.Message),
+				ObservedGeneration: generation,
+			}
+		}
+	}
+	return Condition(kubeslicev1beta1.ConditionReady, true, "Reconciled", "All conditions are met", generation)
+}
+
+// SetConditions sets the given conditions in the status of obj. When readyFrom is not empty the Ready
+// condition is derived from the conditions of those types. conditions must point into obj, the object
+// is read again on conflicts. The status is only updated when a condition changes.
+func SetConditions(ctx context.Context, c client.Client, obj client.Object, conditions *[]metav1.Condition, readyFrom []string, updates ...metav1.Condition) error {
+	merge := func() []metav1.Condition {
+		merged := append([]metav1.Condition{}, *conditions...)
+		for _, condition := range updates {
+			meta.SetStatusCondition(&merged, condition)
+		}
+		if len(readyFrom) > 0 {
+			meta.SetStatusCondition(&merged, ReadyCondition(merged, obj.GetGeneration(), readyFrom...))
+		}
+		return merged
+	}
+	if !conditionsChanged(*conditions, merge()) {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			return err
+		}
+		merged := merge()
+		if equality.Semantic.DeepEqual(*conditions, merged) {
+			return nil
+		}
+		*conditions = merged
+		return c.Status().Update(ctx, obj)
+	})
+}
+
+// conditionsChanged returns true if the status, reason, message or observed generation of a condition changed
+func conditionsChanged(old, updated []metav1.Condition) bool {
+	for _, condition := range updated {
+		existing := meta.FindStatusCondition(old, condition.Type)
+		if existing == nil || existing.Status != condition.Status || existing.Reason != condition.Reason ||
+			existing.Message != condition.Message || existing.ObservedGeneration != condition.ObservedGeneration {
+			return true
+		}
+	}
+	return false
+}
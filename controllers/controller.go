@@ -161,6 +161,13 @@ func GetSliceRouterPodNameAndIP(ctx context.Context, c client.Client, sliceName
 	return "", "", nil
 }
 
+// SliceInMaintenance returns true if the slice is frozen on this cluster. The mutating actions on the
+// slice components, such as restarts, rebalancing, recycling and QoS pushes, are suspended while the
+// status of the components is still collected.
+func SliceInMaintenance(slice *kubeslicev1beta1.Slice) bool {
+	return slice != nil && slice.Spec.Maintenance
+}
+
 // SliceAppNamespaceConfigured returns true if the namespace is present in the application namespace list
 // configured for the slice
 func SliceAppNamespaceConfigured(ctx context.Context, slice string, namespace string) (bool, error) {
@@ -180,7 +187,15 @@ func SliceAppNamespaceConfigured(ctx context.Context, slice string, namespace st
 		return false, err
 	}
 
-	for _, ns := range s.Status.SliceConfig.NamespaceIsolationProfile.ApplicationNamespaces {
+	if s.Status.SliceConfig != nil && s.Status.SliceConfig.NamespaceIsolationProfile != nil {
+		for _, ns := range s.Status.SliceConfig.NamespaceIsolationProfile.ApplicationNamespaces {
+			if ns == namespace {
+				return true, nil
+			}
+		}
+	}
+	// namespaces onboarded through the namespace selector of the slice
+	for _, ns := range s.Status.ApplicationNamespaces {
 		if ns == namespace {
 			return true, nil
 		}
@@ -202,6 +217,7 @@ func ContructNetworkPolicyObject(ctx context.Context, slice *kubeslicev1beta1.Sl
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      slice.Name + "-" + appNs,
 			Namespace: appNs,
+			Labels:    map[string]string{ApplicationNamespaceSelectorLabelKey: slice.Name},
 		},
 		Spec: networkingv1.NetworkPolicySpec{
 			PodSelector: metav1.LabelSelector{},
@@ -234,15 +250,7 @@ func ContructNetworkPolicyObject(ctx context.Context, slice *kubeslicev1beta1.Sl
 		},
 	}
 
-	var cfgAllowedNsList []string
-	cfgAllowedNsList = slice.Status.SliceConfig.NamespaceIsolationProfile.AllowedNamespaces
-	// traffic from "kubeslice-system","istio-system","kube-system" namespaces is allowed by default
-	for _, v := range allowedNamespacesByDefault {
-		if !exists(cfgAllowedNsList, v) {
-			cfgAllowedNsList = append(cfgAllowedNsList, v)
-		}
-	}
-	for _, allowedNs := range cfgAllowedNsList {
+	for _, allowedNs := range GetSliceAllowedNamespaces(slice) {
 		ingressRule := networkingv1.NetworkPolicyPeer{
 			NamespaceSelector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{AllowedNamespaceSelectorLabelKey: allowedNs},
@@ -260,6 +268,21 @@ func ContructNetworkPolicyObject(ctx context.Context, slice *kubeslicev1beta1.Sl
 	return netPolicy
 }
 
+// GetSliceAllowedNamespaces returns the namespaces that can send and receive traffic to the app namespaces
+// of the slice. Traffic from "kubeslice-system","istio-system","kube-system" namespaces is allowed by default.
+func GetSliceAllowedNamespaces(slice *kubeslicev1beta1.Slice) []string {
+	var cfgAllowedNsList []string
+	if slice.Status.SliceConfig != nil && slice.Status.SliceConfig.NamespaceIsolationProfile != nil {
+		cfgAllowedNsList = append(cfgAllowedNsList, slice.Status.SliceConfig.NamespaceIsolationProfile.AllowedNamespaces...)
+	}
+	for _, v := range allowedNamespacesByDefault {
+		if !exists(cfgAllowedNsList, v) {
+			cfgAllowedNsList = append(cfgAllowedNsList, v)
+		}
+	}
+	return cfgAllowedNsList
+}
+
 func exists(i []string, o string) bool {
 	for _, v := range i {
 		if v == o {
@@ -0,0 +1,69 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package serviceexport
+
+import (
+	"context"
+	"fmt"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// serviceExportReadyConditions are the conditions the Ready condition of the serviceexport is derived from
+var serviceExportReadyConditions = []string{
+	kubeslicev1beta1.ConditionEndpointsAvailable,
+	kubeslicev1beta1.ConditionDNSReady,
+}
+
+// serviceExportConditions returns the conditions for the current status of the serviceexport. The
+// slice.local name of the service resolves on the slice once the serviceexport is published to the hub.
+func serviceExportConditions(serviceexport *kubeslicev1beta1.ServiceExport, onSlice bool) []metav1.Condition {
+	generation := serviceexport.Generation
+	endpoints := controllers.Condition(kubeslicev1beta1.ConditionEndpointsAvailable, false, "NoEndpoints",
+		"No ready pods match the selector of the serviceexport", generation)
+	if serviceexport.Status.AvailableEndpoints > 0 {
+		endpoints = controllers.Condition(kubeslicev1beta1.ConditionEndpointsAvailable, true, "EndpointsAvailable",
+			fmt.Sprintf("%d endpoints are available", serviceexport.Status.AvailableEndpoints), generation)
+	}
+
+	var dns metav1.Condition
+	switch {
+	case !onSlice:
+		dns = controllers.Condition(kubeslicev1beta1.ConditionDNSReady, false, "NamespaceNotOnSlice",
+			"Namespace of the serviceexport is not an application namespace of the slice", generation)
+	case serviceexport.Status.ExportStatus == kubeslicev1beta1.ExportStatusError:
+		dns = controllers.Condition(kubeslicev1beta1.ConditionDNSReady, false, "HubSyncFailed",
+			"Failed to publish the serviceexport to the hub cluster", generation)
+	case serviceexport.Status.LastSync == 0:
+		dns = controllers.Condition(kubeslicev1beta1.ConditionDNSReady, false, "HubSyncPending",
+			"Serviceexport is not published to the hub cluster yet", generation)
+	default:
+		dns = controllers.Condition(kubeslicev1beta1.ConditionDNSReady, true, "PublishedToSlice",
+			serviceexport.Status.DNSName+" is published to the slice", generation)
+	}
+	return []metav1.Condition{endpoints, dns}
+}
+
+// updateServiceExportConditions sets the conditions of the serviceexport
+func (r *Reconciler) updateServiceExportConditions(ctx context.Context, serviceexport *kubeslicev1beta1.ServiceExport, onSlice bool) error {
+	return controllers.SetConditions(ctx, r.Client, serviceexport, &serviceexport.Status.Conditions,
+		serviceExportReadyConditions, serviceExportConditions(serviceexport, onSlice)...)
+}
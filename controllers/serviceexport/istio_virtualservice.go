@@ -128,6 +128,7 @@ func (r *Reconciler) virtualService(serviceexport *kubeslicev1beta1.ServiceExpor
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      virtualServiceName(serviceexport),
 			Namespace: controllers.ControlPlaneNamespace,
+			Labels:    labelsForServiceEntry(serviceexport),
 		},
 		Spec: networkingv1beta1.VirtualService{
 			Hosts: []string{
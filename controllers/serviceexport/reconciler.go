@@ -44,7 +44,10 @@ import (
 	"github.com/kubeslice/worker-operator/controllers"
 	sliceController "github.com/kubeslice/worker-operator/controllers/slice"
 	ossEvents "github.com/kubeslice/worker-operator/events"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
 	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 )
 
@@ -106,6 +109,14 @@ func (r Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resul
 	if requeue {
 		return result, err
 	}
+	// report the state of the serviceexport even if the reconciliation is requeued midway
+	onSlice := true
+	defer func() {
+		if err := r.updateServiceExportConditions(ctx, serviceexport, onSlice); err != nil {
+			log.Error(err, "Failed to update serviceexport conditions")
+		}
+	}()
+
 	// Reconciler running for the first time. Set the initial status here
 	if serviceexport.Status.ExportStatus == kubeslicev1beta1.ExportStatusInitial {
 		serviceexport.Status.DNSName = serviceexport.Name + "." + serviceexport.Namespace + ".svc.slice.local"
@@ -127,11 +138,18 @@ func (r Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resul
 	if err != nil {
 		log.Error(err, "Unable to fetch slice for serviceexport")
 		utils.RecordEvent(ctx, r.EventRecorder, serviceexport, nil, ossEvents.EventServiceExportSliceFetchFailed, controllerName)
-		return ctrl.Result{RequeueAfter: controllers.ReconcileInterval}, nil
+		return ctrl.Result{RequeueAfter: operatorconfig.ReconcileInterval(controllers.ReconcileInterval)}, nil
+	}
+
+	// record the hub project of the slice, to delete the serviceexport from it even after the slice is gone
+	requeue, result, err = r.annotateServiceExportWithProject(ctx, serviceexport, slice, &debugLog)
+	if requeue {
+		return result, err
 	}
 
 	if !isValidNameSpace(serviceexport.Namespace, slice) {
 		log.Error(fmt.Errorf("serviceexport ns is not part of the slice"), "couldn't onboard serviceexport")
+		onSlice = false
 		if serviceexport.Status.ExportStatus != kubeslicev1beta1.ExportStatusPending {
 			serviceexport.Status.ExportStatus = kubeslicev1beta1.ExportStatusPending
 			if err := r.Status().Update(ctx, serviceexport); err != nil {
@@ -139,7 +157,7 @@ func (r Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resul
 			}
 		}
 		utils.RecordEvent(ctx, r.EventRecorder, serviceexport, nil, ossEvents.EventServiceExportStatusPending, controllerName)
-		return ctrl.Result{RequeueAfter: controllers.ReconcileInterval}, nil
+		return ctrl.Result{RequeueAfter: operatorconfig.ReconcileInterval(controllers.ReconcileInterval)}, nil
 	}
 
 	if serviceexport.Status.ExposedPorts != portListToDisplayString(serviceexport.Spec.Ports) {
@@ -287,7 +305,7 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&kubeslicev1beta1.Slice{},
 			handler.EnqueueRequestsFromMapFunc(r.mapServiceExportsToSlice),
 		).
-		Complete(r)
+		Complete(tracing.NewReconciler("serviceexport", r))
 }
 
 // enqueue requests with service exports belonging under given slice
@@ -386,3 +404,24 @@ func (r *Reconciler) labelServiceExportWithSlice(ctx context.Context, serviceexp
 	}
 	return false, ctrl.Result{}, nil
 }
+
+// annotateServiceExportWithProject copies the hub project annotation of the slice to the serviceexport object
+// returns requeue flag (to either requeue or stop requeing), the reconcilation result and error
+func (r *Reconciler) annotateServiceExportWithProject(ctx context.Context, serviceexport *kubeslicev1beta1.ServiceExport, slice *kubeslicev1beta1.Slice, debugLog *logr.Logger) (bool, ctrl.Result, error) {
+	project, ok := slice.GetAnnotations()[hub.ProjectAnnotation]
+	if !ok || serviceexport.GetAnnotations()[hub.ProjectAnnotation] == project {
+		return false, ctrl.Result{}, nil
+	}
+	annotations := serviceexport.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[hub.ProjectAnnotation] = project
+	serviceexport.SetAnnotations(annotations)
+
+	if err := r.Update(ctx, serviceexport); err != nil {
+		return true, ctrl.Result{}, err
+	}
+	debugLog.Info("Added hub project annotation for serviceexport", "serviceexport", serviceexport.Name, "project", project)
+	return true, ctrl.Result{Requeue: true}, nil
+}
@@ -0,0 +1,71 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package serviceimport
+
+import (
+	"context"
+	"fmt"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// serviceImportReadyConditions are the conditions the Ready condition of the serviceimport is derived from
+var serviceImportReadyConditions = []string{
+	kubeslicev1beta1.ConditionEndpointsAvailable,
+	kubeslicev1beta1.ConditionDNSReady,
+}
+
+// serviceImportConditions returns the conditions for the current status of the serviceimport. The
+// endpoints of the serviceimport are served by the slice DNS, slice is nil if it does not exist.
+func serviceImportConditions(serviceimport *kubeslicev1beta1.ServiceImport, slice *kubeslicev1beta1.Slice) []metav1.Condition {
+	generation := serviceimport.Generation
+	endpoints := controllers.Condition(kubeslicev1beta1.ConditionEndpointsAvailable, false, "NoEndpoints",
+		"No cluster exports the service", generation)
+	if n := len(serviceimport.Status.Endpoints); n > 0 {
+		endpoints = controllers.Condition(kubeslicev1beta1.ConditionEndpointsAvailable, true, "EndpointsAvailable",
+			fmt.Sprintf("%d endpoints are available", n), generation)
+	}
+
+	var dns metav1.Condition
+	switch {
+	case slice == nil:
+		dns = controllers.Condition(kubeslicev1beta1.ConditionDNSReady, false, "SliceNotFound",
+			"Slice of the serviceimport does not exist on this cluster", generation)
+	case slice.Status.DNSIP == "":
+		dns = controllers.Condition(kubeslicev1beta1.ConditionDNSReady, false, "SliceDNSNotReady",
+			"Slice DNS service is not created yet", generation)
+	default:
+		dns = controllers.Condition(kubeslicev1beta1.ConditionDNSReady, true, "SliceDNSReady",
+			fmt.Sprintf("%s is served by the slice DNS at %s", serviceimport.Spec.DNSName, slice.Status.DNSIP), generation)
+	}
+	return []metav1.Condition{endpoints, dns}
+}
+
+// updateServiceImportConditions sets the conditions of the serviceimport
+func (r *Reconciler) updateServiceImportConditions(ctx context.Context, serviceimport *kubeslicev1beta1.ServiceImport) error {
+	slice, err := controllers.GetSlice(ctx, r.Client, serviceimport.Spec.Slice)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return controllers.SetConditions(ctx, r.Client, serviceimport, &serviceimport.Status.Conditions,
+		serviceImportReadyConditions, serviceImportConditions(serviceimport, slice)...)
+}
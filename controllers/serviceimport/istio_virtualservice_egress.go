@@ -128,6 +128,7 @@ func (r *Reconciler) virtualServiceToEgress(serviceImport *kubeslicev1beta1.Serv
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      virtualServiceFromAppPodName(serviceImport),
 			Namespace: serviceImport.Namespace,
+			Labels:    labelsForServiceEntry(serviceImport),
 		},
 		Spec: networkingv1beta1.VirtualService{
 			Hosts: []string{
@@ -175,6 +176,7 @@ func (r *Reconciler) virtualServiceFromEgress(serviceImport *kubeslicev1beta1.Se
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      virtualServiceFromEgressName(serviceImport),
 			Namespace: controllers.ControlPlaneNamespace,
+			Labels:    labelsForServiceEntry(serviceImport),
 		},
 		Spec: networkingv1beta1.VirtualService{
 			Hosts: []string{
@@ -103,6 +103,7 @@ func (r *Reconciler) virtualServiceNonEgress(serviceImport *kubeslicev1beta1.Ser
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      virtualServiceFromAppPodName(serviceImport),
 			Namespace: serviceImport.Namespace,
+			Labels:    labelsForServiceEntry(serviceImport),
 		},
 		Spec: networkingv1beta1.VirtualService{
 			Hosts: []string{
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
 	"github.com/prometheus/client_golang/prometheus"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -87,6 +88,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return result, err
 	}
 
+	// report the state of the serviceimport even if the reconciliation is requeued midway
+	defer func() {
+		if err := r.updateServiceImportConditions(ctx, serviceimport); err != nil {
+			log.Error(err, "Failed to update serviceimport conditions")
+		}
+	}()
+
 	if serviceimport.Status.ExposedPorts != portListToDisplayString(serviceimport.Spec.Ports) {
 		return r.updateServiceImportPorts(ctx, serviceimport)
 	}
@@ -139,7 +147,7 @@ func (r *Reconciler) Setup(mgr ctrl.Manager, mf metrics.MetricsFactory) error {
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kubeslicev1beta1.ServiceImport{}).
-		Complete(r)
+		Complete(tracing.NewReconciler("serviceimport", r))
 }
 func (r *Reconciler) handleServiceImportDeletion(ctx context.Context, serviceimport *kubeslicev1beta1.ServiceImport) (bool, ctrl.Result, error) {
 	log := logger.FromContext(ctx)
@@ -0,0 +1,234 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package slice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	controllerv1alpha1 "github.com/kubeslice/apis/pkg/controller/v1alpha1"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	ossEvents "github.com/kubeslice/worker-operator/events"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/utils"
+	nsmv1 "github.com/networkservicemesh/sdk-k8s/pkg/tools/k8s/apis/networkservicemesh.io/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// hubConnectedCondition returns the HubConnected condition for the current state of the hub connection
+func hubConnectedCondition(state hub.ConnectionState, lastErr error, generation int64) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               kubeslicev1beta1.SliceConditionHubConnected,
+		Reason:             string(state),
+		ObservedGeneration: generation,
+	}
+	switch state {
+	case hub.HubConnected:
+		condition.Status = metav1.ConditionTrue
+		condition.Message = "Hub cluster is reachable"
+	case hub.HubConnectionUnknown:
+		condition.Status = metav1.ConditionUnknown
+		condition.Message = "Hub cluster has not been probed yet"
+	default:
+		condition.Status = metav1.ConditionFalse
+		condition.Message = "Slice is running with its last known configuration"
+		if lastErr != nil {
+			condition.Message += ": " + lastErr.Error()
+		}
+	}
+	return condition
+}
+
+// updateHubConnectedCondition sets the HubConnected condition of the slice, the status is only
+// updated when the condition changes
+func (r *SliceReconciler) updateHubConnectedCondition(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
+	if r.HubConnection == nil {
+		return nil
+	}
+	state, _, lastErr := r.HubConnection.Status()
+	condition := hubConnectedCondition(state, lastErr, slice.Generation)
+	return controllers.SetConditions(ctx, r.Client, slice, &slice.Status.Conditions, nil, condition)
+}
+
+// maintenanceCondition returns the Maintenance condition for the maintenance flag of the slice
+func maintenanceCondition(slice *kubeslicev1beta1.Slice) metav1.Condition {
+	if controllers.SliceInMaintenance(slice) {
+		return controllers.Condition(kubeslicev1beta1.SliceConditionMaintenance, true, "MaintenanceEnabled",
+			"Restarts, rebalancing, recycling and QoS pushes are suspended on this cluster", slice.Generation)
+	}
+	return controllers.Condition(kubeslicev1beta1.SliceConditionMaintenance, false, "MaintenanceDisabled",
+		"Slice components are reconciled on this cluster", slice.Generation)
+}
+
+// updateMaintenanceCondition sets the Maintenance condition of the slice and records an event when the
+// maintenance starts or ends. The condition is only reported once the slice was put in maintenance.
+func (r *SliceReconciler) updateMaintenanceCondition(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
+	existing := meta.FindStatusCondition(slice.Status.Conditions, kubeslicev1beta1.SliceConditionMaintenance)
+	if existing == nil && !controllers.SliceInMaintenance(slice) {
+		return nil
+	}
+	wasInMaintenance := existing != nil && existing.Status == metav1.ConditionTrue
+	condition := maintenanceCondition(slice)
+	if err := controllers.SetConditions(ctx, r.Client, slice, &slice.Status.Conditions, nil, condition); err != nil {
+		return err
+	}
+	inMaintenance := condition.Status == metav1.ConditionTrue
+	switch {
+	case inMaintenance && !wasInMaintenance:
+		logger.FromContext(ctx).Info("Slice maintenance started")
+		utils.RecordEvent(ctx, r.EventRecorder, slice, nil, ossEvents.EventSliceMaintenanceStarted, controllerName)
+	case !inMaintenance && wasInMaintenance:
+		logger.FromContext(ctx).Info("Slice maintenance ended")
+		utils.RecordEvent(ctx, r.EventRecorder, slice, nil, ossEvents.EventSliceMaintenanceEnded, controllerName)
+	}
+	return nil
+}
+
+// sliceReadyConditions are the conditions the Ready condition of the slice is derived from. HubConnected
+// is not part of it since the slice keeps running with its last known configuration without the hub.
+var sliceReadyConditions = []string{
+	kubeslicev1beta1.ConditionDNSReady,
+	kubeslicev1beta1.ConditionRouterConnected,
+	kubeslicev1beta1.ConditionTunnelUp,
+	kubeslicev1beta1.ConditionPoliciesApplied,
+	kubeslicev1beta1.ConditionSubnetsValid,
+}
+
+// updateSliceConditions sets the conditions reporting the state of the slice components on this cluster
+func (r *SliceReconciler) updateSliceConditions(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
+	conditions, err := r.sliceConditions(ctx, slice)
+	if err != nil {
+		return err
+	}
+	return controllers.SetConditions(ctx, r.Client, slice, &slice.Status.Conditions, sliceReadyConditions, conditions...)
+}
+
+func (r *SliceReconciler) sliceConditions(ctx context.Context, slice *kubeslicev1beta1.Slice) ([]metav1.Condition, error) {
+	generation := slice.Generation
+	policies := controllers.Condition(kubeslicev1beta1.ConditionPoliciesApplied, true, "IsolationDisabled",
+		"Namespace isolation is not enabled for the slice", generation)
+	if slice.Status.SliceConfig.NamespaceIsolationProfile != nil && slice.Status.SliceConfig.NamespaceIsolationProfile.IsolationEnabled {
+		if slice.Status.NetworkPoliciesInstalled {
+			policies = controllers.Condition(kubeslicev1beta1.ConditionPoliciesApplied, true, "NetworkPoliciesInstalled",
+				"Network policies are installed in the application namespaces", generation)
+		} else {
+			policies = controllers.Condition(kubeslicev1beta1.ConditionPoliciesApplied, false, "NetworkPoliciesPending",
+				"Network policies are not installed in the application namespaces yet", generation)
+		}
+	}
+
+	if slice.Status.SliceConfig.SliceOverlayNetworkDeploymentMode == controllerv1alpha1.NONET {
+		const message = "Slice has no overlay network"
+		return []metav1.Condition{
+			controllers.Condition(kubeslicev1beta1.ConditionDNSReady, true, "NoNetwork", message, generation),
+			controllers.Condition(kubeslicev1beta1.ConditionRouterConnected, true, "NoNetwork", message, generation),
+			controllers.Condition(kubeslicev1beta1.ConditionTunnelUp, true, "NoNetwork", message, generation),
+			policies,
+			controllers.Condition(kubeslicev1beta1.ConditionSubnetsValid, true, "NoNetwork", message, generation),
+		}, nil
+	}
+
+	subnets := controllers.Condition(kubeslicev1beta1.ConditionSubnetsValid, true, "NoOverlap",
+		"Slice subnets do not overlap with the cluster CIDRs", generation)
+	if len(slice.Status.SubnetOverlaps) > 0 {
+		overlaps := []string{}
+		for _, o := range slice.Status.SubnetOverlaps {
+			overlaps = append(overlaps, fmt.Sprintf("%s %s overlaps with %s", o.Name, o.Subnet, o.ClusterCIDR))
+		}
+		subnets = controllers.Condition(kubeslicev1beta1.ConditionSubnetsValid, false, "SubnetOverlap",
+			"Slice router and gateways are not deployed: "+strings.Join(overlaps, ", "), generation)
+	}
+
+	dns := controllers.Condition(kubeslicev1beta1.ConditionDNSReady, false, "DNSServicePending",
+		"Slice DNS service is not created yet", generation)
+	if slice.Status.DNSIP != "" {
+		dns = controllers.Condition(kubeslicev1beta1.ConditionDNSReady, true, "DNSServiceReady",
+			"Slice DNS is served at "+slice.Status.DNSIP, generation)
+	}
+
+	_, routerIP, err := controllers.GetSliceRouterPodNameAndIP(ctx, r.Client, slice.Name)
+	if err != nil {
+		return nil, err
+	}
+	router := controllers.Condition(kubeslicev1beta1.ConditionRouterConnected, false, "RouterPodNotRunning",
+		"Slice router pod is not running", generation)
+	if routerIP != "" {
+		// the pods only reach the router once it registered its endpoint of the slice network service in NSM
+		registered, err := r.sliceRouterRegistered(ctx, slice.Name)
+		if err != nil {
+			return nil, err
+		}
+		if registered {
+			router = controllers.Condition(kubeslicev1beta1.ConditionRouterConnected, true, "RouterRegistered",
+				"Slice router at "+routerIP+" is registered in NSM", generation)
+		} else {
+			router = controllers.Condition(kubeslicev1beta1.ConditionRouterConnected, false, "RouterNotRegistered",
+				"Slice router pod is running at "+routerIP+" but its endpoint is not registered in NSM", generation)
+		}
+	}
+
+	sliceGwList, err := controllers.GetSliceGatewayList(ctx, r.Client, slice.Name)
+	if err != nil {
+		return nil, err
+	}
+	tunnel := controllers.Condition(kubeslicev1beta1.ConditionTunnelUp, true, "NoRemoteClusters",
+		"Slice has no gateways to remote clusters", generation)
+	if len(sliceGwList.Items) > 0 {
+		down := []string{}
+		for _, sliceGw := range sliceGwList.Items {
+			if !meta.IsStatusConditionTrue(sliceGw.Status.Conditions, kubeslicev1beta1.ConditionTunnelUp) {
+				down = append(down, sliceGw.Name)
+			}
+		}
+		sort.Strings(down)
+		if len(down) == 0 {
+			tunnel = controllers.Condition(kubeslicev1beta1.ConditionTunnelUp, true, "TunnelsUp",
+				fmt.Sprintf("Tunnels of all %d gateways are up", len(sliceGwList.Items)), generation)
+		} else {
+			tunnel = controllers.Condition(kubeslicev1beta1.ConditionTunnelUp, false, "TunnelsDown",
+				"Tunnels are down for gateways: "+strings.Join(down, ", "), generation)
+		}
+	}
+
+	return []metav1.Condition{dns, router, tunnel, policies, subnets}, nil
+}
+
+// sliceRouterRegistered returns true if the NSM endpoint of the slice router is registered and not expired
+func (r *SliceReconciler) sliceRouterRegistered(ctx context.Context, sliceName string) (bool, error) {
+	nse := &nsmv1.NetworkServiceEndpoint{}
+	err := r.Get(ctx, types.NamespacedName{Name: "vl3-nse-" + sliceName, Namespace: controllers.ControlPlaneNamespace}, nse)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if nse.Spec.ExpirationTime != nil && nse.Spec.ExpirationTime.AsTime().Before(time.Now()) {
+		return false, nil
+	}
+	return true, nil
+}
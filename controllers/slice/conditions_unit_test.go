@@ -0,0 +1,277 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package slice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
+	"github.com/kubeslice/worker-operator/pkg/netop"
+	nsmv1 "github.com/networkservicemesh/sdk-k8s/pkg/tools/k8s/apis/networkservicemesh.io/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type fakeHubConnection struct {
+	state hub.ConnectionState
+	err   error
+}
+
+func (f *fakeHubConnection) Status() (hub.ConnectionState, time.Time, error) {
+	return f.state, time.Now(), f.err
+}
+
+func TestUpdateHubConnectedCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    hub.ConnectionState
+		err      error
+		expected metav1.ConditionStatus
+	}{
+		{"connected", hub.HubConnected, nil, metav1.ConditionTrue},
+		{"disconnected", hub.HubDisconnected, errors.New("connection refused"), metav1.ConditionFalse},
+		{"unauthorized", hub.HubUnauthorized, errors.New("Unauthorized"), metav1.ConditionFalse},
+		{"unknown", hub.HubConnectionUnknown, nil, metav1.ConditionUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slice := &kubeslicev1beta1.Slice{ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: ControlPlaneNamespace}}
+			r := newOffboardingTestReconciler(slice)
+			r.HubConnection = &fakeHubConnection{state: tt.state, err: tt.err}
+			if err := r.updateHubConnectedCondition(context.Background(), slice); err != nil {
+				t.Fatal("unexpected error: ", err)
+			}
+			got := &kubeslicev1beta1.Slice{}
+			if err := r.Get(context.Background(), types.NamespacedName{Name: "green", Namespace: ControlPlaneNamespace}, got); err != nil {
+				t.Fatal("unexpected error: ", err)
+			}
+			condition := meta.FindStatusCondition(got.Status.Conditions, kubeslicev1beta1.SliceConditionHubConnected)
+			if condition == nil || condition.Status != tt.expected || condition.Reason != string(tt.state) {
+				t.Errorf("unexpected condition %+v", condition)
+			}
+		})
+	}
+}
+
+func TestUpdateMaintenanceCondition(t *testing.T) {
+	ctx := context.Background()
+	slice := &kubeslicev1beta1.Slice{
+		ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: ControlPlaneNamespace},
+	}
+	r := newOffboardingTestReconciler(slice)
+	reasons := func() []string {
+		events := &corev1.EventList{}
+		if err := r.List(ctx, events); err != nil {
+			t.Fatal("unexpected error: ", err)
+		}
+		reasons := []string{}
+		for _, e := range events.Items {
+			reasons = append(reasons, e.Reason)
+		}
+		return reasons
+	}
+	setMaintenance := func(maintenance bool) {
+		slice.Spec.Maintenance = maintenance
+		if err := r.Update(ctx, slice); err != nil {
+			t.Fatal("unexpected error: ", err)
+		}
+	}
+
+	// the condition is not reported for slices that were never in maintenance
+	if err := r.updateMaintenanceCondition(ctx, slice); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(slice.Status.Conditions) != 0 {
+		t.Errorf("expected no condition, got %+v", slice.Status.Conditions)
+	}
+
+	setMaintenance(true)
+	if err := r.updateMaintenanceCondition(ctx, slice); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if !meta.IsStatusConditionTrue(slice.Status.Conditions, kubeslicev1beta1.SliceConditionMaintenance) {
+		t.Errorf("expected the maintenance condition to be true, got %+v", slice.Status.Conditions)
+	}
+	// the event is only recorded when the maintenance starts
+	if err := r.updateMaintenanceCondition(ctx, slice); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if got := reasons(); len(got) != 1 || got[0] != "SliceMaintenanceStarted" {
+		t.Errorf("expected a maintenance started event, got %v", got)
+	}
+
+	setMaintenance(false)
+	if err := r.updateMaintenanceCondition(ctx, slice); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	condition := meta.FindStatusCondition(slice.Status.Conditions, kubeslicev1beta1.SliceConditionMaintenance)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "MaintenanceDisabled" {
+		t.Errorf("unexpected condition %+v", condition)
+	}
+	if got := reasons(); len(got) != 2 {
+		t.Errorf("expected a maintenance ended event, got %v", got)
+	}
+}
+
+func TestUpdateSliceConditions(t *testing.T) {
+	newObjects := func(tunnelStatus metav1.ConditionStatus, overlaps []kubeslicev1beta1.SubnetOverlap) []client.Object {
+		return []client.Object{
+			&kubeslicev1beta1.Slice{
+				ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: ControlPlaneNamespace, Generation: 2},
+				Status: kubeslicev1beta1.SliceStatus{
+					SliceConfig:    &kubeslicev1beta1.SliceConfig{},
+					DNSIP:          "10.96.0.53",
+					SubnetOverlaps: overlaps,
+				},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "vl3-slice-router-green", Namespace: ControlPlaneNamespace,
+					Labels: map[string]string{"networkservicemesh.io/impl": "vl3-service-green"}},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.1.1.1"},
+			},
+			&kubeslicev1beta1.SliceGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "green-worker-1-worker-2", Namespace: ControlPlaneNamespace,
+					Labels: map[string]string{ApplicationNamespaceSelectorLabelKey: "green"}},
+				Status: kubeslicev1beta1.SliceGatewayStatus{Conditions: []metav1.Condition{{
+					Type: kubeslicev1beta1.ConditionTunnelUp, Status: tunnelStatus, Reason: "Test",
+				}}},
+			},
+		}
+	}
+	tests := []struct {
+		name         string
+		tunnel       metav1.ConditionStatus
+		overlaps     []kubeslicev1beta1.SubnetOverlap
+		unregistered bool
+		ready        metav1.ConditionStatus
+		readyReason  string
+	}{
+		{"all components ready", metav1.ConditionTrue, nil, false, metav1.ConditionTrue, "Reconciled"},
+		{"tunnel down", metav1.ConditionFalse, nil, false, metav1.ConditionFalse, "TunnelsDown"},
+		{"subnet overlap", metav1.ConditionTrue, []kubeslicev1beta1.SubnetOverlap{
+			{Name: "sliceSubnet", Subnet: "10.96.0.0/16", ClusterCIDR: "10.96.0.0/12"},
+		}, false, metav1.ConditionFalse, "SubnetOverlap"},
+		{"router not registered in nsm", metav1.ConditionTrue, nil, true, metav1.ConditionFalse, "RouterNotRegistered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := newObjects(tt.tunnel, tt.overlaps)
+			if !tt.unregistered {
+				objs = append(objs, &nsmv1.NetworkServiceEndpoint{
+					ObjectMeta: metav1.ObjectMeta{Name: "vl3-nse-green", Namespace: ControlPlaneNamespace},
+				})
+			}
+			slice := objs[0].(*kubeslicev1beta1.Slice)
+			r := newOffboardingTestReconciler(objs...)
+			if err := r.updateSliceConditions(context.Background(), slice); err != nil {
+				t.Fatal("unexpected error: ", err)
+			}
+			got := &kubeslicev1beta1.Slice{}
+			if err := r.Get(context.Background(), types.NamespacedName{Name: "green", Namespace: ControlPlaneNamespace}, got); err != nil {
+				t.Fatal("unexpected error: ", err)
+			}
+			for _, conditionType := range sliceReadyConditions {
+				if meta.FindStatusCondition(got.Status.Conditions, conditionType) == nil {
+					t.Errorf("condition %s not set", conditionType)
+				}
+			}
+			ready := meta.FindStatusCondition(got.Status.Conditions, kubeslicev1beta1.ConditionReady)
+			if ready == nil || ready.Status != tt.ready || ready.Reason != tt.readyReason || ready.ObservedGeneration != 2 {
+				t.Errorf("unexpected ready condition %+v", ready)
+			}
+		})
+	}
+}
+
+type fakeNetOpClient struct {
+	errs map[string]error
+}
+
+func (f *fakeNetOpClient) UpdateSliceQosProfile(ctx context.Context, addr string, slice *kubeslicev1beta1.Slice) error {
+	return f.errs[addr]
+}
+
+func (f *fakeNetOpClient) SendSliceLifeCycleEventToNetOp(ctx context.Context, addr string, sliceName string, eventType netop.EventType) error {
+	return nil
+}
+
+func (f *fakeNetOpClient) SendConnectionContext(ctx context.Context, serverAddr string, gw *kubeslicev1beta1.SliceGateway, sliceGwNodePorts []int) error {
+	return nil
+}
+
+func TestSyncSliceQosProfileWithNetOp(t *testing.T) {
+	netOpPod := func(name, node, ip string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ControlPlaneNamespace, Labels: map[string]string{"app": "app_net_op"}},
+			Spec:       corev1.PodSpec{NodeName: node},
+			Status:     corev1.PodStatus{PodIP: ip},
+		}
+	}
+	tests := []struct {
+		name     string
+		profile  kubeslicev1beta1.QosProfileDetails
+		errs     map[string]error
+		expected metav1.ConditionStatus
+		reason   string
+	}{
+		{"applied", kubeslicev1beta1.QosProfileDetails{TcType: "BANDWIDTH_CONTROL", QueueType: "TBF", BandwidthCeilingKbps: 5000}, nil,
+			metav1.ConditionTrue, "QosProfileApplied"},
+		{"invalid profile", kubeslicev1beta1.QosProfileDetails{TcType: "DSCP_MARKING"}, nil,
+			metav1.ConditionFalse, "InvalidQosProfile"},
+		{"unsupported on a node", kubeslicev1beta1.QosProfileDetails{QueueType: "TBF", BandwidthCeilingKbps: 5000},
+			map[string]error{"10.1.0.2:5000": status.Error(codes.InvalidArgument, "unknown tc type")},
+			metav1.ConditionFalse, "QosProfileRejected"},
+		{"netop unreachable", kubeslicev1beta1.QosProfileDetails{BandwidthCeilingKbps: 5000},
+			map[string]error{"10.1.0.1:5000": errors.New("connection refused")},
+			metav1.ConditionFalse, "QosProfileNotApplied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slice := &kubeslicev1beta1.Slice{
+				ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: ControlPlaneNamespace},
+				Status: kubeslicev1beta1.SliceStatus{
+					SliceConfig: &kubeslicev1beta1.SliceConfig{QosProfileDetails: tt.profile},
+				},
+			}
+			r := newOffboardingTestReconciler(slice, netOpPod("netop-a", "node-1", "10.1.0.1"), netOpPod("netop-b", "node-2", "10.1.0.2"))
+			r.WorkerNetOpClient = &fakeNetOpClient{errs: tt.errs}
+			err := r.SyncSliceQosProfileWithNetOp(context.Background(), slice)
+			if (err != nil) != (tt.expected != metav1.ConditionTrue) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			got := &kubeslicev1beta1.Slice{}
+			if err := r.Get(context.Background(), types.NamespacedName{Name: "green", Namespace: ControlPlaneNamespace}, got); err != nil {
+				t.Fatal("unexpected error: ", err)
+			}
+			condition := meta.FindStatusCondition(got.Status.Conditions, kubeslicev1beta1.ConditionQosApplied)
+			if condition == nil || condition.Status != tt.expected || condition.Reason != tt.reason {
+				t.Errorf("unexpected condition %+v", condition)
+			}
+		})
+	}
+}
@@ -26,17 +26,21 @@ import (
 	"github.com/kubeslice/worker-operator/controllers"
 	ossEvents "github.com/kubeslice/worker-operator/events"
 	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/networkpolicy/backend"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 	webhook "github.com/kubeslice/worker-operator/pkg/webhook/pod"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 type nsMarker struct {
@@ -53,6 +57,10 @@ func (r *SliceReconciler) ReconcileSliceNamespaces(ctx context.Context, slice *k
 	if reconcile {
 		return res, err, true
 	}
+	err = r.reconcileOffboardingNamespaces(ctx, slice)
+	if err != nil {
+		return ctrl.Result{}, err, true
+	}
 	err = r.reconcileAllowedNamespaces(ctx, slice)
 	if err != nil {
 		return ctrl.Result{}, err, true
@@ -67,13 +75,24 @@ func (r *SliceReconciler) ReconcileSliceNamespaces(ctx context.Context, slice *k
 func (r *SliceReconciler) reconcileAppNamespaces(ctx context.Context, slice *kubeslicev1beta1.Slice) (ctrl.Result, error, bool) {
 	log := logger.FromContext(ctx).WithValues("type", "appNamespaces")
 	debugLog := log.V(1)
-	//early exit if NamespaceIsolationProfile is not defined
-	if slice.Status.SliceConfig.NamespaceIsolationProfile == nil {
+	//early exit if neither NamespaceIsolationProfile nor the namespace selector is defined
+	if slice.Status.SliceConfig.NamespaceIsolationProfile == nil && slice.Spec.ApplicationNamespaceSelector == nil {
 		return ctrl.Result{}, nil, false
 	}
 	//cfgAppNsList = list of all app namespaces in slice CR
 	//var cfgAppNsList []string
 	cfgAppNsList := buildAppNamespacesList(slice)
+	// add the namespaces matching the namespace selector of the slice
+	selectedAppNsList, err := r.getSelectedAppNamespaces(ctx, slice)
+	if err != nil {
+		log.Error(err, "Failed to list namespaces matching the slice namespace selector")
+		return ctrl.Result{}, err, true
+	}
+	for _, selectedAppNs := range selectedAppNsList {
+		if !exists(cfgAppNsList, selectedAppNs) {
+			cfgAppNsList = append(cfgAppNsList, selectedAppNs)
+		}
+	}
 	debugLog.Info("reconciling", "applicationNamespaces", cfgAppNsList)
 
 	// Get the list of existing namespaces that are tagged with the kubeslice label
@@ -84,7 +103,7 @@ func (r *SliceReconciler) reconcileAppNamespaces(ctx context.Context, slice *kub
 			controllers.ApplicationNamespaceSelectorLabelKey: slice.Name,
 		}),
 	}
-	err := r.List(ctx, existingAppNsList, listOpts...)
+	err = r.List(ctx, existingAppNsList, listOpts...)
 	if err != nil {
 		log.Error(err, "Failed to list namespaces")
 		return ctrl.Result{}, err, true
@@ -116,14 +135,22 @@ func (r *SliceReconciler) reconcileAppNamespaces(ctx context.Context, slice *kub
 	if err != nil {
 		return ctrl.Result{}, err, true
 	}
-	// Sweep the existing namespaces again to unbind any namespace that was not found in the configured list
+	// Sweep the existing namespaces again to offboard any namespace that was not found in the configured list
+	offboardingNsList := []string{}
 	for existingAppNs := range existingAppNsMap {
+		// namespaces keep the slice label while they drain
+		if getOffboardingNamespace(slice.Status.OffboardingNamespaces, existingAppNs) != nil {
+			continue
+		}
 		if !existingAppNsMap[existingAppNs].marked {
-			err := r.unbindAppNamespace(ctx, slice, existingAppNs, configLabels, configAnnotations)
+			started, err := r.startAppNamespaceOffboarding(ctx, slice, existingAppNs)
 			if err != nil {
 				log.Error(err, "Failed to unbind namespace from slice", "namespace", existingAppNs)
 				return ctrl.Result{}, err, true
 			}
+			if started {
+				offboardingNsList = append(offboardingNsList, existingAppNs)
+			}
 			statusChanged = true
 		}
 	}
@@ -134,6 +161,7 @@ func (r *SliceReconciler) reconcileAppNamespaces(ctx context.Context, slice *kub
 				return getErr
 			}
 			slice.Status.ApplicationNamespaces = labeledAppNsList
+			slice.Status.OffboardingNamespaces = addOffboardingNamespaces(slice.Status.OffboardingNamespaces, offboardingNsList)
 			err := r.Status().Update(ctx, slice)
 			if err != nil {
 				log.Error(err, "Failed to update Application Namespaces in slice status,retrying")
@@ -364,57 +392,65 @@ func indexOf(a []string, b string) int {
 }
 
 func (r *SliceReconciler) unbindAppNamespace(ctx context.Context, slice *kubeslicev1beta1.Slice, appNs string, configLabels, configAnnotations map[string]string) error {
+	log := logger.FromContext(ctx).WithValues("type", "appNamespaces")
+	found, err := r.unlabelAppNamespace(ctx, appNs, configLabels, configAnnotations, "")
+	//namespace might be deleted by user/admin
+	if err != nil || !found {
+		return err
+	}
+	// Delete network policy if present
+	err = r.netpolBackend().UnbindNamespace(ctx, slice, appNs)
+	if err != nil {
+		log.Error(err, "NS unbind: Failed to remove slice netpol", "namespace", appNs)
+	}
+	//remove the resource annotations and labels from this namespace
+	return r.deleteAnnotationsAndLabels(ctx, slice, appNs)
+}
+
+// unlabelAppNamespace removes the slice labels from the namespace. If offboardingSlice is set, the namespace is
+// marked as offboarding from that slice. Returns false if the namespace does not exist.
+func (r *SliceReconciler) unlabelAppNamespace(ctx context.Context, appNs string, configLabels, configAnnotations map[string]string, offboardingSlice string) (bool, error) {
 	log := logger.FromContext(ctx).WithValues("type", "appNamespaces")
 	debuglog := log.V(1)
 	namespace := &corev1.Namespace{}
 	err := r.Get(ctx, types.NamespacedName{Name: appNs}, namespace)
-	//namespace might be deleted by user/admin
 	if errors.IsNotFound(err) {
-		return nil
+		return false, nil
 	}
 	if err != nil {
 		log.Error(err, "NS unbind: Failed to find namespace", "namespace", appNs)
-		return err
+		return false, err
 	}
 
 	nsLabels := namespace.ObjectMeta.GetLabels()
 	_, ok := nsLabels[controllers.ApplicationNamespaceSelectorLabelKey]
 	if !ok {
 		debuglog.Info("NS unbind: slice label not found", "namespace", appNs)
-	} else {
-		delete(nsLabels, controllers.ApplicationNamespaceSelectorLabelKey)
-		// remove injection key
-		_, ok := nsLabels[InjectSidecarKey]
-		if ok {
-			delete(nsLabels, InjectSidecarKey)
-		}
-		namespace.ObjectMeta.SetLabels(nsLabels)
-		// remove user defined labels
-		removeEntries(nsLabels, configLabels)
-		// fetch annotations and remove user defined annotations from the namespace
-		nsAnnotations := namespace.ObjectMeta.GetAnnotations()
-		removeEntries(nsAnnotations, configAnnotations)
-		namespace.ObjectMeta.SetAnnotations(nsAnnotations)
-
-		err = r.Update(ctx, namespace)
-		if err != nil {
-			log.Error(err, "NS unbind: Failed to remove slice label", "namespace", appNs)
-			return err
-		}
+		return true, nil
 	}
-	// Delete network policy if present
-	netPolicy := &networkingv1.NetworkPolicy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      slice.Name + "-" + appNs,
-			Namespace: appNs,
-		},
-	}
-	err = r.Delete(ctx, netPolicy)
-	if err != nil && !errors.IsNotFound(err) {
-		log.Error(err, "NS unbind: Failed to remove slice netpol", "namespace", appNs)
+	delete(nsLabels, controllers.ApplicationNamespaceSelectorLabelKey)
+	// remove injection key
+	_, ok = nsLabels[InjectSidecarKey]
+	if ok {
+		delete(nsLabels, InjectSidecarKey)
 	}
-	//remove the resource annotations and labels from this namespace
-	return r.deleteAnnotationsAndLabels(ctx, slice, appNs)
+	// remove user defined labels
+	removeEntries(nsLabels, configLabels)
+	if offboardingSlice != "" {
+		nsLabels[controllers.ApplicationNamespaceOffboardingLabelKey] = offboardingSlice
+	}
+	namespace.ObjectMeta.SetLabels(nsLabels)
+	// fetch annotations and remove user defined annotations from the namespace
+	nsAnnotations := namespace.ObjectMeta.GetAnnotations()
+	removeEntries(nsAnnotations, configAnnotations)
+	namespace.ObjectMeta.SetAnnotations(nsAnnotations)
+
+	err = r.Update(ctx, namespace)
+	if err != nil {
+		log.Error(err, "NS unbind: Failed to remove slice label", "namespace", appNs)
+		return true, err
+	}
+	return true, nil
 }
 
 func (r *SliceReconciler) deleteAnnotationsAndLabels(ctx context.Context, slice *kubeslicev1beta1.Slice, appNs string) error {
@@ -615,31 +651,123 @@ func (r *SliceReconciler) deleteAnnotationsAndLabels(ctx context.Context, slice
 			log.Info("Removed slice labels and annotations", "daemonset", daemonset.Name)
 		}
 	}
+
+	// replicasets of deployments are updated by their deployment
+	replicaSetList := appsv1.ReplicaSetList{}
+	if err := r.List(ctx, &replicaSetList, client.InNamespace(appNs)); err != nil {
+		log.Error(err, "Namespace offboarding:cannot list replicasets under ns ", appNs)
+	}
+	for i := range replicaSetList.Items {
+		replicaset := &replicaSetList.Items[i]
+		if metav1.GetControllerOf(replicaset) != nil || !removeSliceFromPodTemplate(&replicaset.Spec.Template, slice.Name) {
+			continue
+		}
+		if err := r.Update(ctx, replicaset); err != nil {
+			log.Error(err, "Error deleting labels and annotations from replicaset while namespace unbinding from slice", replicaset.ObjectMeta.Name)
+			return err
+		}
+		log.Info("Removed slice labels and annotations", "replicaset", replicaset.Name)
+	}
+
+	// the pod template of jobs is immutable, the jobs created by cronjobs from now on are not attached to the slice
+	cronJobList := batchv1.CronJobList{}
+	if err := r.List(ctx, &cronJobList, client.InNamespace(appNs)); err != nil {
+		log.Error(err, "Namespace offboarding:cannot list cronjobs under ns ", appNs)
+	}
+	for i := range cronJobList.Items {
+		cronjob := &cronJobList.Items[i]
+		if !removeSliceFromPodTemplate(&cronjob.Spec.JobTemplate.Spec.Template, slice.Name) {
+			continue
+		}
+		if err := r.Update(ctx, cronjob); err != nil {
+			log.Error(err, "Error deleting labels and annotations from cronjob while namespace unbinding from slice", cronjob.ObjectMeta.Name)
+			return err
+		}
+		log.Info("Removed slice labels and annotations", "cronjob", cronjob.Name)
+	}
 	return nil
 }
 
+// removeSliceFromPodTemplate removes the slice labels and NSM annotations from a pod template. Returns true if the
+// template changed.
+func removeSliceFromPodTemplate(template *corev1.PodTemplateSpec, sliceName string) bool {
+	changed := false
+	if _, ok := template.Labels[webhook.PodInjectLabelKey]; ok {
+		delete(template.Labels, webhook.PodInjectLabelKey)
+		changed = true
+	}
+	if template.Labels[controllers.ApplicationNamespaceSelectorLabelKey] == sliceName {
+		delete(template.Labels, controllers.ApplicationNamespaceSelectorLabelKey)
+		changed = true
+	}
+	if template.Annotations["ns.networkservicemesh.io"] == "vl3-service-"+sliceName {
+		delete(template.Annotations, "ns.networkservicemesh.io")
+		changed = true
+	}
+	if template.Annotations["networkservicemesh.io"] == fmt.Sprintf("kernel://vl3-service-%s/nsm0", sliceName) {
+		delete(template.Annotations, "networkservicemesh.io")
+		changed = true
+	}
+	if _, ok := template.Annotations["kubeslice.io/status"]; ok {
+		delete(template.Annotations, "kubeslice.io/status")
+		changed = true
+	}
+	return changed
+}
+
 func (r *SliceReconciler) uninstallNetworkPolicies(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
 	log := r.Log.WithValues("type", "networkPolicy")
-	for _, ns := range slice.Status.ApplicationNamespaces {
-		// Delete network policy if present
-		netPolicy := &networkingv1.NetworkPolicy{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      slice.Name + "-" + ns,
-				Namespace: ns,
-			},
-		}
-		err := r.Delete(ctx, netPolicy)
-		if err != nil {
-			if errors.IsNotFound(err) {
-				continue
-			}
-			log.Error(err, "NS unbind: Failed to remove slice netpol", "namespace", ns)
+	// namespaces that were still draining are detached right away
+	for _, offboardingNs := range slice.Status.OffboardingNamespaces {
+		if err := r.removeOffboardingLabel(ctx, offboardingNs.Namespace); err != nil {
+			log.Error(err, "Failed to remove offboarding label from namespace", "namespace", offboardingNs.Namespace)
+			return err
+		}
+		if err := r.deleteAnnotationsAndLabels(ctx, slice, offboardingNs.Namespace); err != nil {
+			log.Error(err, "Failed to unbind namespace from slice", "namespace", offboardingNs.Namespace)
+			return err
 		}
 	}
+	if err := r.uninstallPreviousNetpolBackend(ctx, slice); err != nil {
+		return err
+	}
+	if err := r.netpolBackend().Uninstall(ctx, slice); err != nil {
+		log.Error(err, "Failed to uninstall slice network policies", "backend", r.netpolBackend().Type())
+		return err
+	}
 	slice.Status.NetworkPoliciesInstalled = false
+	slice.Status.NetworkPolicyBackend = ""
 	return r.Status().Update(ctx, slice)
 }
 
+// uninstallPreviousNetpolBackend removes the policies installed by the backend recorded in the slice
+// status when the operator was switched to another backend. Slices installed before the backend was
+// recorded used namespaced NetworkPolicies.
+func (r *SliceReconciler) uninstallPreviousNetpolBackend(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
+	log := r.Log.WithValues("type", "networkPolicy")
+	if !slice.Status.NetworkPoliciesInstalled {
+		return nil
+	}
+	previousType := slice.Status.NetworkPolicyBackend
+	if previousType == "" {
+		previousType = backend.TypeNetworkPolicy
+	}
+	if previousType == r.netpolBackend().Type() {
+		return nil
+	}
+	previous, err := backend.New(previousType, r.Client)
+	if err != nil {
+		log.Error(err, "Failed to create previous network policy backend", "backend", previousType)
+		return err
+	}
+	if err := previous.Uninstall(ctx, slice); err != nil {
+		log.Error(err, "Failed to uninstall network policies of previous backend", "backend", previousType)
+		return err
+	}
+	log.Info("Uninstalled network policies of previous backend", "previous", previousType, "backend", r.netpolBackend().Type())
+	return nil
+}
+
 func (r *SliceReconciler) reconcileSliceNetworkPolicy(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
 	log := r.Log.WithValues("type", "networkPolicy")
 	//early exit if namespaceIsolation is empty
@@ -668,33 +796,34 @@ func (r *SliceReconciler) reconcileSliceNetworkPolicy(ctx context.Context, slice
 			return err
 		}
 	}
+	appNamespaces := []string{}
 	for _, appNsObj := range appNsList.Items {
-		err = r.installSliceNetworkPolicyInAppNs(ctx, slice, appNsObj.ObjectMeta.Name)
-		if err != nil {
-			log.Error(err, "Failed to install network policy", "namespace", appNsObj.ObjectMeta.Name)
-			return err
-		}
+		appNamespaces = append(appNamespaces, appNsObj.ObjectMeta.Name)
+	}
+	if err := r.uninstallPreviousNetpolBackend(ctx, slice); err != nil {
+		return err
+	}
+	err = r.netpolBackend().Install(ctx, slice, appNamespaces)
+	if err != nil {
+		log.Error(err, "Failed to install network policy", "backend", r.netpolBackend().Type(), "namespaces", appNamespaces)
+		return err
+	}
+	for _, appNs := range appNamespaces {
 		utils.RecordEvent(ctx, r.EventRecorder, slice, nil, ossEvents.EventNetPolAdded, "slice_reconciler")
-		log.Info("Installed netpol for namespace successfully", "namespace", appNsObj.ObjectMeta.Name)
+		log.Info("Installed netpol for namespace successfully", "namespace", appNs)
 	}
 	slice.Status.NetworkPoliciesInstalled = true
+	slice.Status.NetworkPolicyBackend = r.netpolBackend().Type()
 	return r.Status().Update(ctx, slice)
 }
 
-func (r *SliceReconciler) installSliceNetworkPolicyInAppNs(ctx context.Context, slice *kubeslicev1beta1.Slice, appNs string) error {
-	log := r.Log.WithValues("type", "networkPolicy")
-
-	netPolicy := controllers.ContructNetworkPolicyObject(ctx, slice, appNs)
-	err := r.Update(ctx, netPolicy)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return r.Create(ctx, netPolicy)
-		} else {
-			return err
-		}
+// netpolBackend returns the backend used to enforce namespace isolation, falling back to namespaced
+// NetworkPolicies if none is configured
+func (r *SliceReconciler) netpolBackend() backend.Backend {
+	if r.NetworkPolicyBackend == nil {
+		return backend.NewNetworkPolicyBackend(r.Client)
 	}
-	log.Info("Updated network policy", "namespace", appNs)
-	return nil
+	return r.NetworkPolicyBackend
 }
 
 func (r *SliceReconciler) cleanupSliceNamespaces(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
@@ -724,6 +853,10 @@ func (r *SliceReconciler) cleanupSliceNamespaces(ctx context.Context, slice *kub
 			return err
 		}
 	}
+	if err := r.netpolBackend().Uninstall(ctx, slice); err != nil {
+		log.Error(err, "Failed to uninstall slice network policies", "backend", r.netpolBackend().Type())
+		return err
+	}
 	// unbind allowed Namespaces
 	for _, namespace := range slice.Status.AllowedNamespaces {
 		if err := r.unbindAllowedNamespace(ctx, namespace, slice.Name); err != nil {
@@ -745,6 +878,9 @@ func exists(i []string, o string) bool {
 
 func buildAppNamespacesList(slice *kubeslicev1beta1.Slice) []string {
 	var cfgAppNsList []string
+	if slice.Status.SliceConfig.NamespaceIsolationProfile == nil {
+		return cfgAppNsList
+	}
 	for _, qualifiedAppNs := range slice.Status.SliceConfig.NamespaceIsolationProfile.ApplicationNamespaces {
 		// Ignore control plane namespace if it appears in the app namespace list
 		if qualifiedAppNs == ControlPlaneNamespace {
@@ -754,6 +890,70 @@ func buildAppNamespacesList(slice *kubeslicev1beta1.Slice) []string {
 	}
 	return cfgAppNsList
 }
+
+// getSelectedAppNamespaces returns the namespaces matching the namespace selector of the slice.
+// Namespaces already onboarded to another slice and the system and excluded namespaces are skipped.
+func (r *SliceReconciler) getSelectedAppNamespaces(ctx context.Context, slice *kubeslicev1beta1.Slice) ([]string, error) {
+	if slice.Spec.ApplicationNamespaceSelector == nil {
+		return nil, nil
+	}
+	log := logger.FromContext(ctx).WithValues("type", "appNamespaces")
+	selector, err := metav1.LabelSelectorAsSelector(slice.Spec.ApplicationNamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+	// an empty selector would onboard every namespace of the cluster
+	if selector.Empty() {
+		log.Info("Ignoring empty application namespace selector")
+		return nil, nil
+	}
+	nsList := &corev1.NamespaceList{}
+	if err := r.List(ctx, nsList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	excludedNs := append(operatorconfig.ExcludedNamespaces(), allowedNamespacesByDefault...)
+	var selectedAppNsList []string
+	for _, ns := range nsList.Items {
+		if ns.Name == ControlPlaneNamespace || exists(excludedNs, ns.Name) {
+			continue
+		}
+		if sliceName, ok := ns.Labels[controllers.ApplicationNamespaceSelectorLabelKey]; ok && sliceName != slice.Name {
+			log.Info("Namespace matching the selector is part of another slice", "namespace", ns.Name, "slice", sliceName)
+			continue
+		}
+		selectedAppNsList = append(selectedAppNsList, ns.Name)
+	}
+	return selectedAppNsList, nil
+}
+
+// findSlicesSelectingNamespace returns the slices whose namespace selector matches the namespace,
+// or that have onboarded the namespace, so that namespaces are onboarded and offboarded as their labels change
+func (r *SliceReconciler) findSlicesSelectingNamespace(ctx context.Context, o client.Object) []reconcile.Request {
+	log := logger.FromContext(ctx).WithValues("type", "appNamespaces")
+	sliceList := &kubeslicev1beta1.SliceList{}
+	if err := r.List(ctx, sliceList, client.InNamespace(ControlPlaneNamespace)); err != nil {
+		log.Error(err, "Failed to list slices")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, slice := range sliceList.Items {
+		if slice.Spec.ApplicationNamespaceSelector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(slice.Spec.ApplicationNamespaceSelector)
+		if err != nil {
+			continue
+		}
+		nsLabels := labels.Set(o.GetLabels())
+		if selector.Matches(nsLabels) || nsLabels[controllers.ApplicationNamespaceSelectorLabelKey] == slice.Name {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: slice.Name, Namespace: slice.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
 func (r *SliceReconciler) createAndLabelAppNamespaces(ctx context.Context, cfgAppNsList []string, existingAppNsMap map[string]*nsMarker, slice *kubeslicev1beta1.Slice, configLabels, configAnnotations map[string]string) ([]string, bool, error) {
 	labeledAppNsList := []string{}
 	statusChanged := false
@@ -0,0 +1,172 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package slice
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeNetpolBackend struct {
+	backendType string
+	installed   []string
+}
+
+func (b *fakeNetpolBackend) Type() string {
+	return b.backendType
+}
+
+func (b *fakeNetpolBackend) Install(ctx context.Context, slice *kubeslicev1beta1.Slice, appNamespaces []string) error {
+	b.installed = appNamespaces
+	return nil
+}
+
+func (b *fakeNetpolBackend) UnbindNamespace(ctx context.Context, slice *kubeslicev1beta1.Slice, appNs string) error {
+	return nil
+}
+
+func (b *fakeNetpolBackend) Uninstall(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
+	b.installed = nil
+	return nil
+}
+
+func newNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func newSelectorTestReconciler(objs ...client.Object) *SliceReconciler {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = kubeslicev1beta1.AddToScheme(scheme)
+	return &SliceReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+	}
+}
+
+func newSelectorTestSlice(selector *metav1.LabelSelector) *kubeslicev1beta1.Slice {
+	return &kubeslicev1beta1.Slice{
+		ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: ControlPlaneNamespace},
+		Spec:       kubeslicev1beta1.SliceSpec{ApplicationNamespaceSelector: selector},
+	}
+}
+
+func TestGetSelectedAppNamespaces(t *testing.T) {
+	env := map[string]string{"env": "preview"}
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		expected []string
+	}{
+		{"no selector", nil, nil},
+		{"empty selector", &metav1.LabelSelector{}, nil},
+		{"matching selector", &metav1.LabelSelector{MatchLabels: env}, []string{"pr-1", "pr-2"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := newSelectorTestReconciler(
+				newNamespace("pr-1", env),
+				newNamespace("pr-2", map[string]string{"env": "preview", controllers.ApplicationNamespaceSelectorLabelKey: "green"}),
+				// onboarded to another slice
+				newNamespace("pr-3", map[string]string{"env": "preview", controllers.ApplicationNamespaceSelectorLabelKey: "blue"}),
+				newNamespace(ControlPlaneNamespace, env),
+				newNamespace("kube-system", env),
+				newNamespace("istio-system", env),
+				// excluded by EXCLUDED_NS
+				newNamespace("default", env),
+				newNamespace("prod", map[string]string{"env": "prod"}),
+			)
+			selected, err := r.getSelectedAppNamespaces(context.Background(), newSelectorTestSlice(test.selector))
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			if !reflect.DeepEqual(test.expected, selected) {
+				t.Error("Expected namespaces:", test.expected, " but got ", selected)
+			}
+		})
+	}
+}
+
+func TestFindSlicesSelectingNamespace(t *testing.T) {
+	r := newSelectorTestReconciler(newSelectorTestSlice(&metav1.LabelSelector{MatchLabels: map[string]string{"env": "preview"}}))
+	tests := []struct {
+		name     string
+		ns       *corev1.Namespace
+		expected int
+	}{
+		{"namespace matching the selector", newNamespace("pr-1", map[string]string{"env": "preview"}), 1},
+		{"namespace onboarded to the slice", newNamespace("pr-2", map[string]string{controllers.ApplicationNamespaceSelectorLabelKey: "green"}), 1},
+		{"unrelated namespace", newNamespace("prod", map[string]string{"env": "prod"}), 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := r.findSlicesSelectingNamespace(context.Background(), test.ns)
+			if len(requests) != test.expected {
+				t.Error("Expected requests:", test.expected, " but got ", requests)
+			}
+		})
+	}
+}
+
+func TestReconcileSliceNetworkPolicyBackendSwitch(t *testing.T) {
+	slice := &kubeslicev1beta1.Slice{
+		ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: ControlPlaneNamespace},
+		Status: kubeslicev1beta1.SliceStatus{
+			SliceConfig: &kubeslicev1beta1.SliceConfig{
+				NamespaceIsolationProfile: &kubeslicev1beta1.NamespaceIsolationProfile{IsolationEnabled: true},
+			},
+			ApplicationNamespaces:    []string{"iperf"},
+			NetworkPoliciesInstalled: true,
+		},
+	}
+	appNs := newNamespace("iperf", map[string]string{controllers.ApplicationNamespaceSelectorLabelKey: "green"})
+	netpol := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "green-iperf", Namespace: "iperf"}}
+	r := newOffboardingTestReconciler(slice, appNs, netpol)
+	current := &fakeNetpolBackend{backendType: "AdminNetworkPolicy"}
+	r.NetworkPolicyBackend = current
+	ctx := context.Background()
+
+	if err := r.reconcileSliceNetworkPolicy(ctx, slice); err != nil {
+		t.Fatalf("reconcileSliceNetworkPolicy returned error: %v", err)
+	}
+	err := r.Get(ctx, client.ObjectKeyFromObject(netpol), &networkingv1.NetworkPolicy{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Expected the NetworkPolicy of the previous backend to be deleted, got %v", err)
+	}
+	if !reflect.DeepEqual(current.installed, []string{"iperf"}) {
+		t.Errorf("Expected policies installed for iperf, got %v", current.installed)
+	}
+	updated := &kubeslicev1beta1.Slice{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(slice), updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status.NetworkPolicyBackend != "AdminNetworkPolicy" {
+		t.Errorf("Expected backend AdminNetworkPolicy in status, got %q", updated.Status.NetworkPolicyBackend)
+	}
+}
@@ -20,12 +20,17 @@ package slice
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
 	"github.com/kubeslice/worker-operator/pkg/logger"
 	"github.com/kubeslice/worker-operator/pkg/netop"
+	"github.com/kubeslice/worker-operator/pkg/qos"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -54,28 +59,64 @@ func GetNetOpPods(ctx context.Context, namespace string,
 	return pods, nil
 }
 
-// SyncSliceQosProfileWithNetOp Syncs slice qos profile with netop pods
+// SyncSliceQosProfileWithNetOp Syncs slice qos profile with netop pods.
+// The profile is sent to every netop pod, the nodes that could not apply it are reported
+// in the QosApplied condition of the slice.
 func (r *SliceReconciler) SyncSliceQosProfileWithNetOp(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
 	log := logger.FromContext(ctx).WithValues("type", "net_op")
 
+	discipline, err := qos.Resolve(slice.Status.SliceConfig.QosProfileDetails)
+	if err != nil {
+		log.Error(err, "Invalid qos profile")
+		r.updateQosAppliedCondition(ctx, slice, controllers.Condition(kubeslicev1beta1.ConditionQosApplied, false,
+			"InvalidQosProfile", err.Error(), slice.Generation))
+		return err
+	}
+
 	// Get the current list of netop pods.
 	// This populates the NetOpPods map in the slice reconciler structure.
-	err := r.getNetOpPods(ctx, slice.Name, slice.Namespace)
+	err = r.getNetOpPods(ctx, slice.Name, slice.Namespace)
 	if err != nil {
 		return err
 	}
 
+	var failed []string
+	var firstErr error
 	for _, n := range r.NetOpPods {
 		sidecarGrpcAddress := n.PodIP + ":5000"
 		err := r.WorkerNetOpClient.UpdateSliceQosProfile(ctx, sidecarGrpcAddress, slice)
 		if err != nil {
-			log.Error(err, "Failed to send qos to netop. PodIp: %v, PodName: %v", n.PodIP, n.PodName)
-			return err
+			log.Error(err, "Failed to send qos to netop", "podIp", n.PodIP, "podName", n.PodName, "node", n.Node)
+			failed = append(failed, fmt.Sprintf("%s: %v", n.Node, err))
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if firstErr != nil {
+		reason := "QosProfileNotApplied"
+		if qos.NotApplicable(firstErr) {
+			reason = "QosProfileRejected"
 		}
+		r.updateQosAppliedCondition(ctx, slice, controllers.Condition(kubeslicev1beta1.ConditionQosApplied, false, reason,
+			fmt.Sprintf("%s could not be applied on %d of %d nodes: %s", discipline, len(failed), len(r.NetOpPods), strings.Join(failed, "; ")),
+			slice.Generation))
+		return firstErr
 	}
+	r.updateQosAppliedCondition(ctx, slice, controllers.Condition(kubeslicev1beta1.ConditionQosApplied, true, "QosProfileApplied",
+		fmt.Sprintf("%s applied on %d nodes", discipline, len(r.NetOpPods)), slice.Generation))
 	return nil
 }
 
+// updateQosAppliedCondition sets the QosApplied condition of the slice, it is not part of the Ready
+// condition since the slice traffic flows without its QoS profile
+func (r *SliceReconciler) updateQosAppliedCondition(ctx context.Context, slice *kubeslicev1beta1.Slice, condition metav1.Condition) {
+	if err := controllers.SetConditions(ctx, r.Client, slice, &slice.Status.Conditions, nil, condition); err != nil {
+		logger.FromContext(ctx).Error(err, "Failed to update the QosApplied condition")
+	}
+}
+
 func (r *SliceReconciler) getNetOpPods(ctx context.Context, sliceName string, namespace string) error {
 	log := logger.FromContext(ctx).WithValues("type", "net_op")
 	debugLog := log.V(1)
@@ -0,0 +1,385 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package slice
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	ossEvents "github.com/kubeslice/worker-operator/events"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultOffboardingDrainPeriod = 60 * time.Second
+	// same annotation as kubectl rollout restart
+	restartedAtAnnotationKey = "kubectl.kubernetes.io/restartedAt"
+)
+
+// startAppNamespaceOffboarding marks the namespace as offboarding, so that new serviceexports are rejected. The
+// namespace stays on the slice, with its network policies, until the drain period has elapsed, then it is detached
+// and its workloads are restarted by reconcileOffboardingNamespaces. Returns false if the namespace does not exist.
+func (r *SliceReconciler) startAppNamespaceOffboarding(ctx context.Context, slice *kubeslicev1beta1.Slice, appNs string) (bool, error) {
+	log := logger.FromContext(ctx).WithValues("type", "appNamespaces")
+	namespace := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: appNs}, namespace); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if namespace.Labels[controllers.ApplicationNamespaceOffboardingLabelKey] != slice.Name {
+		if namespace.Labels == nil {
+			namespace.Labels = map[string]string{}
+		}
+		namespace.Labels[controllers.ApplicationNamespaceOffboardingLabelKey] = slice.Name
+		if err := r.Update(ctx, namespace); err != nil {
+			log.Error(err, "Failed to mark namespace as offboarding", "namespace", appNs)
+			return true, err
+		}
+	}
+	log.Info("Started offboarding namespace", "namespace", appNs, "drainPeriod", offboardingDrainPeriod())
+	utils.RecordEvent(ctx, r.EventRecorder, slice, nil, ossEvents.EventAppNamespaceOffboardingStarted, controllerName)
+	return true, nil
+}
+
+// detachAppNamespace removes the slice labels and the network policies of the slice from the namespace once the
+// drain period has elapsed
+func (r *SliceReconciler) detachAppNamespace(ctx context.Context, slice *kubeslicev1beta1.Slice, appNs string) error {
+	log := logger.FromContext(ctx).WithValues("type", "appNamespaces")
+	configLabels, configAnnotations, err := r.HubClient.GetClusterNamespaceConfig(ctx, controllers.ClusterName)
+	if err != nil {
+		return err
+	}
+	if _, err := r.unlabelAppNamespace(ctx, appNs, configLabels, configAnnotations, slice.Name); err != nil {
+		return err
+	}
+	if err := r.netpolBackend().UnbindNamespace(ctx, slice, appNs); err != nil {
+		log.Error(err, "NS unbind: Failed to remove slice netpol", "namespace", appNs)
+	}
+	return nil
+}
+
+// addOffboardingNamespaces adds the namespaces to the offboarding list of the slice status
+func addOffboardingNamespaces(offboarding []kubeslicev1beta1.OffboardingNamespace, namespaces []string) []kubeslicev1beta1.OffboardingNamespace {
+	for _, ns := range namespaces {
+		if getOffboardingNamespace(offboarding, ns) != nil {
+			continue
+		}
+		offboarding = append(offboarding, kubeslicev1beta1.OffboardingNamespace{
+			Namespace: ns,
+			Phase:     kubeslicev1beta1.OffboardingPhaseDraining,
+			StartedOn: time.Now().Unix(),
+			Message:   fmt.Sprintf("Rejecting new serviceexports for %s", offboardingDrainPeriod()),
+		})
+	}
+	return offboarding
+}
+
+func getOffboardingNamespace(offboarding []kubeslicev1beta1.OffboardingNamespace, ns string) *kubeslicev1beta1.OffboardingNamespace {
+	for i := range offboarding {
+		if offboarding[i].Namespace == ns {
+			return &offboarding[i]
+		}
+	}
+	return nil
+}
+
+// reconcileOffboardingNamespaces moves the offboarding namespaces through the offboarding phases. Namespaces are
+// removed from the slice status once their workloads are restarted and the pods of the workloads that cannot be
+// restarted are gone, or if they are added back to the slice.
+func (r *SliceReconciler) reconcileOffboardingNamespaces(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
+	log := logger.FromContext(ctx).WithValues("type", "appNamespaces")
+	if len(slice.Status.OffboardingNamespaces) == 0 {
+		return nil
+	}
+	drainPeriod := offboardingDrainPeriod()
+	offboarding := []kubeslicev1beta1.OffboardingNamespace{}
+	statusChanged := false
+	for _, ns := range slice.Status.OffboardingNamespaces {
+		// namespace was added back to the slice while offboarding
+		if exists(slice.Status.ApplicationNamespaces, ns.Namespace) {
+			if err := r.removeOffboardingLabel(ctx, ns.Namespace); err != nil {
+				return err
+			}
+			log.Info("Cancelled namespace offboarding", "namespace", ns.Namespace)
+			statusChanged = true
+			continue
+		}
+		if ns.Phase == kubeslicev1beta1.OffboardingPhaseDraining && time.Since(time.Unix(ns.StartedOn, 0)) < drainPeriod {
+			offboarding = append(offboarding, ns)
+			continue
+		}
+		// the workloads are restarted once the maintenance of the slice ends
+		if controllers.SliceInMaintenance(slice) && ns.Phase != kubeslicev1beta1.OffboardingPhaseWaitingForWorkloads {
+			offboarding = append(offboarding, ns)
+			continue
+		}
+		var unrestartable []kubeslicev1beta1.OffboardingWorkload
+		var err error
+		if ns.Phase == kubeslicev1beta1.OffboardingPhaseWaitingForWorkloads {
+			unrestartable, err = r.remainingUnrestartableWorkloads(ctx, ns.Namespace, ns.UnrestartableWorkloads)
+		} else {
+			unrestartable, err = r.restartAppNamespaceWorkloads(ctx, slice, ns.Namespace)
+		}
+		if err != nil {
+			log.Error(err, "Failed to restart workloads of offboarding namespace", "namespace", ns.Namespace)
+			message := fmt.Sprintf("Failed to restart workloads: %v", err)
+			if ns.Phase != kubeslicev1beta1.OffboardingPhaseRollingWorkloads || ns.Message != message {
+				utils.RecordEvent(ctx, r.EventRecorder, slice, nil, ossEvents.EventAppNamespaceOffboardingFailed, controllerName)
+				ns.Phase = kubeslicev1beta1.OffboardingPhaseRollingWorkloads
+				ns.Message = message
+				statusChanged = true
+			}
+			offboarding = append(offboarding, ns)
+			continue
+		}
+		if len(unrestartable) > 0 {
+			if ns.Phase != kubeslicev1beta1.OffboardingPhaseWaitingForWorkloads || !reflect.DeepEqual(ns.UnrestartableWorkloads, unrestartable) {
+				log.Info("Waiting for the pods of workloads that cannot be restarted", "namespace", ns.Namespace, "workloads", unrestartable)
+				ns.Phase = kubeslicev1beta1.OffboardingPhaseWaitingForWorkloads
+				ns.Message = fmt.Sprintf("%d workloads cannot be restarted, their pods stay attached to the slice until they complete or are deleted", len(unrestartable))
+				ns.UnrestartableWorkloads = unrestartable
+				statusChanged = true
+			}
+			offboarding = append(offboarding, ns)
+			continue
+		}
+		if err := r.removeOffboardingLabel(ctx, ns.Namespace); err != nil {
+			return err
+		}
+		log.Info("Offboarded namespace", "namespace", ns.Namespace)
+		utils.RecordEvent(ctx, r.EventRecorder, slice, nil, ossEvents.EventAppNamespaceOffboarded, controllerName)
+		statusChanged = true
+	}
+	if !statusChanged {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if getErr := r.Get(ctx, types.NamespacedName{Name: slice.Name, Namespace: controllers.ControlPlaneNamespace}, slice); getErr != nil {
+			return getErr
+		}
+		slice.Status.OffboardingNamespaces = offboarding
+		return r.Status().Update(ctx, slice)
+	})
+}
+
+// restartAppNamespaceWorkloads detaches the namespace from the slice, removes the slice labels and annotations from
+// its workloads and restarts the workloads whose pods are still attached to the slice. Deployments, statefulsets and
+// daemonsets are rolled out again and the pods of replicasets without a deployment are deleted to be recreated.
+// Returns the workloads that cannot be restarted: jobs, pods of other controllers and pods without a controller.
+func (r *SliceReconciler) restartAppNamespaceWorkloads(ctx context.Context, slice *kubeslicev1beta1.Slice, appNs string) ([]kubeslicev1beta1.OffboardingWorkload, error) {
+	log := logger.FromContext(ctx).WithValues("type", "appNamespaces")
+	// collect the workloads of the attached pods before their labels are removed
+	podList := corev1.PodList{}
+	if err := r.List(ctx, &podList, client.InNamespace(appNs)); err != nil {
+		return nil, err
+	}
+	workloads := map[string]client.Object{}
+	podsToDelete := []*corev1.Pod{}
+	unrestartable := []kubeslicev1beta1.OffboardingWorkload{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !isPodAttachedToSlice(pod, slice.Name) || isPodCompleted(pod) {
+			continue
+		}
+		workload, err := r.getPodWorkload(ctx, pod)
+		if err != nil {
+			return nil, err
+		}
+		switch workload.(type) {
+		case *appsv1.Deployment, *appsv1.StatefulSet, *appsv1.DaemonSet:
+			workloads[fmt.Sprintf("%T/%s", workload, workload.GetName())] = workload
+		case *appsv1.ReplicaSet:
+			// the replicaset recreates the pod without the slice labels
+			podsToDelete = append(podsToDelete, pod)
+		default:
+			unrestartable = addUnrestartableWorkload(unrestartable, workload, pod)
+		}
+	}
+
+	if err := r.detachAppNamespace(ctx, slice, appNs); err != nil {
+		return nil, err
+	}
+	if err := r.deleteAnnotationsAndLabels(ctx, slice, appNs); err != nil {
+		return nil, err
+	}
+
+	restartedAt := time.Now().Format(time.RFC3339)
+	for _, workload := range workloads {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := r.Get(ctx, client.ObjectKeyFromObject(workload), workload); err != nil {
+				return err
+			}
+			template := getPodTemplate(workload)
+			if template.Annotations == nil {
+				template.Annotations = map[string]string{}
+			}
+			template.Annotations[restartedAtAnnotationKey] = restartedAt
+			return r.Update(ctx, workload)
+		})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Restarted workload of offboarding namespace", "namespace", appNs, "workload", workload.GetName())
+	}
+	for _, pod := range podsToDelete {
+		if err := r.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		log.Info("Deleted pod of replicaset of offboarding namespace", "namespace", appNs, "pod", pod.Name)
+	}
+	return unrestartable, nil
+}
+
+// addUnrestartableWorkload adds the pod to its workload, the pod itself if it has no controller. The pods of a
+// cronjob are reported with their job.
+func addUnrestartableWorkload(workloads []kubeslicev1beta1.OffboardingWorkload, workload client.Object, pod *corev1.Pod) []kubeslicev1beta1.OffboardingWorkload {
+	kind, name := "Pod", pod.Name
+	if workload != nil {
+		kind, name = workload.GetObjectKind().GroupVersionKind().Kind, workload.GetName()
+	}
+	for i := range workloads {
+		if workloads[i].Kind == kind && workloads[i].Name == name {
+			workloads[i].Pods = append(workloads[i].Pods, pod.Name)
+			return workloads
+		}
+	}
+	return append(workloads, kubeslicev1beta1.OffboardingWorkload{Kind: kind, Name: name, Pods: []string{pod.Name}})
+}
+
+// remainingUnrestartableWorkloads returns the workloads with pods that are still running
+func (r *SliceReconciler) remainingUnrestartableWorkloads(ctx context.Context, appNs string, workloads []kubeslicev1beta1.OffboardingWorkload) ([]kubeslicev1beta1.OffboardingWorkload, error) {
+	remaining := []kubeslicev1beta1.OffboardingWorkload{}
+	for _, workload := range workloads {
+		pods := []string{}
+		for _, name := range workload.Pods {
+			pod := &corev1.Pod{}
+			err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: appNs}, pod)
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if !isPodCompleted(pod) {
+				pods = append(pods, name)
+			}
+		}
+		if len(pods) > 0 {
+			workload.Pods = pods
+			remaining = append(remaining, workload)
+		}
+	}
+	return remaining, nil
+}
+
+func isPodCompleted(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+func isPodAttachedToSlice(pod *corev1.Pod, sliceName string) bool {
+	if _, ok := pod.Labels[controllers.NSMIPLabelSelectorKey]; ok {
+		return true
+	}
+	return pod.Labels[controllers.ApplicationNamespaceSelectorLabelKey] == sliceName
+}
+
+// getPodWorkload returns the deployment, statefulset or daemonset managing the pod, the replicaset if it is not
+// managed by a deployment, or the owner reference for other controllers. Returns nil for pods without a controller.
+func (r *SliceReconciler) getPodWorkload(ctx context.Context, pod *corev1.Pod) (client.Object, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return nil, nil
+	}
+	key := types.NamespacedName{Name: owner.Name, Namespace: pod.Namespace}
+	switch owner.Kind {
+	case "StatefulSet":
+		return &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}, nil
+	case "DaemonSet":
+		return &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}, nil
+	case "ReplicaSet":
+		rs := &appsv1.ReplicaSet{}
+		if err := r.Get(ctx, key, rs); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		rsOwner := metav1.GetControllerOf(rs)
+		if rsOwner == nil {
+			return rs, nil
+		}
+		if rsOwner.Kind != "Deployment" {
+			return &metav1.PartialObjectMetadata{TypeMeta: metav1.TypeMeta{Kind: rsOwner.Kind}, ObjectMeta: metav1.ObjectMeta{Name: rsOwner.Name, Namespace: key.Namespace}}, nil
+		}
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: rsOwner.Name, Namespace: key.Namespace}}, nil
+	}
+	return &metav1.PartialObjectMetadata{TypeMeta: metav1.TypeMeta{Kind: owner.Kind}, ObjectMeta: metav1.ObjectMeta{Name: owner.Name, Namespace: key.Namespace}}, nil
+}
+
+func getPodTemplate(workload client.Object) *corev1.PodTemplateSpec {
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		return &w.Spec.Template
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template
+	case *appsv1.DaemonSet:
+		return &w.Spec.Template
+	}
+	return &corev1.PodTemplateSpec{}
+}
+
+func (r *SliceReconciler) removeOffboardingLabel(ctx context.Context, appNs string) error {
+	namespace := &corev1.Namespace{}
+	err := r.Get(ctx, types.NamespacedName{Name: appNs}, namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if _, ok := namespace.Labels[controllers.ApplicationNamespaceOffboardingLabelKey]; !ok {
+		return nil
+	}
+	delete(namespace.Labels, controllers.ApplicationNamespaceOffboardingLabelKey)
+	return r.Update(ctx, namespace)
+}
+
+func offboardingDrainPeriod() time.Duration {
+	drainPeriod, err := time.ParseDuration(controllers.NamespaceOffboardingDrainPeriod)
+	if err != nil || drainPeriod < 0 {
+		return defaultOffboardingDrainPeriod
+	}
+	return drainPeriod
+}
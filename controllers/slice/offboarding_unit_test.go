@@ -0,0 +1,251 @@
+package slice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mevents "github.com/kubeslice/kubeslice-monitoring/pkg/events"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	ossEvents "github.com/kubeslice/worker-operator/events"
+	"github.com/kubeslice/worker-operator/tests/emulator/hubclient"
+	nsmv1 "github.com/networkservicemesh/sdk-k8s/pkg/tools/k8s/apis/networkservicemesh.io/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newOffboardingTestReconciler(objs ...client.Object) *SliceReconciler {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = kubeslicev1beta1.AddToScheme(scheme)
+	_ = nsmv1.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithStatusSubresource(&kubeslicev1beta1.Slice{}).Build()
+	eventRecorder := mevents.NewEventRecorder(c, scheme, ossEvents.EventsMap, mevents.EventRecorderOptions{})
+	hubClient, _ := hubclient.NewHubClientEmulator(c)
+	return &SliceReconciler{Client: c, EventRecorder: &eventRecorder, HubClient: hubClient}
+}
+
+func newOffboardingTestObjects(startedOn time.Time) []client.Object {
+	isController := true
+	slice := &kubeslicev1beta1.Slice{
+		ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: ControlPlaneNamespace},
+		Status: kubeslicev1beta1.SliceStatus{
+			OffboardingNamespaces: []kubeslicev1beta1.OffboardingNamespace{{
+				Namespace: "iperf",
+				Phase:     kubeslicev1beta1.OffboardingPhaseDraining,
+				StartedOn: startedOn.Unix(),
+			}},
+		},
+	}
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "iperf-server", Namespace: "iperf"}}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "iperf-server-abc",
+		Namespace:       "iperf",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "iperf-server", Controller: &isController}},
+	}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            "iperf-server-abc-xyz",
+		Namespace:       "iperf",
+		Labels:          map[string]string{controllers.NSMIPLabelSelectorKey: "10.1.1.2"},
+		OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "iperf-server-abc", Controller: &isController}},
+	}}
+	ns := newNamespace("iperf", map[string]string{controllers.ApplicationNamespaceOffboardingLabelKey: "green"})
+	return []client.Object{slice, deploy, rs, pod, ns}
+}
+
+func TestAddOffboardingNamespaces(t *testing.T) {
+	offboarding := addOffboardingNamespaces(nil, []string{"iperf", "bookinfo"})
+	offboarding = addOffboardingNamespaces(offboarding, []string{"iperf"})
+	if len(offboarding) != 2 {
+		t.Fatal("Expected 2 offboarding namespaces but got ", offboarding)
+	}
+	for _, ns := range offboarding {
+		if ns.Phase != kubeslicev1beta1.OffboardingPhaseDraining {
+			t.Error("Expected phase Draining but got ", ns.Phase)
+		}
+	}
+}
+
+func TestReconcileOffboardingNamespaces(t *testing.T) {
+	tests := []struct {
+		name              string
+		startedOn         time.Time
+		maintenance       bool
+		expectRestart     bool
+		expectOffboarding int
+	}{
+		{"drain period not elapsed", time.Now(), false, false, 1},
+		{"drain period elapsed", time.Now().Add(-time.Hour), false, true, 0},
+		{"slice in maintenance", time.Now().Add(-time.Hour), true, false, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			r := newOffboardingTestReconciler(newOffboardingTestObjects(test.startedOn)...)
+			slice := &kubeslicev1beta1.Slice{}
+			if err := r.Get(ctx, types.NamespacedName{Name: "green", Namespace: ControlPlaneNamespace}, slice); err != nil {
+				t.Fatal(err)
+			}
+			slice.Spec.Maintenance = test.maintenance
+			if err := r.reconcileOffboardingNamespaces(ctx, slice); err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+
+			deploy := &appsv1.Deployment{}
+			if err := r.Get(ctx, types.NamespacedName{Name: "iperf-server", Namespace: "iperf"}, deploy); err != nil {
+				t.Fatal(err)
+			}
+			_, restarted := deploy.Spec.Template.Annotations[restartedAtAnnotationKey]
+			if restarted != test.expectRestart {
+				t.Error("Expected deployment restart:", test.expectRestart, " but got ", restarted)
+			}
+			if err := r.Get(ctx, types.NamespacedName{Name: "green", Namespace: ControlPlaneNamespace}, slice); err != nil {
+				t.Fatal(err)
+			}
+			if len(slice.Status.OffboardingNamespaces) != test.expectOffboarding {
+				t.Error("Expected offboarding namespaces:", test.expectOffboarding, " but got ", slice.Status.OffboardingNamespaces)
+			}
+			ns := &corev1.Namespace{}
+			if err := r.Get(ctx, types.NamespacedName{Name: "iperf"}, ns); err != nil {
+				t.Fatal(err)
+			}
+			_, offboarding := ns.Labels[controllers.ApplicationNamespaceOffboardingLabelKey]
+			if offboarding != (test.expectOffboarding == 1) {
+				t.Error("Unexpected offboarding label on namespace:", ns.Labels)
+			}
+		})
+	}
+}
+
+func TestStartAppNamespaceOffboarding(t *testing.T) {
+	ctx := context.Background()
+	slice := &kubeslicev1beta1.Slice{ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: ControlPlaneNamespace}}
+	r := newOffboardingTestReconciler(slice, newNamespace("iperf", map[string]string{controllers.ApplicationNamespaceSelectorLabelKey: "green"}))
+	started, err := r.startAppNamespaceOffboarding(ctx, slice, "iperf")
+	if err != nil || !started {
+		t.Fatal("Expected offboarding to start but got ", started, err)
+	}
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "iperf"}, ns); err != nil {
+		t.Fatal(err)
+	}
+	if ns.Labels[controllers.ApplicationNamespaceOffboardingLabelKey] != "green" {
+		t.Error("Expected the namespace to be marked as offboarding but got ", ns.Labels)
+	}
+	if ns.Labels[controllers.ApplicationNamespaceSelectorLabelKey] != "green" {
+		t.Error("Expected the namespace to keep the slice label while draining but got ", ns.Labels)
+	}
+	if started, _ := r.startAppNamespaceOffboarding(ctx, slice, "bookinfo"); started {
+		t.Error("Expected offboarding not to start for a missing namespace")
+	}
+}
+
+func TestReconcileOffboardingNamespacesUnrestartableWorkloads(t *testing.T) {
+	ctx := context.Background()
+	isController := true
+	attached := map[string]string{controllers.NSMIPLabelSelectorKey: "10.1.1.3"}
+	objs := newOffboardingTestObjects(time.Now().Add(-time.Hour))
+	objs[len(objs)-1] = newNamespace("iperf", map[string]string{
+		controllers.ApplicationNamespaceSelectorLabelKey:    "green",
+		controllers.ApplicationNamespaceOffboardingLabelKey: "green",
+	})
+	objs = append(objs,
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "iperf-client", Namespace: "iperf"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            "iperf-client-abc",
+			Namespace:       "iperf",
+			Labels:          attached,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "iperf-client", Controller: &isController}},
+		}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "iperf-job", Namespace: "iperf"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            "iperf-job-abc",
+			Namespace:       "iperf",
+			Labels:          attached,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Job", Name: "iperf-job", Controller: &isController}},
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "iperf-debug", Namespace: "iperf", Labels: attached}},
+	)
+	r := newOffboardingTestReconciler(objs...)
+	slice := &kubeslicev1beta1.Slice{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "green", Namespace: ControlPlaneNamespace}, slice); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reconcileOffboardingNamespaces(ctx, slice); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "iperf"}, ns); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ns.Labels[controllers.ApplicationNamespaceSelectorLabelKey]; ok {
+		t.Error("Expected the slice label to be removed after the drain period but got ", ns.Labels)
+	}
+	if err := r.Get(ctx, types.NamespacedName{Name: "iperf-client-abc", Namespace: "iperf"}, &corev1.Pod{}); err == nil {
+		t.Error("Expected the pod of the replicaset to be deleted")
+	}
+	if err := r.Get(ctx, types.NamespacedName{Name: "green", Namespace: ControlPlaneNamespace}, slice); err != nil {
+		t.Fatal(err)
+	}
+	if len(slice.Status.OffboardingNamespaces) != 1 {
+		t.Fatal("Expected the namespace to wait for its workloads but got ", slice.Status.OffboardingNamespaces)
+	}
+	offboarding := slice.Status.OffboardingNamespaces[0]
+	expected := []kubeslicev1beta1.OffboardingWorkload{
+		{Kind: "Job", Name: "iperf-job", Pods: []string{"iperf-job-abc"}},
+		{Kind: "Pod", Name: "iperf-debug", Pods: []string{"iperf-debug"}},
+	}
+	if offboarding.Phase != kubeslicev1beta1.OffboardingPhaseWaitingForWorkloads || !unorderedEqual(offboarding.UnrestartableWorkloads, expected) {
+		t.Error("Expected the unrestartable workloads to be reported but got ", offboarding)
+	}
+
+	// the job completes and the bare pod is deleted
+	job := &corev1.Pod{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "iperf-job-abc", Namespace: "iperf"}, job); err != nil {
+		t.Fatal(err)
+	}
+	job.Status.Phase = corev1.PodSucceeded
+	if err := r.Status().Update(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "iperf-debug", Namespace: "iperf"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reconcileOffboardingNamespaces(ctx, slice); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := r.Get(ctx, types.NamespacedName{Name: "green", Namespace: ControlPlaneNamespace}, slice); err != nil {
+		t.Fatal(err)
+	}
+	if len(slice.Status.OffboardingNamespaces) != 0 {
+		t.Error("Expected the namespace to be offboarded but got ", slice.Status.OffboardingNamespaces)
+	}
+}
+
+func unorderedEqual(got, expected []kubeslicev1beta1.OffboardingWorkload) bool {
+	if len(got) != len(expected) {
+		return false
+	}
+	for _, e := range expected {
+		found := false
+		for _, g := range got {
+			if g.Kind == e.Kind && g.Name == e.Name && len(g.Pods) == len(e.Pods) && g.Pods[0] == e.Pods[0] {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
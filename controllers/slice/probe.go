@@ -0,0 +1,222 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package slice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	"github.com/kubeslice/worker-operator/pkg/probe"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// ReconcileProbe deploys the connectivity probe pod of the slice when its probes are enabled and
+// removes it when they are disabled. The probes are run by the prober of the operator.
+func (r *SliceReconciler) ReconcileProbe(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
+	log := logger.FromContext(ctx).WithName("slice-probe")
+
+	found := &appsv1.Deployment{}
+	err := r.Get(ctx, types.NamespacedName{Name: probe.DeploymentName(slice.Name), Namespace: controllers.ControlPlaneNamespace}, found)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if slice.Spec.ConnectivityProbe == nil {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: probe.SecretName(slice.Name), Namespace: controllers.ControlPlaneNamespace}}
+		if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if !exists {
+			return nil
+		}
+		log.Info("Connectivity probes disabled, deleting probe deployment", "name", found.Name)
+		if err := r.Delete(ctx, found); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	image := operatorconfig.Image(operatorconfig.ProbeImageEnv)
+	if image == "" {
+		log.Info("Connectivity probes enabled but no probe image is configured, set " + operatorconfig.ProbeImageEnv)
+		return nil
+	}
+	if slice.Status.SliceConfig == nil || slice.Status.SliceConfig.SliceSubnet == "" {
+		log.Info("Waiting for the slice subnet to deploy the probe pod")
+		return nil
+	}
+	if err := r.reconcileProbeSecret(ctx, slice); err != nil {
+		log.Error(err, "Failed to create probe secret")
+		return err
+	}
+	deployment := deploymentForProbe(slice.Name, image, slice.Status.SliceConfig.SliceSubnet)
+	if exists {
+		// the image and the arguments change with the operator configuration and the slice subnet
+		container := &found.Spec.Template.Spec.Containers[0]
+		desired := deployment.Spec.Template.Spec.Containers[0]
+		if container.Image == desired.Image && reflect.DeepEqual(container.Args, desired.Args) && reflect.DeepEqual(container.Env, desired.Env) {
+			return nil
+		}
+		container.Image, container.Args, container.Env = desired.Image, desired.Args, desired.Env
+		if err := r.Update(ctx, found); err != nil {
+			log.Error(err, "Failed to update probe deployment")
+			return err
+		}
+		log.Info("Updated probe deployment", "name", found.Name, "image", desired.Image)
+		return nil
+	}
+	if err := ctrl.SetControllerReference(slice, deployment, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, deployment); err != nil && !apierrors.IsAlreadyExists(err) {
+		log.Error(err, "Failed to create probe deployment")
+		return err
+	}
+	log.Info("Created probe deployment", "name", deployment.Name)
+	return nil
+}
+
+// reconcileProbeSecret creates the secret with the token the operator authenticates to the probe pod with
+func (r *SliceReconciler) reconcileProbeSecret(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: probe.SecretName(slice.Name), Namespace: controllers.ControlPlaneNamespace}, secret)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return err
+	}
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      probe.SecretName(slice.Name),
+			Namespace: controllers.ControlPlaneNamespace,
+			Labels:    probe.PodLabels(slice.Name),
+		},
+		Data: map[string][]byte{probe.TokenKey: []byte(hex.EncodeToString(token))},
+	}
+	if err := ctrl.SetControllerReference(slice, secret, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, secret); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// deploymentForProbe returns the deployment of the probe pod of a slice. The pod runs the probe
+// subcommand of the operator image and is connected to the slice like the application pods.
+func deploymentForProbe(sliceName, image, sliceSubnet string) *appsv1.Deployment {
+	var replicas int32 = 1
+	var automount = false
+	var nonRoot = true
+	var user int64 = 65532
+	var allowPrivilegeEscalation = false
+	ls := probe.PodLabels(sliceName)
+
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      probe.DeploymentName(sliceName),
+			Namespace: controllers.ControlPlaneNamespace,
+			Labels:    ls,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: ls,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: ls,
+					Annotations: map[string]string{
+						"networkservicemesh.io": fmt.Sprintf("kernel://vl3-service-%s/nsm0", sliceName),
+					},
+				},
+				Spec: corev1.PodSpec{
+					AutomountServiceAccountToken: &automount,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot: &nonRoot,
+						RunAsUser:    &user,
+						// allows the icmp echo requests from unprivileged datagram sockets
+						Sysctls: []corev1.Sysctl{{
+							Name:  "net.ipv4.ping_group_range",
+							Value: "0 2147483647",
+						}},
+					},
+					Containers: []corev1.Container{{
+						Name:            "probe",
+						Image:           image,
+						ImagePullPolicy: corev1.PullIfNotPresent,
+						Command:         []string{"/manager"},
+						Args:            []string{"probe", fmt.Sprintf("--listen=:%d", probe.AgentPort), "--subnet=" + sliceSubnet},
+						Env: []corev1.EnvVar{{
+							Name: probe.TokenEnv,
+							ValueFrom: &corev1.EnvVarSource{
+								SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: probe.SecretName(sliceName)},
+									Key:                  probe.TokenKey,
+								},
+							},
+						}},
+						Ports: []corev1.ContainerPort{{
+							Name:          "probe",
+							ContainerPort: probe.AgentPort,
+						}},
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+						},
+						Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{
+								"memory": resource.MustParse("64Mi"),
+								"cpu":    resource.MustParse("100m"),
+							},
+							Requests: corev1.ResourceList{
+								"memory": resource.MustParse("16Mi"),
+								"cpu":    resource.MustParse("10m"),
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	if len(controllers.ImagePullSecretName) != 0 {
+		dep.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{
+			Name: controllers.ImagePullSecretName,
+		}}
+	}
+
+	return dep
+}
@@ -0,0 +1,97 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package slice
+
+import (
+	"context"
+	"testing"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestReconcileProbe(t *testing.T) {
+	t.Setenv(operatorconfig.ProbeImageEnv, "kubeslice/worker-operator:test")
+	slice := &kubeslicev1beta1.Slice{
+		ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: ControlPlaneNamespace, UID: "green-uid"},
+		Spec:       kubeslicev1beta1.SliceSpec{ConnectivityProbe: &kubeslicev1beta1.ConnectivityProbe{}},
+		Status:     kubeslicev1beta1.SliceStatus{SliceConfig: &kubeslicev1beta1.SliceConfig{SliceSubnet: "10.1.0.0/16"}},
+	}
+	r := newOffboardingTestReconciler(slice)
+	r.Scheme = r.Client.Scheme()
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "green-probe", Namespace: ControlPlaneNamespace}
+
+	if err := r.ReconcileProbe(ctx, slice); err != nil {
+		t.Fatal(err)
+	}
+	dep := &appsv1.Deployment{}
+	if err := r.Get(ctx, key, dep); err != nil {
+		t.Fatalf("expected probe deployment to be created: %v", err)
+	}
+	pod := dep.Spec.Template
+	if pod.Annotations["networkservicemesh.io"] != "kernel://vl3-service-green/nsm0" {
+		t.Errorf("expected probe pod to be connected to the slice, got annotations %v", pod.Annotations)
+	}
+	if c := pod.Spec.Containers[0]; c.Image != "kubeslice/worker-operator:test" || c.Args[0] != "probe" || c.Args[2] != "--subnet=10.1.0.0/16" {
+		t.Errorf("expected probe container to run the probe subcommand in the slice subnet, got %s %v", c.Image, c.Args)
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, key, secret); err != nil || len(secret.Data["token"]) == 0 {
+		t.Fatalf("expected probe secret with a token to be created: %v", err)
+	}
+	if ref := pod.Spec.Containers[0].Env[0].ValueFrom.SecretKeyRef; ref.Name != "green-probe" || ref.Key != "token" {
+		t.Errorf("expected the token to be read from the probe secret, got %v", ref)
+	}
+	token := string(secret.Data["token"])
+
+	if len(dep.OwnerReferences) != 1 || dep.OwnerReferences[0].Name != "green" {
+		t.Errorf("expected probe deployment to be owned by the slice, got %v", dep.OwnerReferences)
+	}
+
+	t.Setenv(operatorconfig.ProbeImageEnv, "kubeslice/worker-operator:next")
+	if err := r.ReconcileProbe(ctx, slice); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, dep); err != nil {
+		t.Fatal(err)
+	}
+	if c := dep.Spec.Template.Spec.Containers[0]; c.Image != "kubeslice/worker-operator:next" {
+		t.Errorf("expected probe deployment to be updated to the new image, got %s", c.Image)
+	}
+	if err := r.Get(ctx, key, secret); err != nil || string(secret.Data["token"]) != token {
+		t.Errorf("expected the token to be kept, got %v", err)
+	}
+
+	slice.Spec.ConnectivityProbe = nil
+	if err := r.ReconcileProbe(ctx, slice); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, dep); !apierrors.IsNotFound(err) {
+		t.Errorf("expected probe deployment to be deleted, got %v", err)
+	}
+	if err := r.Get(ctx, key, secret); !apierrors.IsNotFound(err) {
+		t.Errorf("expected probe secret to be deleted, got %v", err)
+	}
+}
@@ -51,6 +51,9 @@ import (
 	ossEvents "github.com/kubeslice/worker-operator/events"
 	"github.com/kubeslice/worker-operator/pkg/logger"
 	"github.com/kubeslice/worker-operator/pkg/manifest"
+	"github.com/kubeslice/worker-operator/pkg/networkpolicy/backend"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -73,6 +76,8 @@ type SliceReconciler struct {
 	WorkerRouterClient      WorkerRouterClientProvider
 	WorkerNetOpClient       WorkerNetOpClientProvider
 	WorkerGatewayEdgeClient WorkerGatewayEdgeClientProvider
+	NetworkPolicyBackend    backend.Backend
+	HubConnection           HubConnectionProvider
 
 	// metrics
 	gaugeAppPods *prometheus.GaugeVec
@@ -82,7 +87,10 @@ type SliceReconciler struct {
 //+kubebuilder:rbac:groups=networking.kubeslice.io,resources=slices/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.kubeslice.io,resources=slices/finalizers,verbs=update
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;update;delete
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch;create;update;patch;delete
@@ -91,11 +99,15 @@ type SliceReconciler struct {
 //+kubebuilder:rbac:groups=networking.istio.io,resources=gateways,verbs=get;list;create;update;watch;delete
 //+kubebuilder:rbac:groups=networking.istio.io,resources=serviceentries,verbs=get;list;create;update;watch;delete
 //+kubebuilder:rbac:groups=networking.istio.io,resources=virtualservices,verbs=get;list;create;update;watch;delete
-//+kubebuilder:webhook:path=/mutate-webhook,mutating=true,failurePolicy=fail,groups="";apps,resources=pods;deployments;statefulsets;daemonsets,verbs=create;update,versions=v1,name=webhook.kubeslice.io,admissionReviewVersions=v1,sideEffects=NoneOnDryRun
+//+kubebuilder:webhook:path=/mutate-webhook,mutating=true,failurePolicy=fail,groups="";apps;batch,resources=pods;deployments;statefulsets;daemonsets;replicasets;jobs;cronjobs,verbs=create;update,versions=v1,name=webhook.kubeslice.io,admissionReviewVersions=v1,sideEffects=NoneOnDryRun
 //+kubebuilder:webhook:path=/validate-webhook,mutating=false,failurePolicy=fail,groups="networking.kubeslice.io",resources=serviceexports,verbs=create;update,versions=v1beta1,name=webhook.kubeslice.io,admissionReviewVersions=v1,sideEffects=NoneOnDryRun
 //+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=policy.networking.k8s.io,resources=adminnetworkpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cilium.io,resources=ciliumclusterwidenetworkpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=projectcalico.org,resources=globalnetworkpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networkservicemesh.io,resources=networkserviceendpoints,verbs=get;list;watch
 
 func (r *SliceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("slice", req.NamespacedName)
@@ -156,12 +168,29 @@ func (r *SliceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return result, err
 	}
 
+	if err := r.updateHubConnectedCondition(ctx, slice); err != nil {
+		log.Error(err, "Failed to update hub connected condition")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateMaintenanceCondition(ctx, slice); err != nil {
+		log.Error(err, "Failed to update maintenance condition")
+		return ctrl.Result{}, err
+	}
+
 	if slice.Status.SliceConfig == nil {
 		err := fmt.Errorf("slice not reconciled from hub")
 		log.Error(err, "Slice is not reconciled from hub yet, skipping reconciliation")
 		return ctrl.Result{}, err
 	}
 
+	// report the state of the slice components even if the reconciliation is requeued midway
+	defer func() {
+		if err := r.updateSliceConditions(ctx, slice); err != nil {
+			log.Error(err, "Failed to update slice conditions")
+		}
+	}()
+
 	if slice.Status.SliceConfig.SliceOverlayNetworkDeploymentMode != controllerv1alpha1.NONET {
 		if slice.Status.DNSIP == "" {
 			requeue, result, err := r.handleDnsSvc(ctx, slice)
@@ -180,6 +209,11 @@ func (r *SliceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	if slice.Status.SliceConfig.SliceOverlayNetworkDeploymentMode == controllerv1alpha1.NONET {
 		debugLog.Info("No communication slice, skipping reconciliation of qos, netop, egw, router etc")
 		// to support net to no-net switching write a function to delete network components if present
+	} else if controllers.SliceInMaintenance(slice) {
+		log.Info("Slice is in maintenance, skipping reconciliation of qos, netop, egw, router etc")
+	} else if len(slice.Status.SubnetOverlaps) > 0 {
+		log.Info("Slice subnets overlap with the cluster CIDRs, skipping reconciliation of router, slicegw edge etc",
+			"overlaps", slice.Status.SubnetOverlaps)
 	} else {
 		debugLog.Info("Slice with network, continue reconciliation of qos, netop, egw, router etc")
 		// syncQoStoNetop, reconcile slice router, slicegw edge, ext gateways
@@ -214,7 +248,7 @@ func (r *SliceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	r.exposeMetric(slice.Status.AppPods, slice)
 
 	return ctrl.Result{
-		RequeueAfter: controllers.ReconcileInterval,
+		RequeueAfter: operatorconfig.ReconcileInterval(controllers.ReconcileInterval),
 	}, nil
 }
 
@@ -251,6 +285,12 @@ func (r *SliceReconciler) reconcileNetworkComponents(ctx context.Context, slice
 		return res, nil, true
 	}
 
+	debugLog.Info("reconciling connectivity probe")
+	if err := r.ReconcileProbe(ctx, slice); err != nil {
+		log.Error(err, "Failed to reconcile connectivity probe")
+		return ctrl.Result{}, err, true
+	}
+
 	debugLog.Info("ExternalGatewayConfig", "obj", slice.Status.SliceConfig.ExternalGatewayConfig)
 	if slice.Status.SliceConfig.ExternalGatewayConfig != nil &&
 		slice.Status.SliceConfig.ExternalGatewayConfig.GatewayType == controllerv1alpha1.GATEWAY_TYPE_ISTIO {
@@ -413,6 +453,10 @@ func (r *SliceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Pod{}).
 		Owns(&kubeslicev1beta1.SliceGateway{}).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.findSlicesSelectingNamespace),
+		).
 		Watches(
 			&appsv1.Deployment{},
 			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) (recs []reconcile.Request) {
@@ -546,5 +590,5 @@ func (r *SliceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				},
 			}),
 		).
-		Complete(r)
+		Complete(tracing.NewReconciler("slice", r))
 }
@@ -28,6 +28,7 @@ import (
 	"github.com/kubeslice/worker-operator/controllers"
 	"github.com/kubeslice/worker-operator/pkg/cluster"
 	"github.com/kubeslice/worker-operator/pkg/gatewayedge"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -226,15 +227,21 @@ func (r *SliceReconciler) reconcileSliceGatewayEdgeService(ctx context.Context,
 	return ctrl.Result{}, nil, false
 }
 
-func deploymentForSliceGatewayEdge(sliceName, depName string) *appsv1.Deployment {
-	var replicas int32 = 1
-	var privileged = true
-
-	gwEdgeImg := os.Getenv("AVESHA_SLICE_GW_EDGE_IMAGE")
+// getSliceGatewayEdgeImage returns the image of the slice gateway edge container
+func getSliceGatewayEdgeImage() string {
+	gwEdgeImg := operatorconfig.Image(operatorconfig.SliceGatewayEdgeImageEnv)
 	if gwEdgeImg == "" {
 		// TODO: Push the default image to aveshalabs nexus
 		gwEdgeImg = "aveshatest/kubeslice-gateway-edge:1.0.0"
 	}
+	return gwEdgeImg
+}
+
+func deploymentForSliceGatewayEdge(sliceName, depName string) *appsv1.Deployment {
+	var replicas int32 = 1
+	var privileged = true
+
+	gwEdgeImg := getSliceGatewayEdgeImage()
 
 	imgPullPolicy := corev1.PullAlways
 
@@ -430,6 +437,26 @@ func (r *SliceReconciler) reconcileSliceGatewayEdgeDeployment(ctx context.Contex
 		return ctrl.Result{Requeue: true}, nil, true
 	}
 
+	// update if the gateway edge image has been changed in the operator config or worker env vars
+	gwEdgeImg := getSliceGatewayEdgeImage()
+	for i := range gwEdgeDeployments.Items {
+		deployment := &gwEdgeDeployments.Items[i]
+		for j := range deployment.Spec.Template.Spec.Containers {
+			container := &deployment.Spec.Template.Spec.Containers[j]
+			if container.Name != "kubeslice-gateway-edge" || container.Image == gwEdgeImg {
+				continue
+			}
+			container.Image = gwEdgeImg
+			log := r.Log.WithValues("slice", slice.Name)
+			log.Info("updating slice gw edge Deployment", "Name", deployment.Name, "image", gwEdgeImg)
+			if err := r.Update(ctx, deployment); err != nil {
+				log.Error(err, "Failed to update slice gw edge Deployment", "Name", deployment.Name)
+				return ctrl.Result{}, err, true
+			}
+			return ctrl.Result{Requeue: true}, nil, true
+		}
+	}
+
 	return ctrl.Result{}, nil, false
 }
 
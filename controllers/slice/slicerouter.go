@@ -31,6 +31,7 @@ import (
 
 	"github.com/kubeslice/worker-operator/controllers"
 	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 	webhook "github.com/kubeslice/worker-operator/pkg/webhook/pod"
 	appsv1 "k8s.io/api/apps/v1"
@@ -64,7 +65,7 @@ func labelsForSliceRouterDeployment(name string) map[string]string {
 func getSliceRouterSidecarImageAndPullPolicy() (string, corev1.PullPolicy) {
 	pullPolicy := corev1.PullAlways
 
-	sliceRouterSidecarImage := os.Getenv("AVESHA_VL3_SIDECAR_IMAGE")
+	sliceRouterSidecarImage := operatorconfig.Image(operatorconfig.SliceRouterSidecarImageEnv)
 	sliceRouterSidecarImagePullPolicy := os.Getenv("AVESHA_VL3_SIDECAR_IMAGE_PULLPOLICY")
 
 	if len(sliceRouterSidecarImagePullPolicy) > 0 {
@@ -93,16 +94,22 @@ func (r *SliceReconciler) getNsmDataplaneMode(ctx context.Context, slice *kubesl
 	return nsmDataplaneKernel, nil
 }
 
-func (r *SliceReconciler) getContainerSpecForSliceRouter(s *kubeslicev1beta1.Slice, dataplane string) corev1.Container {
-	vl3ImagePullPolicy := corev1.PullAlways
+func getSliceRouterImageAndPullPolicy() (string, corev1.PullPolicy) {
+	pullPolicy := corev1.PullAlways
 
-	vl3Image := os.Getenv("AVESHA_VL3_ROUTER_IMAGE")
+	vl3Image := operatorconfig.Image(operatorconfig.SliceRouterImageEnv)
 	vl3RouterPullPolicy := os.Getenv("AVESHA_VL3_ROUTER_PULLPOLICY")
 
 	if len(vl3RouterPullPolicy) != 0 {
-		vl3ImagePullPolicy = corev1.PullPolicy(vl3RouterPullPolicy)
+		pullPolicy = corev1.PullPolicy(vl3RouterPullPolicy)
 	}
 
+	return vl3Image, pullPolicy
+}
+
+func (r *SliceReconciler) getContainerSpecForSliceRouter(s *kubeslicev1beta1.Slice, dataplane string) corev1.Container {
+	vl3Image, vl3ImagePullPolicy := getSliceRouterImageAndPullPolicy()
+
 	privileged := true
 
 	sliceRouterContainer := corev1.Container{
@@ -333,6 +340,41 @@ func (r *SliceReconciler) deploymentForSliceRouter(s *kubeslicev1beta1.Slice, da
 	return dep
 }
 
+// reconcileSliceRouterDeployment updates the images of the slice router deployment when they are changed
+// in the operator config or the environment
+func (r *SliceReconciler) reconcileSliceRouterDeployment(ctx context.Context, dep *appsv1.Deployment) error {
+	log := logger.FromContext(ctx).WithName("slice-router")
+	vl3Image, vl3PullPolicy := getSliceRouterImageAndPullPolicy()
+	sidecarImage, sidecarPullPolicy := getSliceRouterSidecarImageAndPullPolicy()
+	updated := false
+	containers := dep.Spec.Template.Spec.Containers
+	for i := range containers {
+		image, pullPolicy := "", corev1.PullPolicy("")
+		switch containers[i].Name {
+		case "vl3-nse":
+			image, pullPolicy = vl3Image, vl3PullPolicy
+		case "kubeslice-vl3-sidecar":
+			image, pullPolicy = sidecarImage, sidecarPullPolicy
+		default:
+			continue
+		}
+		if containers[i].Image != image || containers[i].ImagePullPolicy != pullPolicy {
+			containers[i].Image = image
+			containers[i].ImagePullPolicy = pullPolicy
+			updated = true
+		}
+	}
+	if !updated {
+		return nil
+	}
+	if err := r.Update(ctx, dep); err != nil {
+		log.Error(err, "Failed to update slice router")
+		return err
+	}
+	log.Info("Updated slice router images", "Name", dep.Name, "image", vl3Image, "sidecarImage", sidecarImage)
+	return nil
+}
+
 // Deploys the vL3 slice router.
 // The configmap needed for the NSE is created first before the NSE is launched.
 func (r *SliceReconciler) deploySliceRouter(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
@@ -409,6 +451,9 @@ func (r *SliceReconciler) ReconcileSliceRouter(ctx context.Context, slice *kubes
 		}
 		return ctrl.Result{}, err, true
 	}
+	if err := r.reconcileSliceRouterDeployment(ctx, foundSliceRouter); err != nil {
+		return ctrl.Result{}, err, true
+	}
 
 	foundSvc := &corev1.Service{}
 	err = r.Get(ctx, types.NamespacedName{
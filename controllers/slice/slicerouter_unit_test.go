@@ -0,0 +1,70 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package slice
+
+import (
+	"context"
+	"testing"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestReconcileSliceRouterImages(t *testing.T) {
+	slice := &kubeslicev1beta1.Slice{
+		ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: ControlPlaneNamespace, UID: "green-uid"},
+		Status: kubeslicev1beta1.SliceStatus{SliceConfig: &kubeslicev1beta1.SliceConfig{
+			ClusterSubnetCIDR: "10.1.1.0/24",
+		}},
+	}
+	r := newOffboardingTestReconciler(slice)
+	r.Scheme = r.Client.Scheme()
+	ctx := context.Background()
+
+	t.Setenv("AVESHA_VL3_ROUTER_IMAGE", "vl3:1.0")
+	t.Setenv("AVESHA_VL3_SIDECAR_IMAGE", "vl3-sidecar:1.0")
+	dep := r.deploymentForSliceRouter(slice, nsmDataplaneKernel)
+	if err := r.Create(ctx, dep); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("AVESHA_VL3_ROUTER_IMAGE", "vl3:1.1")
+	t.Setenv("AVESHA_VL3_SIDECAR_IMAGE_PULLPOLICY", string(corev1.PullIfNotPresent))
+	if err := r.reconcileSliceRouterDeployment(ctx, dep); err != nil {
+		t.Fatal(err)
+	}
+	updated := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "vl3-slice-router-green", Namespace: ControlPlaneNamespace}, updated); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range updated.Spec.Template.Spec.Containers {
+		switch c.Name {
+		case "vl3-nse":
+			if c.Image != "vl3:1.1" {
+				t.Error("Expected the router image to be updated but got ", c.Image)
+			}
+		case "kubeslice-vl3-sidecar":
+			if c.Image != "vl3-sidecar:1.0" || c.ImagePullPolicy != corev1.PullIfNotPresent {
+				t.Error("Expected the sidecar pull policy to be updated but got ", c.Image, c.ImagePullPolicy)
+			}
+		}
+	}
+}
@@ -20,9 +20,11 @@ package slice
 
 import (
 	"context"
+	"time"
 
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
 	"github.com/kubeslice/worker-operator/pkg/gatewayedge"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
 	"github.com/kubeslice/worker-operator/pkg/netop"
 	"github.com/kubeslice/worker-operator/pkg/router"
 )
@@ -40,6 +42,11 @@ type HubClientProvider interface {
 	GetClusterNamespaceConfig(ctx context.Context, clusterName string) (map[string]string, map[string]string, error)
 }
 
+// HubConnectionProvider reports the state of the connection to the hub cluster
+type HubConnectionProvider interface {
+	Status() (hub.ConnectionState, time.Time, error)
+}
+
 type WorkerRouterClientProvider interface {
 	GetClientConnectionInfo(ctx context.Context, addr string) ([]kubeslicev1beta1.AppPod, error)
 	SendConnectionContext(ctx context.Context, serverAddr string, sliceRouterConnCtx *router.SliceRouterConnCtx) error
@@ -0,0 +1,288 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package slicecapture
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	webhook "github.com/kubeslice/worker-operator/pkg/webhook/pod"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultDuration is the duration of a capture that does not set one
+	DefaultDuration = 60 * time.Second
+	// collectGracePeriod is the time given to the capture containers to start and exit after the duration
+	collectGracePeriod = time.Minute
+	// maxSecretSize caps the pcap files of a capture, as they are staged in a secret
+	maxSecretSize = 1000 * 1024
+	// captureMountPath is where the collector pod mounts the PVC
+	captureMountPath = "/pcap"
+	// stagingMountPath is where the collector pod mounts the staging secret
+	stagingMountPath = "/staging"
+)
+
+// DefaultMaxSize caps the pcap file of each pod of a capture that does not set one
+var DefaultMaxSize = resource.MustParse("512Ki")
+
+// captureScript captures the packets for $0 seconds on the interface $1 with the filter $2 and writes
+// at most $3 bytes of pcap, base64 encoded, to the logs of the container. tcpdump errors are reported
+// in the termination message.
+const captureScript = `timeout "$0" tcpdump -i "$1" -U -w - ${2:+"$2"} 2>/tmp/tcpdump.err | head -c "$3" >/tmp/capture.pcap
+if [ ! -s /tmp/capture.pcap ]; then cat /tmp/tcpdump.err >/dev/termination-log; exit 1; fi
+base64 /tmp/capture.pcap`
+
+// collectScript copies the staged pcap files in the $0 directory of the PVC
+const collectScript = `mkdir -p "` + captureMountPath + `/$0" && cp ` + stagingMountPath + `/*.pcap "` + captureMountPath + `/$0/"`
+
+func captureDuration(capture *kubeslicev1beta1.SliceCapture) time.Duration {
+	if capture.Spec.Duration != nil && capture.Spec.Duration.Duration > 0 {
+		return capture.Spec.Duration.Duration
+	}
+	return DefaultDuration
+}
+
+// podMaxSize returns the size cap of the pcap file of each of the given number of pods
+func podMaxSize(capture *kubeslicev1beta1.SliceCapture, pods int) int64 {
+	size := DefaultMaxSize.Value()
+	if capture.Spec.MaxSize != nil && capture.Spec.MaxSize.Value() > 0 {
+		size = capture.Spec.MaxSize.Value()
+	}
+	if pods > 0 && size*int64(pods) > maxSecretSize {
+		size = maxSecretSize / int64(pods)
+	}
+	return size
+}
+
+// validate returns why the capture cannot run, if any
+func validate(capture *kubeslicev1beta1.SliceCapture) string {
+	if capture.Namespace != controllers.ControlPlaneNamespace {
+		return "slicecaptures are only run in the " + controllers.ControlPlaneNamespace + " namespace"
+	}
+	if capture.Spec.Target == kubeslicev1beta1.CaptureTargetGateway && capture.Spec.Gateway == "" {
+		return "gateway is required for the Gateway target"
+	}
+	if capture.Spec.Storage.Type == kubeslicev1beta1.CaptureStoragePersistentVolumeClaim && capture.Spec.Storage.ClaimName == "" {
+		return "claimName is required for the PersistentVolumeClaim storage"
+	}
+	return ""
+}
+
+// targetPods returns the running pods of the slice in which the packets are captured, sorted by name
+func targetPods(ctx context.Context, c client.Client, capture *kubeslicev1beta1.SliceCapture) ([]corev1.Pod, error) {
+	pods := []corev1.Pod{}
+	switch capture.Spec.Target {
+	case kubeslicev1beta1.CaptureTargetGateway:
+		gwPods, err := gatewayPods(ctx, c, client.MatchingLabels{controllers.SliceGatewaySelectorLabelKey: capture.Spec.Gateway})
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, gwPods...)
+	case kubeslicev1beta1.CaptureTargetRouter:
+		routerPods, err := sliceRouterPods(ctx, c, capture.Spec.SliceName)
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, routerPods...)
+	default:
+		gwPods, err := gatewayPods(ctx, c, client.MatchingLabels{controllers.ApplicationNamespaceSelectorLabelKey: capture.Spec.SliceName})
+		if err != nil {
+			return nil, err
+		}
+		routerPods, err := sliceRouterPods(ctx, c, capture.Spec.SliceName)
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, gwPods...)
+		pods = append(pods, routerPods...)
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods, nil
+}
+
+// sliceRouterPods returns the running slice router pod of the slice, if any
+func sliceRouterPods(ctx context.Context, c client.Client, sliceName string) ([]corev1.Pod, error) {
+	podName, _, err := controllers.GetSliceRouterPodNameAndIP(ctx, c, sliceName)
+	if err != nil || podName == "" {
+		return nil, err
+	}
+	pod := &corev1.Pod{}
+	if err := c.Get(ctx, client.ObjectKey{Name: podName, Namespace: controllers.ControlPlaneNamespace}, pod); err != nil {
+		return nil, err
+	}
+	return []corev1.Pod{*pod}, nil
+}
+
+func gatewayPods(ctx context.Context, c client.Client, selector client.MatchingLabels) ([]corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	selector[webhook.PodInjectLabelKey] = "slicegateway"
+	if err := c.List(ctx, podList, selector, client.InNamespace(controllers.ControlPlaneNamespace)); err != nil {
+		return nil, err
+	}
+	pods := []corev1.Pod{}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase != corev1.PodRunning || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+// containerName returns the name of the ephemeral containers of a capture. It is unique per capture
+// since ephemeral containers cannot be removed from the pods.
+func containerName(capture *kubeslicev1beta1.SliceCapture) string {
+	id := string(capture.UID)
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return "capture-" + id
+}
+
+// captureContainer returns the ephemeral container capturing the packets of a pod
+func captureContainer(capture *kubeslicev1beta1.SliceCapture, image string, maxSize int64) corev1.EphemeralContainer {
+	iface := capture.Spec.Interface
+	if iface == "" {
+		iface = "any"
+	}
+	return corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:  containerName(capture),
+			Image: image,
+			Command: []string{"sh", "-c", captureScript,
+				strconv.Itoa(int(captureDuration(capture).Seconds())), iface, capture.Spec.Filter, strconv.FormatInt(maxSize, 10)},
+			SecurityContext: &corev1.SecurityContext{
+				Capabilities: &corev1.Capabilities{
+					Add: []corev1.Capability{"NET_ADMIN", "NET_RAW"},
+				},
+			},
+		},
+	}
+}
+
+func hasEphemeralContainer(pod *corev1.Pod, name string) bool {
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// terminatedState returns the terminated state of an ephemeral container, nil while it runs
+func terminatedState(pod *corev1.Pod, name string) *corev1.ContainerStateTerminated {
+	for _, s := range pod.Status.EphemeralContainerStatuses {
+		if s.Name == name {
+			return s.State.Terminated
+		}
+	}
+	return nil
+}
+
+// decodePcap decodes the pcap written by the capture script in the logs of the container
+func decodePcap(logs []byte) ([]byte, error) {
+	encoded := strings.Join(strings.Fields(string(logs)), "")
+	pcap, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the pcap from the container logs: %w", err)
+	}
+	return pcap, nil
+}
+
+func terminationMessage(state *corev1.ContainerStateTerminated) string {
+	msg := strings.TrimSpace(state.Message)
+	if msg == "" {
+		msg = state.Reason
+	}
+	return fmt.Sprintf("capture exited with code %d: %s", state.ExitCode, msg)
+}
+
+func stagingSecretName(capture *kubeslicev1beta1.SliceCapture) string {
+	return capture.Name + "-pcap"
+}
+
+func collectorPodName(capture *kubeslicev1beta1.SliceCapture) string {
+	return capture.Name + "-collect"
+}
+
+func pcapKey(pod string) string {
+	return pod + ".pcap"
+}
+
+// collectorPod returns the pod copying the staged pcap files to the PVC of the capture
+func collectorPod(capture *kubeslicev1beta1.SliceCapture, image string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      collectorPodName(capture),
+			Namespace: capture.Namespace,
+			Labels: map[string]string{
+				"kubeslice.io/slicecapture": capture.Name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:    "collect",
+				Image:   image,
+				Command: []string{"sh", "-c", collectScript, capture.Name},
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "staging", MountPath: stagingMountPath, ReadOnly: true},
+					{Name: "pcap", MountPath: captureMountPath},
+				},
+			}},
+			Volumes: []corev1.Volume{
+				{
+					Name: "staging",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{SecretName: stagingSecretName(capture)},
+					},
+				},
+				{
+					Name: "pcap",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: capture.Spec.Storage.ClaimName},
+					},
+				},
+			},
+		},
+	}
+}
+
+// pcapSize returns the size of the pcap files of the captured pods
+func pcapSize(captures []kubeslicev1beta1.PodCapture) (int, int64) {
+	count, size := 0, int64(0)
+	for _, c := range captures {
+		if c.Completed && c.Message == "" {
+			count++
+			size += c.Size
+		}
+	}
+	return count, size
+}
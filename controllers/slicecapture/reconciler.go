@@ -0,0 +1,347 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package slicecapture
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kubeslice/kubeslice-monitoring/pkg/events"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	ossEvents "github.com/kubeslice/worker-operator/events"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
+	"github.com/kubeslice/worker-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const controllerName = "sliceCaptureReconciler"
+
+// pollInterval is the requeue interval while the captures run or the pcap files are copied
+var pollInterval = 5 * time.Second
+
+// LogReader reads the logs of a container
+type LogReader interface {
+	ReadLogs(ctx context.Context, namespace, pod, container string) ([]byte, error)
+}
+
+type podLogReader struct {
+	pods typedcorev1.PodsGetter
+}
+
+func (r *podLogReader) ReadLogs(ctx context.Context, namespace, pod, container string) ([]byte, error) {
+	return r.pods.Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{Container: container}).DoRaw(ctx)
+}
+
+// NewLogReader returns a LogReader reading the logs from the API server
+func NewLogReader(cfg *rest.Config) (LogReader, error) {
+	coreClient, err := typedcorev1.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &podLogReader{pods: coreClient}, nil
+}
+
+// Reconciler runs the packet captures of the SliceCaptures in the gateway and router pods of the slices
+type Reconciler struct {
+	client.Client
+	Log           logr.Logger
+	Scheme        *runtime.Scheme
+	Logs          LogReader
+	EventRecorder *events.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=networking.kubeslice.io,resources=slicecaptures,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=networking.kubeslice.io,resources=slicecaptures/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=core,resources=pods/ephemeralcontainers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=pods/log,verbs=get
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;delete
+
+// Reconcile moves a SliceCapture through its phases: the capture containers are added to the
+// target pods, their pcap files are staged in a secret once they exit, and copied to the PVC
+// of the capture if requested. Completed captures are left untouched.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("slicecapture", req.NamespacedName)
+	ctx = logger.WithLogger(ctx, log)
+
+	capture := &kubeslicev1beta1.SliceCapture{}
+	if err := r.Get(ctx, req.NamespacedName, capture); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get slicecapture")
+		return ctrl.Result{}, err
+	}
+
+	switch capture.Status.Phase {
+	case kubeslicev1beta1.CapturePhaseSucceeded, kubeslicev1beta1.CapturePhaseFailed:
+		return ctrl.Result{}, nil
+	case kubeslicev1beta1.CapturePhaseRunning:
+		return r.collectCaptures(ctx, capture)
+	case kubeslicev1beta1.CapturePhaseCollecting:
+		return r.reconcileCollector(ctx, capture)
+	default:
+		return r.startCapture(ctx, capture)
+	}
+}
+
+// startCapture adds the capture containers to the target pods
+func (r *Reconciler) startCapture(ctx context.Context, capture *kubeslicev1beta1.SliceCapture) (ctrl.Result, error) {
+	log := logger.FromContext(ctx)
+	if msg := validate(capture); msg != "" {
+		return ctrl.Result{}, r.fail(ctx, capture, msg)
+	}
+	image := operatorconfig.Image(operatorconfig.CaptureImageEnv)
+	if image == "" {
+		return ctrl.Result{}, r.fail(ctx, capture, operatorconfig.CaptureImageEnv+" is not set")
+	}
+	pods, err := targetPods(ctx, r.Client, capture)
+	if err != nil {
+		log.Error(err, "Failed to list the target pods")
+		return ctrl.Result{}, err
+	}
+	if len(pods) == 0 {
+		capture.Status.Phase = kubeslicev1beta1.CapturePhasePending
+		capture.Status.Message = "no running target pod in slice " + capture.Spec.SliceName
+		return ctrl.Result{RequeueAfter: operatorconfig.ReconcileInterval(controllers.ReconcileInterval)}, r.Status().Update(ctx, capture)
+	}
+
+	name := containerName(capture)
+	container := captureContainer(capture, image, podMaxSize(capture, len(pods)))
+	captures := []kubeslicev1beta1.PodCapture{}
+	for i := range pods {
+		pod := &pods[i]
+		podCapture := kubeslicev1beta1.PodCapture{Pod: pod.Name, Container: name}
+		if !hasEphemeralContainer(pod, name) {
+			pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, container)
+			if err := r.SubResource("ephemeralcontainers").Update(ctx, pod); err != nil {
+				log.Error(err, "Failed to add the capture container", "pod", pod.Name)
+				podCapture.Completed = true
+				podCapture.Message = "unable to add the capture container: " + err.Error()
+			}
+		}
+		captures = append(captures, podCapture)
+	}
+	log.Info("started packet capture", "pods", len(captures), "duration", captureDuration(capture))
+
+	now := metav1.Now()
+	capture.Status.Phase = kubeslicev1beta1.CapturePhaseRunning
+	capture.Status.StartTime = &now
+	capture.Status.Captures = captures
+	capture.Status.Message = fmt.Sprintf("capturing in %d pods", len(captures))
+	if err := r.Status().Update(ctx, capture); err != nil {
+		log.Error(err, "Failed to update slicecapture status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: captureDuration(capture)}, nil
+}
+
+// collectCaptures stages the pcap files of the capture containers that exited in the secret of the capture
+func (r *Reconciler) collectCaptures(ctx context.Context, capture *kubeslicev1beta1.SliceCapture) (ctrl.Result, error) {
+	log := logger.FromContext(ctx)
+	deadline := capture.Status.StartTime.Add(captureDuration(capture) + collectGracePeriod)
+	pcaps := map[string][]byte{}
+	pending := 0
+	for i := range capture.Status.Captures {
+		podCapture := &capture.Status.Captures[i]
+		if podCapture.Completed {
+			continue
+		}
+		pod := &corev1.Pod{}
+		err := r.Get(ctx, client.ObjectKey{Name: podCapture.Pod, Namespace: capture.Namespace}, pod)
+		if err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		var state *corev1.ContainerStateTerminated
+		if err == nil {
+			state = terminatedState(pod, podCapture.Container)
+		}
+		switch {
+		case errors.IsNotFound(err):
+			podCapture.Completed = true
+			podCapture.Message = "pod deleted during the capture"
+		case state == nil && time.Now().After(deadline):
+			podCapture.Completed = true
+			podCapture.Message = "capture did not complete in time"
+		case state == nil:
+			pending++
+		case state.ExitCode != 0:
+			podCapture.Completed = true
+			podCapture.Message = terminationMessage(state)
+		default:
+			podCapture.Completed = true
+			pcap, err := r.readPcap(ctx, pod, podCapture.Container)
+			if err != nil {
+				log.Error(err, "Failed to read the pcap", "pod", pod.Name)
+				podCapture.Message = err.Error()
+				break
+			}
+			podCapture.Size = int64(len(pcap))
+			pcaps[pcapKey(pod.Name)] = pcap
+		}
+	}
+
+	if len(pcaps) > 0 {
+		if err := r.stagePcaps(ctx, capture, pcaps); err != nil {
+			log.Error(err, "Failed to stage the pcap files")
+			return ctrl.Result{}, err
+		}
+	}
+	if pending > 0 {
+		return ctrl.Result{RequeueAfter: pollInterval}, r.Status().Update(ctx, capture)
+	}
+
+	count, size := pcapSize(capture.Status.Captures)
+	if count == 0 {
+		return ctrl.Result{}, r.fail(ctx, capture, "no pcap file was captured")
+	}
+	capture.Status.Message = fmt.Sprintf("captured %d bytes in %d of %d pods", size, count, len(capture.Status.Captures))
+	if capture.Spec.Storage.Type == kubeslicev1beta1.CaptureStoragePersistentVolumeClaim {
+		capture.Status.Phase = kubeslicev1beta1.CapturePhaseCollecting
+		return ctrl.Result{Requeue: true}, r.Status().Update(ctx, capture)
+	}
+	capture.Status.Location = "secret/" + stagingSecretName(capture)
+	return ctrl.Result{}, r.succeed(ctx, capture)
+}
+
+func (r *Reconciler) readPcap(ctx context.Context, pod *corev1.Pod, container string) ([]byte, error) {
+	logs, err := r.Logs.ReadLogs(ctx, pod.Namespace, pod.Name, container)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the logs of the capture container: %w", err)
+	}
+	return decodePcap(logs)
+}
+
+// stagePcaps adds the pcap files to the secret of the capture
+func (r *Reconciler) stagePcaps(ctx context.Context, capture *kubeslicev1beta1.SliceCapture, pcaps map[string][]byte) error {
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Name: stagingSecretName(capture), Namespace: capture.Namespace}, secret)
+	if errors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      stagingSecretName(capture),
+				Namespace: capture.Namespace,
+				Labels: map[string]string{
+					"kubeslice.io/slicecapture": capture.Name,
+				},
+			},
+			Data: pcaps,
+		}
+		if err := controllerutil.SetControllerReference(capture, secret, r.Scheme); err != nil {
+			return err
+		}
+		return r.Create(ctx, secret)
+	}
+	if err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	for key, pcap := range pcaps {
+		secret.Data[key] = pcap
+	}
+	return r.Update(ctx, secret)
+}
+
+// reconcileCollector copies the staged pcap files to the PVC of the capture with a collector pod
+func (r *Reconciler) reconcileCollector(ctx context.Context, capture *kubeslicev1beta1.SliceCapture) (ctrl.Result, error) {
+	log := logger.FromContext(ctx)
+	pod := &corev1.Pod{}
+	err := r.Get(ctx, client.ObjectKey{Name: collectorPodName(capture), Namespace: capture.Namespace}, pod)
+	if errors.IsNotFound(err) {
+		pod = collectorPod(capture, operatorconfig.Image(operatorconfig.CaptureImageEnv))
+		if err := controllerutil.SetControllerReference(capture, pod, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Info("copying the pcap files to the pvc", "claim", capture.Spec.Storage.ClaimName)
+		return ctrl.Result{RequeueAfter: pollInterval}, r.Create(ctx, pod)
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		if err := r.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: stagingSecretName(capture), Namespace: capture.Namespace}}
+		if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		capture.Status.Location = fmt.Sprintf("pvc/%s/%s", capture.Spec.Storage.ClaimName, capture.Name)
+		return ctrl.Result{}, r.succeed(ctx, capture)
+	case corev1.PodFailed:
+		// the pcap files are left in the staging secret
+		if err := r.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		capture.Status.Location = "secret/" + stagingSecretName(capture)
+		return ctrl.Result{}, r.fail(ctx, capture, "unable to copy the pcap files to pvc "+capture.Spec.Storage.ClaimName+", they are left in the secret")
+	default:
+		return ctrl.Result{RequeueAfter: pollInterval}, nil
+	}
+}
+
+func (r *Reconciler) succeed(ctx context.Context, capture *kubeslicev1beta1.SliceCapture) error {
+	now := metav1.Now()
+	capture.Status.Phase = kubeslicev1beta1.CapturePhaseSucceeded
+	capture.Status.CompletionTime = &now
+	if err := r.Status().Update(ctx, capture); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("packet capture succeeded", "location", capture.Status.Location)
+	utils.RecordEvent(ctx, r.EventRecorder, capture, nil, ossEvents.EventSliceCaptureSucceeded, controllerName)
+	return nil
+}
+
+func (r *Reconciler) fail(ctx context.Context, capture *kubeslicev1beta1.SliceCapture, msg string) error {
+	now := metav1.Now()
+	capture.Status.Phase = kubeslicev1beta1.CapturePhaseFailed
+	capture.Status.CompletionTime = &now
+	capture.Status.Message = msg
+	if err := r.Status().Update(ctx, capture); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("packet capture failed", "reason", msg)
+	utils.RecordEvent(ctx, r.EventRecorder, capture, nil, ossEvents.EventSliceCaptureFailed, controllerName)
+	return nil
+}
+
+// SetupWithManager sets up reconciler with manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kubeslicev1beta1.SliceCapture{}).
+		Owns(&corev1.Pod{}).
+		Complete(tracing.NewReconciler("slicecapture", r))
+}
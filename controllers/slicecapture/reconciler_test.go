@@ -0,0 +1,261 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package slicecapture
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	mevents "github.com/kubeslice/kubeslice-monitoring/pkg/events"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	ossEvents "github.com/kubeslice/worker-operator/events"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+type fakeLogReader map[string]string
+
+func (r fakeLogReader) ReadLogs(ctx context.Context, namespace, pod, container string) ([]byte, error) {
+	return []byte(r[pod]), nil
+}
+
+func newTestReconciler(logs fakeLogReader, objs ...client.Object) *Reconciler {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(kubeslicev1beta1.AddToScheme(scheme))
+	// the fake client only updates the status through subresources
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithStatusSubresource(&kubeslicev1beta1.SliceCapture{}, &corev1.Pod{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				if subResource == "ephemeralcontainers" {
+					return c.Update(ctx, obj)
+				}
+				return c.SubResource(subResource).Update(ctx, obj, opts...)
+			},
+		}).Build()
+	eventRecorder := mevents.NewEventRecorder(c, scheme, ossEvents.EventsMap, mevents.EventRecorderOptions{})
+	return &Reconciler{Client: c, Log: logger.NewWrappedLogger(), Scheme: scheme, Logs: logs, EventRecorder: &eventRecorder}
+}
+
+func newGatewayPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "kubeslice-system",
+			Labels: map[string]string{
+				"kubeslice.io/pod-type": "slicegateway",
+				"kubeslice.io/slice":    "red",
+				"kubeslice.io/slice-gw": "red-worker-1-worker-2",
+			},
+		},
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "kubeslice-sidecar", Image: "sidecar"}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.1.1.1"},
+	}
+}
+
+func newCapture(storage kubeslicev1beta1.CaptureStorage) *kubeslicev1beta1.SliceCapture {
+	maxSize := resource.MustParse("1Ki")
+	return &kubeslicev1beta1.SliceCapture{
+		ObjectMeta: metav1.ObjectMeta{Name: "red-capture", Namespace: "kubeslice-system", UID: "0123456789abcdef"},
+		Spec: kubeslicev1beta1.SliceCaptureSpec{
+			SliceName: "red",
+			Target:    kubeslicev1beta1.CaptureTargetGateway,
+			Gateway:   "red-worker-1-worker-2",
+			Filter:    "udp port 11194",
+			Duration:  &metav1.Duration{Duration: 30 * time.Second},
+			MaxSize:   &maxSize,
+			Storage:   storage,
+		},
+	}
+}
+
+func reconcileCapture(t *testing.T, r *Reconciler) (ctrl.Result, *kubeslicev1beta1.SliceCapture) {
+	t.Helper()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "red-capture", Namespace: "kubeslice-system"}}
+	res, err := r.Reconcile(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	capture := &kubeslicev1beta1.SliceCapture{}
+	if err := r.Get(context.Background(), req.NamespacedName, capture); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return res, capture
+}
+
+// terminateCaptureContainer marks the capture container of the pod as exited with the given code
+func terminateCaptureContainer(t *testing.T, r *Reconciler, name string, exitCode int32, message string) {
+	t.Helper()
+	pod := &corev1.Pod{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "kubeslice-system"}, pod); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{{
+		Name: "capture-01234567",
+		State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Message: message},
+		},
+	}}
+	if err := r.Status().Update(context.Background(), pod); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestReconcileCaptureToSecret(t *testing.T) {
+	t.Setenv(operatorconfig.CaptureImageEnv, "nicolaka/netshoot")
+	pcap := []byte("\xd4\xc3\xb2\xa1pcap")
+	r := newTestReconciler(fakeLogReader{"red-gw-0": base64.StdEncoding.EncodeToString(pcap) + "\n"},
+		newCapture(kubeslicev1beta1.CaptureStorage{Type: kubeslicev1beta1.CaptureStorageSecret}),
+		newGatewayPod("red-gw-0"), newGatewayPod("red-gw-1"))
+
+	res, capture := reconcileCapture(t, r)
+	if capture.Status.Phase != kubeslicev1beta1.CapturePhaseRunning || len(capture.Status.Captures) != 2 {
+		t.Fatalf("expected the capture to run in 2 pods, got %+v", capture.Status)
+	}
+	if res.RequeueAfter != 30*time.Second {
+		t.Fatalf("expected a requeue after the duration, got %s", res.RequeueAfter)
+	}
+	pod := &corev1.Pod{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "red-gw-0", Namespace: "kubeslice-system"}, pod); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pod.Spec.EphemeralContainers) != 1 {
+		t.Fatalf("expected the capture container to be added, got %+v", pod.Spec.EphemeralContainers)
+	}
+	ec := pod.Spec.EphemeralContainers[0]
+	// the pcap files of the 2 pods fit in the 1Ki cap of each pod
+	args := ec.Command[3:]
+	if ec.Name != "capture-01234567" || ec.Image != "nicolaka/netshoot" ||
+		args[0] != "30" || args[1] != "any" || args[2] != "udp port 11194" || args[3] != "1024" {
+		t.Fatalf("unexpected capture container: %+v", ec)
+	}
+
+	// a running capture is polled
+	terminateCaptureContainer(t, r, "red-gw-0", 0, "")
+	res, capture = reconcileCapture(t, r)
+	if capture.Status.Phase != kubeslicev1beta1.CapturePhaseRunning || res.RequeueAfter != pollInterval {
+		t.Fatalf("expected the capture to be polled, got %+v", capture.Status)
+	}
+
+	terminateCaptureContainer(t, r, "red-gw-1", 1, "tcpdump: syntax error")
+	_, capture = reconcileCapture(t, r)
+	if capture.Status.Phase != kubeslicev1beta1.CapturePhaseSucceeded || capture.Status.Location != "secret/red-capture-pcap" {
+		t.Fatalf("expected the capture to succeed, got %+v", capture.Status)
+	}
+	if capture.Status.Captures[0].Size != int64(len(pcap)) || capture.Status.Captures[1].Message != "capture exited with code 1: tcpdump: syntax error" {
+		t.Fatalf("unexpected pod captures: %+v", capture.Status.Captures)
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "red-capture-pcap", Namespace: "kubeslice-system"}, secret); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(secret.Data["red-gw-0.pcap"]) != string(pcap) || len(secret.Data) != 1 {
+		t.Fatalf("unexpected secret data: %v", secret.Data)
+	}
+}
+
+func TestReconcileCaptureToPVC(t *testing.T) {
+	t.Setenv(operatorconfig.CaptureImageEnv, "nicolaka/netshoot")
+	r := newTestReconciler(fakeLogReader{"red-gw-0": base64.StdEncoding.EncodeToString([]byte("pcap"))},
+		newCapture(kubeslicev1beta1.CaptureStorage{Type: kubeslicev1beta1.CaptureStoragePersistentVolumeClaim, ClaimName: "captures"}),
+		newGatewayPod("red-gw-0"))
+
+	reconcileCapture(t, r)
+	terminateCaptureContainer(t, r, "red-gw-0", 0, "")
+	_, capture := reconcileCapture(t, r)
+	if capture.Status.Phase != kubeslicev1beta1.CapturePhaseCollecting {
+		t.Fatalf("expected the pcap files to be collected, got %+v", capture.Status)
+	}
+
+	reconcileCapture(t, r)
+	collector := &corev1.Pod{}
+	key := types.NamespacedName{Name: "red-capture-collect", Namespace: "kubeslice-system"}
+	if err := r.Get(context.Background(), key, collector); err != nil {
+		t.Fatalf("expected the collector pod to be created: %v", err)
+	}
+	if collector.Spec.Volumes[0].Secret.SecretName != "red-capture-pcap" ||
+		collector.Spec.Volumes[1].PersistentVolumeClaim.ClaimName != "captures" {
+		t.Fatalf("unexpected collector volumes: %+v", collector.Spec.Volumes)
+	}
+
+	collector.Status.Phase = corev1.PodSucceeded
+	if err := r.Status().Update(context.Background(), collector); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, capture = reconcileCapture(t, r)
+	if capture.Status.Phase != kubeslicev1beta1.CapturePhaseSucceeded || capture.Status.Location != "pvc/captures/red-capture" {
+		t.Fatalf("expected the capture to succeed, got %+v", capture.Status)
+	}
+	err := r.Get(context.Background(), types.NamespacedName{Name: "red-capture-pcap", Namespace: "kubeslice-system"}, &corev1.Secret{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("expected the staging secret to be deleted, got %v", err)
+	}
+	if err := r.Get(context.Background(), key, &corev1.Pod{}); !errors.IsNotFound(err) {
+		t.Fatalf("expected the collector pod to be deleted, got %v", err)
+	}
+}
+
+func TestReconcileInvalidCapture(t *testing.T) {
+	t.Setenv(operatorconfig.CaptureImageEnv, "nicolaka/netshoot")
+	capture := newCapture(kubeslicev1beta1.CaptureStorage{Type: kubeslicev1beta1.CaptureStoragePersistentVolumeClaim})
+	r := newTestReconciler(nil, capture, newGatewayPod("red-gw-0"))
+
+	_, capture = reconcileCapture(t, r)
+	if capture.Status.Phase != kubeslicev1beta1.CapturePhaseFailed ||
+		capture.Status.Message != "claimName is required for the PersistentVolumeClaim storage" {
+		t.Fatalf("expected the capture to fail, got %+v", capture.Status)
+	}
+}
+
+func TestPodMaxSize(t *testing.T) {
+	maxSize := resource.MustParse("800Ki")
+	tests := []struct {
+		name    string
+		maxSize *resource.Quantity
+		pods    int
+		want    int64
+	}{
+		{"default", nil, 1, 512 * 1024},
+		{"set", &maxSize, 1, 800 * 1024},
+		{"capped by the secret size", &maxSize, 4, maxSecretSize / 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capture := &kubeslicev1beta1.SliceCapture{Spec: kubeslicev1beta1.SliceCaptureSpec{MaxSize: tt.maxSize}}
+			if got := podMaxSize(capture, tt.pods); got != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
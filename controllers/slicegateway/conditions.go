@@ -0,0 +1,80 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package slicegateway
+
+import (
+	"context"
+	"fmt"
+
+	gwsidecarpb "github.com/kubeslice/gateway-sidecar/pkg/sidecar/sidecarpb"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// sliceGwReadyConditions are the conditions the Ready condition of the slice gateway is derived from
+var sliceGwReadyConditions = []string{
+	kubeslicev1beta1.ConditionTunnelUp,
+	kubeslicev1beta1.ConditionRouterConnected,
+}
+
+// tunnelUpCondition returns the TunnelUp condition for the tunnel status reported by the gw pods. The
+// gateway is up as long as one of its tunnels is up.
+func tunnelUpCondition(sliceGw *kubeslicev1beta1.SliceGateway) metav1.Condition {
+	total, up := len(sliceGw.Status.GatewayPodStatus), 0
+	for _, gwPod := range sliceGw.Status.GatewayPodStatus {
+		if gwPod.TunnelStatus.Status == int32(gwsidecarpb.TunnelStatusType_GW_TUNNEL_STATE_UP) {
+			up++
+		}
+	}
+	switch {
+	case total == 0:
+		return controllers.Condition(kubeslicev1beta1.ConditionTunnelUp, false, "GatewayPodsNotReady",
+			"No gateway pod reported a tunnel status yet", sliceGw.Generation)
+	case up == 0:
+		return controllers.Condition(kubeslicev1beta1.ConditionTunnelUp, false, "TunnelsDown",
+			fmt.Sprintf("None of the %d tunnels is up", total), sliceGw.Generation)
+	case up < total:
+		return controllers.Condition(kubeslicev1beta1.ConditionTunnelUp, true, "TunnelsDegraded",
+			fmt.Sprintf("%d of %d tunnels are up", up, total), sliceGw.Generation)
+	}
+	return controllers.Condition(kubeslicev1beta1.ConditionTunnelUp, true, "TunnelsUp",
+		fmt.Sprintf("All %d tunnels are up", total), sliceGw.Generation)
+}
+
+// routerConnectedCondition returns the RouterConnected condition for the result of sending the
+// connection context to the slice router
+func routerConnectedCondition(sliceGw *kubeslicev1beta1.SliceGateway, err error, requeue bool) metav1.Condition {
+	switch {
+	case err != nil:
+		return controllers.Condition(kubeslicev1beta1.ConditionRouterConnected, false, "RouterConnectionFailed",
+			err.Error(), sliceGw.Generation)
+	case requeue:
+		return controllers.Condition(kubeslicev1beta1.ConditionRouterConnected, false, "RouterNotReady",
+			"Waiting for the slice router and the gateway tunnels", sliceGw.Generation)
+	}
+	return controllers.Condition(kubeslicev1beta1.ConditionRouterConnected, true, "ConnectionContextSent",
+		"Slice router is connected to the gateway pods", sliceGw.Generation)
+}
+
+// updateSliceGwConditions sets the TunnelUp condition and the given conditions on the slice gateway
+func (r *SliceGwReconciler) updateSliceGwConditions(ctx context.Context, sliceGw *kubeslicev1beta1.SliceGateway, conditions ...metav1.Condition) error {
+	conditions = append(conditions, tunnelUpCondition(sliceGw))
+	return controllers.SetConditions(ctx, r.Client, sliceGw, &sliceGw.Status.Conditions, sliceGwReadyConditions, conditions...)
+}
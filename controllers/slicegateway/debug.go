@@ -0,0 +1,99 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package slicegateway
+
+import (
+	"sync"
+	"time"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/debug"
+)
+
+// nodePortAllocation records why a remote node port was allocated to a gw client deployment
+type nodePortAllocation struct {
+	slice, gateway string
+
+	Deployment  string    `json:"deployment"`
+	Port        int       `json:"port"`
+	Reason      string    `json:"reason"`
+	AllocatedAt time.Time `json:"allocatedAt"`
+}
+
+// gwPodStatusSnapshot is the status last polled from the gw pods of a slice gateway. The status is only
+// written to the slice gateway when it changes, the snapshot shows what the sidecars reported last.
+type gwPodStatusSnapshot struct {
+	slice string
+
+	ObservedAt time.Time                    `json:"observedAt"`
+	Pods       []kubeslicev1beta1.GwPodInfo `json:"pods"`
+}
+
+var (
+	// nodePortAllocations maps the gw client deployments to the allocations of gwClientToRemotePortMap
+	nodePortAllocations sync.Map
+	// gwPodStatusSnapshots maps the slice gateways to the status last polled from their pods
+	gwPodStatusSnapshots sync.Map
+)
+
+// storeRemotePort maps the gw client deployment to the remote node port, recording the reason of the allocation
+func storeRemotePort(sliceGw *kubeslicev1beta1.SliceGateway, depName string, port int, reason string) {
+	gwClientToRemotePortMap.Store(depName, port)
+	nodePortAllocations.Store(depName, nodePortAllocation{
+		slice:       sliceGw.Spec.SliceName,
+		gateway:     sliceGw.Name,
+		Deployment:  depName,
+		Port:        port,
+		Reason:      reason,
+		AllocatedAt: time.Now(),
+	})
+}
+
+// deleteRemotePort removes the mapping of the gw client deployment to its remote node port
+func deleteRemotePort(depName string) (interface{}, bool) {
+	nodePortAllocations.Delete(depName)
+	return gwClientToRemotePortMap.LoadAndDelete(depName)
+}
+
+func storeGwPodStatusSnapshot(sliceGw *kubeslicev1beta1.SliceGateway, gwPodsInfo []*kubeslicev1beta1.GwPodInfo) {
+	snapshot := gwPodStatusSnapshot{
+		slice:      sliceGw.Spec.SliceName,
+		ObservedAt: time.Now(),
+	}
+	for _, gwPod := range gwPodsInfo {
+		snapshot.Pods = append(snapshot.Pods, *gwPod.DeepCopy())
+	}
+	gwPodStatusSnapshots.Store(sliceGw.Name, snapshot)
+}
+
+// DebugState returns the node port allocations and the gw pod status snapshots of the slice gateways
+func DebugState() []debug.Entry {
+	var entries []debug.Entry
+	nodePortAllocations.Range(func(key, value interface{}) bool {
+		a := value.(nodePortAllocation)
+		entries = append(entries, debug.Entry{Slice: a.slice, Gateway: a.gateway, Kind: "nodePortAllocations", State: a})
+		return true
+	})
+	gwPodStatusSnapshots.Range(func(key, value interface{}) bool {
+		s := value.(gwPodStatusSnapshot)
+		entries = append(entries, debug.Entry{Slice: s.slice, Gateway: key.(string), Kind: "gwPodStatus", State: s})
+		return true
+	})
+	return entries
+}
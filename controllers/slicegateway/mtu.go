@@ -0,0 +1,148 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package slicegateway
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/mtu"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileGatewayMTU derives the MTU of the gateway tunnels from the smaller underlay MTU of the gateway
+// nodes of both clusters and reports it in the slicegateway status. The gateway deployments are configured
+// from the status.
+func (r *SliceGwReconciler) reconcileGatewayMTU(ctx context.Context, sliceGw *kubeslicev1beta1.SliceGateway) error {
+	log := logger.FromContext(ctx)
+	local, err := mtu.Underlay(ctx, r.Client)
+	if err != nil {
+		return err
+	}
+	remote, err := r.HubClient.GetClusterUnderlayMTU(ctx, sliceGw.Name, sliceGw.Status.Config.SliceGatewayRemoteClusterID)
+	if err != nil {
+		// keep the tunnels as they are until the underlay mtu of the remote cluster is known
+		log.Error(err, "Failed to get the underlay mtu of the remote cluster")
+		if sliceGw.Status.MTU != nil {
+			return nil
+		}
+	}
+	m := mtu.Tunnel(local, remote, sliceGw.Status.Config.SliceGatewayProtocol)
+	if isServer(sliceGw) {
+		if err := r.reconcileServerConfigMTU(ctx, sliceGw, m); err != nil {
+			return err
+		}
+	}
+	desired := kubeslicev1beta1.TunnelMTU{
+		Underlay: int32(m.Underlay),
+		Overlay:  int32(m.Overlay),
+		MSSFix:   int32(m.MSSFix),
+	}
+	if sliceGw.Status.MTU != nil && *sliceGw.Status.MTU == desired {
+		return nil
+	}
+	log.Info("Updating the mtu of the slice gateway tunnels", "underlay", m.Underlay, "overlay", m.Overlay)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(sliceGw), sliceGw); err != nil {
+			return err
+		}
+		sliceGw.Status.MTU = &desired
+		return r.Status().Update(ctx, sliceGw)
+	})
+}
+
+// reconcileServerConfigMTU writes the MTU to the openvpn server config in the secrets of the gateway. The
+// server is started by a script of the openvpn image that does not take extra options.
+func (r *SliceGwReconciler) reconcileServerConfigMTU(ctx context.Context, sliceGw *kubeslicev1beta1.SliceGateway, m mtu.MTU) error {
+	secrets := &corev1.SecretList{}
+	err := r.List(ctx, secrets, client.InNamespace(sliceGw.Namespace), client.MatchingLabels{"kubeslice.io/slice-gw": sliceGw.Name})
+	if err != nil {
+		return err
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		config, ok := secret.Data["ovpnConfigFile"]
+		if !ok {
+			continue
+		}
+		updated := mtu.OpenVPNConfig(string(config), m)
+		if updated == string(config) {
+			continue
+		}
+		secret.Data["ovpnConfigFile"] = []byte(updated)
+		if err := r.Update(ctx, secret); err != nil {
+			return err
+		}
+		logger.FromContext(ctx).Info("Updated the mtu of the openvpn server config", "secret", secret.Name, "overlay", m.Overlay)
+	}
+	return nil
+}
+
+// gatewayMTU returns the MTU reported in the slicegateway status
+func gatewayMTU(sliceGw *kubeslicev1beta1.SliceGateway) (mtu.MTU, bool) {
+	if sliceGw.Status.MTU == nil {
+		return mtu.MTU{}, false
+	}
+	return mtu.MTU{
+		Underlay: int(sliceGw.Status.MTU.Underlay),
+		Overlay:  int(sliceGw.Status.MTU.Overlay),
+		MSSFix:   int(sliceGw.Status.MTU.MSSFix),
+	}, true
+}
+
+// setGatewayMTU configures the openvpn containers of a gateway pod with the MTU of the slicegateway.
+// The client gets the MTU in its args, the server reads it from its config and the pod is annotated with
+// it to restart when it changes. It returns true if the pod template changed.
+func setGatewayMTU(template *corev1.PodTemplateSpec, sliceGw *kubeslicev1beta1.SliceGateway) bool {
+	m, ok := gatewayMTU(sliceGw)
+	if !ok {
+		return false
+	}
+	changed := false
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		var args []string
+		switch container.Name {
+		case "kubeslice-openvpn-server":
+			args = mtu.OpenVPNServerArgs(container.Args)
+			value := fmt.Sprintf("%d/%d", m.Overlay, m.MSSFix)
+			if template.Annotations[mtu.TunnelAnnotation] != value {
+				if template.Annotations == nil {
+					template.Annotations = map[string]string{}
+				}
+				template.Annotations[mtu.TunnelAnnotation] = value
+				changed = true
+			}
+		case "kubeslice-openvpn-client":
+			args = mtu.OpenVPNArgs(container.Args, m)
+		default:
+			continue
+		}
+		if !reflect.DeepEqual(args, container.Args) {
+			container.Args = args
+			changed = true
+		}
+	}
+	return changed
+}
@@ -45,10 +45,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/kubeslice/kubeslice-monitoring/pkg/metrics"
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
 	"github.com/kubeslice/worker-operator/controllers"
 	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/mtu"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
 	nsmv1 "github.com/networkservicemesh/sdk-k8s/pkg/tools/k8s/apis/networkservicemesh.io/v1"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var sliceGwFinalizer = "networking.kubeslice.io/slicegw-finalizer"
@@ -69,6 +74,10 @@ type SliceGwReconciler struct {
 	EventRecorder    *events.EventRecorder
 	NodeIPs          []string
 	NumberOfGateways int
+
+	gaugeTxRate        *prometheus.GaugeVec
+	gaugeRxRate        *prometheus.GaugeVec
+	counterCeilingHits *prometheus.CounterVec
 }
 
 //+kubebuilder:rbac:groups=networking.kubeslice.io,resources=slicegateways,verbs=get;list;watch;create;update;patch;delete
@@ -137,6 +146,41 @@ func (r *SliceGwReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
+	// the RouterConnected condition is only changed once the connection context was sent to the router
+	var routerConnected []metav1.Condition
+	defer func() {
+		if err := r.updateSliceGwConditions(ctx, sliceGw, routerConnected...); err != nil {
+			log.Error(err, "Failed to update slicegateway conditions")
+		}
+	}()
+
+	overlaps, err := subnetOverlaps(ctx, r.Client, slice, sliceGw)
+	if err != nil {
+		log.Error(err, "Failed to validate the slice subnets against the cluster CIDRs")
+		return ctrl.Result{}, err
+	}
+	if len(overlaps) > 0 {
+		log.Info("Slice subnets overlap with the cluster CIDRs, not deploying the slicegateway", "overlaps", overlaps)
+		return ctrl.Result{
+			RequeueAfter: operatorconfig.ReconcileInterval(controllers.ReconcileInterval),
+		}, nil
+	}
+
+	if controllers.SliceInMaintenance(slice) {
+		log.Info("Slice is in maintenance, only collecting the slicegateway status")
+		res, err, requeue := r.ReconcileGwPodStatus(ctx, sliceGw)
+		if err != nil {
+			log.Error(err, "Failed to reconcile slice gw pod status")
+			return ctrl.Result{}, err
+		}
+		if requeue {
+			return res, nil
+		}
+		return ctrl.Result{
+			RequeueAfter: operatorconfig.SliceGatewayReconcileInterval(controllers.SliceGatewayReconcileInterval),
+		}, nil
+	}
+
 	// Check if slice router network service endpoint (NSE) is present before spawning slice gateway pod.
 	// Gateways connect to vL3 slice router at startup, hence it is necessary to check if the
 	// NSE present before creating the gateway pods.
@@ -147,11 +191,18 @@ func (r *SliceGwReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			return ctrl.Result{}, err
 		}
 		log.Info("No endpoints found for vL3 NSE yet. Waiting...")
+		routerConnected = append(routerConnected, controllers.Condition(kubeslicev1beta1.ConditionRouterConnected, false,
+			"RouterServiceNotFound", "No endpoints found for the slice router network service", sliceGw.Generation))
 		return ctrl.Result{
 			RequeueAfter: 10 * time.Second,
 		}, nil
 	}
 
+	if err := r.reconcileGatewayMTU(ctx, sliceGw); err != nil {
+		log.Error(err, "Failed to update the mtu of the slice gateway tunnels")
+		return ctrl.Result{}, err
+	}
+
 	if isServer(sliceGw) {
 		res, err, requeue := r.ReconcileGatewayDeployments(ctx, sliceGw)
 		if err != nil {
@@ -250,6 +301,7 @@ func (r *SliceGwReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	res, err, requeue = r.SendConnectionContextToSliceRouter(ctx, sliceGw)
+	routerConnected = append(routerConnected, routerConnectedCondition(sliceGw, err, requeue))
 	if err != nil {
 		log.Error(err, "Failed to send connection context to slice router pod")
 		//post event to slicegw
@@ -281,7 +333,7 @@ func (r *SliceGwReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	return ctrl.Result{
-		RequeueAfter: controllers.SliceGatewayReconcileInterval,
+		RequeueAfter: operatorconfig.SliceGatewayReconcileInterval(controllers.SliceGatewayReconcileInterval),
 	}, nil
 }
 
@@ -342,6 +394,7 @@ func (r *SliceGwReconciler) handleSliceGwDeletion(sliceGw *kubeslicev1beta1.Slic
 			if err != nil {
 				return true, ctrl.Result{}, err
 			}
+			r.forgetBandwidthUsage(sliceGw)
 
 		}
 		utils.RecordEvent(ctx, r.EventRecorder, sliceGw, nil, ossEvents.EventSliceGWDeleted, controllerName)
@@ -475,6 +528,17 @@ func (r *SliceGwReconciler) findObjectsForNsmUpdate() (*kubeslicev1beta1.SliceGa
 	return r.findAllSliceGwObjects()
 }
 
+// Setup initializes the bandwidth usage metrics and sets up the controller with the Manager
+func (r *SliceGwReconciler) Setup(mgr ctrl.Manager, mf metrics.MetricsFactory) error {
+	r.gaugeTxRate = mf.NewGauge("slice_gateway_tx_rate_kbps", "Transmit rate of the gateway tunnels of a slice to a remote cluster", []string{"slice", "slice_remote_cluster"})
+	r.gaugeRxRate = mf.NewGauge("slice_gateway_rx_rate_kbps", "Receive rate of the gateway tunnels of a slice from a remote cluster", []string{"slice", "slice_remote_cluster"})
+	r.counterCeilingHits = mf.NewCounter("slice_bandwidth_ceiling_hits_total", "Samples where the transmit rate of the gateway tunnels reached the bandwidth ceiling of the slice", []string{"slice", "slice_remote_cluster"})
+	if err := mgr.Add(&bandwidthSampler{r: r}); err != nil {
+		return err
+	}
+	return r.SetupWithManager(mgr)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *SliceGwReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	var labelSelector metav1.LabelSelector
@@ -559,6 +623,10 @@ func (r *SliceGwReconciler) SetupWithManager(mgr ctrl.Manager) error {
 						// The reconcile would be triggered by the Create func
 						return false
 					}
+					// the mtu of the gateway tunnels follows the underlay mtu of the gateway nodes
+					if oldObj.Annotations[mtu.UnderlayAnnotation] != newObj.Annotations[mtu.UnderlayAnnotation] {
+						return true
+					}
 					if oldObj.ObjectMeta.Labels != nil {
 						nodelabel, ok := oldObj.ObjectMeta.Labels[controllers.NodeTypeSelectorLabelKey]
 						if !ok {
@@ -576,7 +644,7 @@ func (r *SliceGwReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				},
 			}),
 		).
-		Complete(r)
+		Complete(tracing.NewReconciler("slicegateway", r))
 }
 
 func (r *SliceGwReconciler) FindSliceRouterService(ctx context.Context, c client.Client, sliceName string) (bool, error) {
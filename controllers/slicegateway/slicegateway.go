@@ -48,6 +48,8 @@ import (
 	"github.com/kubeslice/worker-operator/pkg/cluster"
 	"github.com/kubeslice/worker-operator/pkg/gwsidecar"
 	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	"github.com/kubeslice/worker-operator/pkg/qos"
 	"github.com/kubeslice/worker-operator/pkg/router"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 	webhook "github.com/kubeslice/worker-operator/pkg/webhook/pod"
@@ -60,11 +62,8 @@ const (
 
 var (
 	vpnClientFileName        = "openvpn_client.ovpn"
-	gwSidecarImage           = os.Getenv("AVESHA_GW_SIDECAR_IMAGE")
 	gwSidecarImagePullPolicy = os.Getenv("AVESHA_GW_SIDECAR_IMAGE_PULLPOLICY")
 
-	openVpnServerImage      = os.Getenv("AVESHA_OPENVPN_SERVER_IMAGE")
-	openVpnClientImage      = os.Getenv("AVESHA_OPENVPN_CLIENT_IMAGE")
 	openVpnServerPullPolicy = os.Getenv("AVESHA_OPENVPN_SERVER_PULLPOLICY")
 	openVpnClientPullPolicy = os.Getenv("AVESHA_OPENVPN_CLIENT_PULLPOLICY")
 )
@@ -98,11 +97,40 @@ func labelsForSliceGwStatus(name string) map[string]string {
 
 // deploymentForGateway returns a gateway Deployment object
 func (r *SliceGwReconciler) deploymentForGateway(g *kubeslicev1beta1.SliceGateway, depName string, gwConfigKey int) *appsv1.Deployment {
+	var dep *appsv1.Deployment
 	if g.Status.Config.SliceGatewayHostType == "Server" {
-		return r.deploymentForGatewayServer(g, depName, gwConfigKey)
+		dep = r.deploymentForGatewayServer(g, depName, gwConfigKey)
 	} else {
-		return r.deploymentForGatewayClient(g, depName, gwConfigKey)
+		dep = r.deploymentForGatewayClient(g, depName, gwConfigKey)
 	}
+	setGatewayMTU(&dep.Spec.Template, g)
+	return dep
+}
+
+// getOpenVPNServerImageAndPullPolicy returns the image of the openvpn container of the gateway servers
+func getOpenVPNServerImageAndPullPolicy() (string, corev1.PullPolicy) {
+	vpnImg := "nexus.dev.aveshalabs.io/kubeslice/openvpn-server.ubuntu.18.04:1.0.0"
+	vpnPullPolicy := corev1.PullAlways
+	if img := operatorconfig.Image(operatorconfig.OpenVPNServerImageEnv); len(img) != 0 {
+		vpnImg = img
+	}
+	if len(openVpnServerPullPolicy) != 0 {
+		vpnPullPolicy = corev1.PullPolicy(openVpnServerPullPolicy)
+	}
+	return vpnImg, vpnPullPolicy
+}
+
+// getOpenVPNClientImageAndPullPolicy returns the image of the openvpn container of the gateway clients
+func getOpenVPNClientImageAndPullPolicy() (string, corev1.PullPolicy) {
+	vpnImg := "nexus.dev.aveshalabs.io/kubeslice/openvpn-client.alpine.amd64:1.0.0"
+	vpnPullPolicy := corev1.PullAlways
+	if img := operatorconfig.Image(operatorconfig.OpenVPNClientImageEnv); len(img) != 0 {
+		vpnImg = img
+	}
+	if len(openVpnClientPullPolicy) != 0 {
+		vpnPullPolicy = corev1.PullPolicy(openVpnClientPullPolicy)
+	}
+	return vpnImg, vpnPullPolicy
 }
 
 func (r *SliceGwReconciler) deploymentForGatewayServer(g *kubeslicev1beta1.SliceGateway, depName string, gwConfigKey int) *appsv1.Deployment {
@@ -117,26 +145,17 @@ func (r *SliceGwReconciler) deploymentForGatewayServer(g *kubeslicev1beta1.Slice
 
 	sidecarImg := DEFAULT_SIDECAR_IMG
 	sidecarPullPolicy := DEFAULT_SIDECAR_PULLPOLICY
-	vpnImg := "nexus.dev.aveshalabs.io/kubeslice/openvpn-server.ubuntu.18.04:1.0.0"
-	vpnPullPolicy := corev1.PullAlways
+	vpnImg, vpnPullPolicy := getOpenVPNServerImageAndPullPolicy()
 	baseFileName := os.Getenv("CLUSTER_NAME") + "-" + g.Spec.SliceName + "-" + g.Status.Config.SliceGatewayName + ".vpn.aveshasystems.com"
 
-	if len(gwSidecarImage) != 0 {
-		sidecarImg = gwSidecarImage
+	if img := operatorconfig.Image(operatorconfig.GatewaySidecarImageEnv); len(img) != 0 {
+		sidecarImg = img
 	}
 
 	if len(gwSidecarImagePullPolicy) != 0 {
 		sidecarPullPolicy = corev1.PullPolicy(gwSidecarImagePullPolicy)
 	}
 
-	if len(openVpnServerImage) != 0 {
-		vpnImg = openVpnServerImage
-	}
-
-	if len(openVpnServerPullPolicy) != 0 {
-		vpnPullPolicy = corev1.PullPolicy(openVpnServerPullPolicy)
-	}
-
 	nsmAnnotation := fmt.Sprintf("kernel://vl3-service-%s/nsm0", g.Spec.SliceName)
 	selectedNodeIP := ""
 	nodeIps := cluster.GetNodeExternalIpList()
@@ -398,27 +417,18 @@ func (r *SliceGwReconciler) deploymentForGatewayClient(g *kubeslicev1beta1.Slice
 	sidecarImg := "nexus.dev.aveshalabs.io/kubeslice/gw-sidecar:1.0.0"
 	sidecarPullPolicy := corev1.PullAlways
 
-	vpnImg := "nexus.dev.aveshalabs.io/kubeslice/openvpn-client.alpine.amd64:1.0.0"
-	vpnPullPolicy := corev1.PullAlways
+	vpnImg, vpnPullPolicy := getOpenVPNClientImageAndPullPolicy()
 
 	ls := labelsForSliceGwDeployment(g.Name, g.Spec.SliceName, depName)
 
-	if len(gwSidecarImage) != 0 {
-		sidecarImg = gwSidecarImage
+	if img := operatorconfig.Image(operatorconfig.GatewaySidecarImageEnv); len(img) != 0 {
+		sidecarImg = img
 	}
 
 	if len(gwSidecarImagePullPolicy) != 0 {
 		sidecarPullPolicy = corev1.PullPolicy(gwSidecarImagePullPolicy)
 	}
 
-	if len(openVpnClientImage) != 0 {
-		vpnImg = openVpnClientImage
-	}
-
-	if len(openVpnClientPullPolicy) != 0 {
-		vpnPullPolicy = corev1.PullPolicy(openVpnClientPullPolicy)
-	}
-
 	nsmAnnotation := fmt.Sprintf("kernel://vl3-service-%s/nsm0", g.Spec.SliceName)
 
 	remotePortNumber := 0
@@ -428,7 +438,7 @@ func (r *SliceGwReconciler) deploymentForGatewayClient(g *kubeslicev1beta1.Slice
 		// Check if cache is valid
 		if !checkIfNodePortIsValid(g.Status.Config.SliceGatewayRemoteNodePorts, remotePortNumber) {
 			log.Info("Port number mapping is invalid", "depName", depName, "port", remotePortNumber)
-			gwClientToRemotePortMap.Delete(depName)
+			deleteRemotePort(depName)
 			remotePortNumber = 0
 		}
 	}
@@ -436,7 +446,7 @@ func (r *SliceGwReconciler) deploymentForGatewayClient(g *kubeslicev1beta1.Slice
 	if !found || remotePortNumber == 0 {
 		for _, nodePort := range g.Status.Config.SliceGatewayRemoteNodePorts {
 			if !checkIfNodePortIsAlreadyUsed(nodePort) {
-				gwClientToRemotePortMap.Store(depName, nodePort)
+				storeRemotePort(g, depName, nodePort, "first remote node port not used by another gw client")
 				log.Info("Storing in map", "depName", depName, "port", nodePort)
 				break
 			}
@@ -707,6 +717,7 @@ func (r *SliceGwReconciler) ReconcileGwPodStatus(ctx context.Context, slicegatew
 			toUpdate = true
 		}
 	}
+	storeGwPodStatusSnapshot(slicegateway, gwPodsInfo)
 	if len(slicegateway.Status.GatewayPodStatus) != len(gwPodsInfo) {
 		toUpdate = true
 	}
@@ -856,6 +867,11 @@ func (r *SliceGwReconciler) SyncNetOpConnectionContextAndQos(ctx context.Context
 		err = r.WorkerNetOpClient.UpdateSliceQosProfile(ctx, sidecarGrpcAddress, slice)
 		if err != nil {
 			log.Error(err, "Failed to send qos to netop. PodIp: %v, PodName: %v", n.PodIP, n.PodName)
+			// a profile the netop cannot apply must not hold back the connection context of the
+			// other nodes, it is reported in the QosApplied condition of the slice
+			if qos.NotApplicable(err) {
+				continue
+			}
 			return err
 		}
 	}
@@ -1425,13 +1441,19 @@ func (r *SliceGwReconciler) ReconcileGatewayDeployments(ctx context.Context, sli
 	}
 
 	sidecarImg := DEFAULT_SIDECAR_IMG
-	if len(gwSidecarImage) != 0 {
-		sidecarImg = gwSidecarImage
+	if img := operatorconfig.Image(operatorconfig.GatewaySidecarImageEnv); len(img) != 0 {
+		sidecarImg = img
 	}
 	sidecarPullPolicy := DEFAULT_SIDECAR_PULLPOLICY
 	if len(gwSidecarImagePullPolicy) != 0 {
 		sidecarPullPolicy = corev1.PullPolicy(gwSidecarImagePullPolicy)
 	}
+	vpnContainerName := "kubeslice-openvpn-server"
+	vpnImg, vpnPullPolicy := getOpenVPNServerImageAndPullPolicy()
+	if isClient(sliceGw) {
+		vpnContainerName = "kubeslice-openvpn-client"
+		vpnImg, vpnPullPolicy = getOpenVPNClientImageAndPullPolicy()
+	}
 
 	for gwInstance := 0; gwInstance < numGwInstances; gwInstance++ {
 		if !gwDeploymentIsPresent(sliceGwName, gwInstance, deployments) {
@@ -1453,7 +1475,19 @@ func (r *SliceGwReconciler) ReconcileGatewayDeployments(ctx context.Context, sli
 					if container.Name == "kubeslice-sidecar" && (container.Image != sidecarImg || container.ImagePullPolicy != sidecarPullPolicy) {
 						container.Image = sidecarImg
 						container.ImagePullPolicy = sidecarPullPolicy
-						log.Info("updating gw Deployment sidecar", "Name", deployment.Name, "image", gwSidecarImage)
+						log.Info("updating gw Deployment sidecar", "Name", deployment.Name, "image", sidecarImg)
+						err = r.Update(ctx, deployment)
+						if err != nil {
+							log.Error(err, "Failed to update Deployment", "Name", deployment.Name)
+							return ctrl.Result{}, err, true
+						}
+						return ctrl.Result{Requeue: true}, nil, true
+					}
+					// update if the openvpn image has been changed in the operator config or worker env vars
+					if container.Name == vpnContainerName && (container.Image != vpnImg || container.ImagePullPolicy != vpnPullPolicy) {
+						container.Image = vpnImg
+						container.ImagePullPolicy = vpnPullPolicy
+						log.Info("updating gw Deployment openvpn", "Name", deployment.Name, "image", vpnImg)
 						err = r.Update(ctx, deployment)
 						if err != nil {
 							log.Error(err, "Failed to update Deployment", "Name", deployment.Name)
@@ -1462,6 +1496,16 @@ func (r *SliceGwReconciler) ReconcileGatewayDeployments(ctx context.Context, sli
 						return ctrl.Result{Requeue: true}, nil, true
 					}
 				}
+				// update if the mtu of the tunnels changed
+				if setGatewayMTU(&deployment.Spec.Template, sliceGw) {
+					log.Info("updating gw Deployment mtu", "Name", deployment.Name, "mtu", sliceGw.Status.MTU.Overlay)
+					err = r.Update(ctx, deployment)
+					if err != nil {
+						log.Error(err, "Failed to update Deployment", "Name", deployment.Name)
+						return ctrl.Result{}, err, true
+					}
+					return ctrl.Result{Requeue: true}, nil, true
+				}
 
 			}
 		}
@@ -1493,7 +1537,8 @@ func (r *SliceGwReconciler) ReconcileGatewayDeployments(ctx context.Context, sli
 						return ctrl.Result{}, err, true
 					}
 					// Update the port map
-					gwClientToRemotePortMap.Store(deployment.Name, portNumToUpdate)
+					storeRemotePort(sliceGw, deployment.Name, portNumToUpdate,
+						fmt.Sprintf("port %d in use is not a remote node port of the slice gateway", nodePortInUse))
 					err = r.updateGatewayDeploymentNodePort(ctx, sliceGw, &deployment, portNumToUpdate)
 					if err != nil {
 						return ctrl.Result{}, err, true
@@ -1516,7 +1561,7 @@ func (r *SliceGwReconciler) ReconcileGatewayDeployments(ctx context.Context, sli
 						return ctrl.Result{}, nil, true
 					}
 				} else {
-					gwClientToRemotePortMap.Store(deployment.Name, nodePortInUse)
+					storeRemotePort(sliceGw, deployment.Name, nodePortInUse, "port in use by the deployment when the operator started")
 				}
 			}
 		}
@@ -1546,7 +1591,7 @@ func (r *SliceGwReconciler) ReconcileGatewayDeployments(ctx context.Context, sli
 			}
 
 			if isClient(sliceGw) {
-				value, loaded := gwClientToRemotePortMap.LoadAndDelete(depToDelete.Name)
+				value, loaded := deleteRemotePort(depToDelete.Name)
 				if loaded {
 					log.Info("Deleted port num map for gw deployment", "depName", depToDelete.Name, "port", value.(int))
 				}
@@ -40,6 +40,7 @@ type NetOpPod struct {
 type HubClientProvider interface {
 	UpdateNodePortForSliceGwServer(ctx context.Context, sliceGwNodePort []int, sliceGwName string) error
 	GetClusterNodeIP(ctx context.Context, clusterName, namespace string) ([]string, error)
+	GetClusterUnderlayMTU(ctx context.Context, sliceGwName, clusterName string) (int, error)
 	CreateWorkerSliceGwRecycler(ctx context.Context, gwRecyclerName, clientID, serverID, sliceGwServer, sliceGwClient, slice string) error
 	GetVPNKeyRotation(ctx context.Context, rotationName string) (*hubv1alpha1.VpnKeyRotation, error)
 	ListWorkerSliceGwRecycler(ctx context.Context, sliceGWName string) ([]spokev1alpha1.WorkerSliceGwRecycler, error)
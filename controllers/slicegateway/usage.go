@@ -0,0 +1,213 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package slicegateway
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// BandwidthUsageWindow is the window the peak rates and ceiling hits of a slice gateway are counted in, eg: 1h
+	BandwidthUsageWindow = utils.GetEnvOrDefault("BANDWIDTH_USAGE_WINDOW", "1h")
+	// BandwidthUsageReportInterval is how often the bandwidth usage is written to the slice gateway status, eg: 1m
+	BandwidthUsageReportInterval = utils.GetEnvOrDefault("BANDWIDTH_USAGE_REPORT_INTERVAL", "1m")
+	// BandwidthUsageSampleInterval is how often the tunnel rates of the slice gateways are sampled, eg: 10s
+	BandwidthUsageSampleInterval = utils.GetEnvOrDefault("BANDWIDTH_USAGE_SAMPLE_INTERVAL", "10s")
+)
+
+const (
+	defaultBandwidthUsageWindow         = time.Hour
+	defaultBandwidthUsageReportInterval = time.Minute
+	defaultBandwidthUsageSampleInterval = 10 * time.Second
+	// the transmit rate counts as a ceiling hit above this share of the bandwidth ceiling
+	ceilingHitRatio = 0.95
+)
+
+// bandwidthTracker accumulates the samples of a slice gateway between two reports
+type bandwidthTracker struct {
+	mu         sync.Mutex
+	usage      kubeslicev1beta1.BandwidthUsage
+	lastReport time.Time
+}
+
+// bandwidthTrackers maps the slice gateways to their bandwidth trackers
+var bandwidthTrackers sync.Map
+
+func durationOrDefault(value string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// observeBandwidthUsage adds a sample of the tunnel rates to the usage and returns whether the transmit
+// rate reached the ceiling. The peak rates and ceiling hits are reset when the window is over.
+func observeBandwidthUsage(usage *kubeslicev1beta1.BandwidthUsage, txKbps, rxKbps, ceilingKbps uint64, window time.Duration, now time.Time) bool {
+	if usage.WindowStart.IsZero() || now.Sub(usage.WindowStart.Time) >= window {
+		*usage = kubeslicev1beta1.BandwidthUsage{WindowStart: metav1.NewTime(now)}
+	}
+	usage.TxRateKbps, usage.RxRateKbps, usage.CeilingKbps = txKbps, rxKbps, ceilingKbps
+	usage.LastUpdated = metav1.NewTime(now)
+	usage.Samples++
+	if txKbps > usage.PeakTxRateKbps {
+		usage.PeakTxRateKbps = txKbps
+	}
+	if rxKbps > usage.PeakRxRateKbps {
+		usage.PeakRxRateKbps = rxKbps
+	}
+	hit := ceilingKbps > 0 && float64(txKbps) >= ceilingHitRatio*float64(ceilingKbps)
+	if hit {
+		usage.CeilingHits++
+	}
+	return hit
+}
+
+// bandwidthSampler samples the tunnel rates of the slice gateways every sample interval, so that the peak
+// rates and ceiling hits do not depend on how often the slice gateways are reconciled. It is run by the
+// elected replica only.
+type bandwidthSampler struct {
+	r *SliceGwReconciler
+}
+
+// Start samples the slice gateways every sample interval until the context is done
+func (s *bandwidthSampler) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, s.sample, durationOrDefault(BandwidthUsageSampleInterval, defaultBandwidthUsageSampleInterval))
+	return nil
+}
+
+// NeedLeaderElection runs the sampler on the elected replica only
+func (s *bandwidthSampler) NeedLeaderElection() bool {
+	return true
+}
+
+func (s *bandwidthSampler) sample(ctx context.Context) {
+	log := logger.FromContext(ctx).WithValues("type", "SliceGw")
+	sliceGws := &kubeslicev1beta1.SliceGatewayList{}
+	if err := s.r.List(ctx, sliceGws, client.InNamespace(controllers.ControlPlaneNamespace)); err != nil {
+		log.Error(err, "Failed to list slice gateways to sample the bandwidth usage")
+		return
+	}
+	for i := range sliceGws.Items {
+		sliceGw := &sliceGws.Items[i]
+		if !sliceGw.DeletionTimestamp.IsZero() {
+			continue
+		}
+		txKbps, rxKbps, err := s.r.getTunnelRates(ctx, sliceGw)
+		if err != nil {
+			log.Error(err, "Failed to sample the tunnel rates of the slice gateway", "sliceGw", sliceGw.Name)
+			continue
+		}
+		s.r.recordBandwidthUsage(ctx, sliceGw, txKbps, rxKbps)
+	}
+}
+
+// getTunnelRates returns the rates of the tunnels of the gw pods in kbps. The tunnels of the gw pods carry
+// different flows, their rates are added up. The gw sidecars compute the rates from the bytes counted on
+// the tunnel interface per millisecond, times 8, that is in kbps. The netops expose no traffic counters,
+// so the rates are those of the tunnels only.
+func (r *SliceGwReconciler) getTunnelRates(ctx context.Context, sliceGw *kubeslicev1beta1.SliceGateway) (uint64, uint64, error) {
+	gwPodsInfo, err := r.GetGwPodInfo(ctx, sliceGw)
+	if err != nil {
+		return 0, 0, err
+	}
+	var txKbps, rxKbps uint64
+	for _, gwPod := range gwPodsInfo {
+		status, err := r.WorkerGWSidecarClient.GetStatus(ctx, gwPod.PodIP+":5000")
+		if err != nil {
+			return 0, 0, err
+		}
+		txKbps += status.TunnelStatus.TxRate
+		rxKbps += status.TunnelStatus.RxRate
+	}
+	return txKbps, rxKbps, nil
+}
+
+// recordBandwidthUsage adds a sample of the tunnel rates, exports them as metrics and writes the usage to
+// the slice gateway status every report interval
+func (r *SliceGwReconciler) recordBandwidthUsage(ctx context.Context, sliceGw *kubeslicev1beta1.SliceGateway, txKbps, rxKbps uint64) {
+	log := logger.FromContext(ctx).WithValues("type", "SliceGw")
+
+	var ceilingKbps uint64
+	slice, err := controllers.GetSlice(ctx, r.Client, sliceGw.Spec.SliceName)
+	if err == nil && slice.Status.SliceConfig != nil && slice.Status.SliceConfig.QosProfileDetails.BandwidthCeilingKbps > 0 {
+		ceilingKbps = uint64(slice.Status.SliceConfig.QosProfileDetails.BandwidthCeilingKbps)
+	}
+
+	value, loaded := bandwidthTrackers.LoadOrStore(sliceGw.Name, &bandwidthTracker{})
+	tracker := value.(*bandwidthTracker)
+	tracker.mu.Lock()
+	if !loaded && sliceGw.Status.BandwidthUsage != nil {
+		// continue the window reported before a restart of the operator
+		tracker.usage = *sliceGw.Status.BandwidthUsage.DeepCopy()
+	}
+	now := time.Now()
+	hit := observeBandwidthUsage(&tracker.usage, txKbps, rxKbps, ceilingKbps, durationOrDefault(BandwidthUsageWindow, defaultBandwidthUsageWindow), now)
+	report := now.Sub(tracker.lastReport) >= durationOrDefault(BandwidthUsageReportInterval, defaultBandwidthUsageReportInterval)
+	usage := tracker.usage.DeepCopy()
+	if report {
+		tracker.lastReport = now
+	}
+	tracker.mu.Unlock()
+
+	remoteCluster := sliceGw.Status.Config.SliceGatewayRemoteClusterID
+	if r.gaugeTxRate != nil {
+		r.gaugeTxRate.WithLabelValues(sliceGw.Spec.SliceName, remoteCluster).Set(float64(txKbps))
+		r.gaugeRxRate.WithLabelValues(sliceGw.Spec.SliceName, remoteCluster).Set(float64(rxKbps))
+		if hit {
+			r.counterCeilingHits.WithLabelValues(sliceGw.Spec.SliceName, remoteCluster).Inc()
+		}
+	}
+	if !report {
+		return
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(sliceGw), sliceGw); err != nil {
+			return err
+		}
+		sliceGw.Status.BandwidthUsage = usage
+		return r.Status().Update(ctx, sliceGw)
+	})
+	if err != nil {
+		log.Error(err, "Failed to update the bandwidth usage of the slice gateway")
+	}
+}
+
+// forgetBandwidthUsage drops the tracker and the metrics of a deleted slice gateway
+func (r *SliceGwReconciler) forgetBandwidthUsage(sliceGw *kubeslicev1beta1.SliceGateway) {
+	bandwidthTrackers.Delete(sliceGw.Name)
+	if r.gaugeTxRate != nil {
+		remoteCluster := sliceGw.Status.Config.SliceGatewayRemoteClusterID
+		r.gaugeTxRate.DeleteLabelValues(sliceGw.Spec.SliceName, remoteCluster)
+		r.gaugeRxRate.DeleteLabelValues(sliceGw.Spec.SliceName, remoteCluster)
+		r.counterCeilingHits.DeleteLabelValues(sliceGw.Spec.SliceName, remoteCluster)
+	}
+}
@@ -31,6 +31,8 @@ import (
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
 	"github.com/kubeslice/worker-operator/controllers"
 	ossEvents "github.com/kubeslice/worker-operator/events"
+	"github.com/kubeslice/worker-operator/pkg/cluster"
+	"github.com/kubeslice/worker-operator/pkg/mtu"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 	webhook "github.com/kubeslice/worker-operator/pkg/webhook/pod"
 
@@ -50,6 +52,19 @@ func isServer(sliceGw *kubeslicev1beta1.SliceGateway) bool {
 func canDeployGw(sliceGw *kubeslicev1beta1.SliceGateway) bool {
 	return sliceGw.Status.Config.SliceGatewayHostType == "Server" || readyToDeployGwClient(sliceGw)
 }
+
+// subnetOverlaps returns the subnets of the slice that overlap with the cluster CIDRs. The subnets of the
+// slicegateway are checked as well since it can arrive from the hub before the slice is validated again.
+func subnetOverlaps(ctx context.Context, c client.Client, slice *kubeslicev1beta1.Slice, sliceGw *kubeslicev1beta1.SliceGateway) ([]kubeslicev1beta1.SubnetOverlap, error) {
+	if len(slice.Status.SubnetOverlaps) > 0 {
+		return slice.Status.SubnetOverlaps, nil
+	}
+	return cluster.CheckSubnetOverlaps(ctx, c, map[string]string{
+		"sliceGatewaySubnet/" + sliceGw.Name:       sliceGw.Status.Config.SliceGatewaySubnet,
+		"sliceGatewayRemoteSubnet/" + sliceGw.Name: sliceGw.Status.Config.SliceGatewayRemoteSubnet,
+	})
+}
+
 func readyToDeployGwClient(sliceGw *kubeslicev1beta1.SliceGateway) bool {
 	if sliceGw.Status.Config.SliceGatewayConnectivityType == "LoadBalancer" || os.Getenv("ENABLE_GW_LB_EDGE") != "" {
 		return len(sliceGw.Status.Config.SliceGatewayServerLBIPs) > 0 || os.Getenv("GW_LB_IP") != ""
@@ -432,11 +447,12 @@ func (r *SliceGwReconciler) cleanupSliceGwResources(ctx context.Context, slicegw
 	}
 
 	for _, deployment := range deployments.Items {
-		port, found := gwClientToRemotePortMap.LoadAndDelete(deployment.Name)
+		port, found := deleteRemotePort(deployment.Name)
 		if found {
 			r.Log.Info("SliceGw Deletion: Invalidated deployment to node port mapping", "depName", deployment.Name, "port", port)
 		}
 	}
+	gwPodStatusSnapshots.Delete(slicegw.Name)
 
 	return nil
 }
@@ -460,6 +476,9 @@ func getOVPNClientContainerArgs(remotePortNumber int, g *kubeslicev1beta1.SliceG
 		"--config",
 		"/vpnclient/" + vpnClientFileName,
 	}
+	if m, ok := gatewayMTU(g); ok {
+		args = mtu.OpenVPNArgs(args, m)
+	}
 	return args
 }
 
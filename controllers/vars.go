@@ -36,6 +36,14 @@ var (
 
 	ImagePullSecretName = utils.GetEnvOrDefault("IMAGE_PULL_SECRET_NAME", "kubeslice-nexus")
 
+	// NetworkPolicyBackend selects how slice namespace isolation is enforced on this cluster.
+	// Supported values: NetworkPolicy, AdminNetworkPolicy, CiliumClusterwideNetworkPolicy, CalicoGlobalNetworkPolicy
+	NetworkPolicyBackend = utils.GetEnvOrDefault("NETWORK_POLICY_BACKEND", "NetworkPolicy")
+
+	// NamespaceOffboardingDrainPeriod is the time an application namespace leaving a slice is given to drain
+	// before its workloads are restarted to detach them from the slice, eg: 60s
+	NamespaceOffboardingDrainPeriod = utils.GetEnvOrDefault("NAMESPACE_OFFBOARDING_DRAIN_PERIOD", "60s")
+
 	ReconcileInterval = 10 * time.Second
 	// This value is the periodic reconcile interval for slicegateway CRs. The slicegateway CRD reconciler is set up to
 	// be event driven. In addition to being triggered due to updates to the slicegateway CR objects, it is also invoked
@@ -46,10 +54,12 @@ var (
 
 const (
 	ApplicationNamespaceSelectorLabelKey = "kubeslice.io/slice"
-	SliceGatewaySelectorLabelKey         = "kubeslice.io/slice-gw"
-	SliceGatewayEdgeTypeLabelKey         = "kubeslice.io/slice-gw-edge-type"
-	NodeTypeSelectorLabelKey             = "kubeslice.io/node-type"
-	PodTypeSelectorLabelKey              = "kubeslice.io/pod-type"
-	PodTypeSelectorValueApp              = "app"
-	TopologyKeySelector                  = "topology.kubeslice.io/gateway"
+	// ApplicationNamespaceOffboardingLabelKey marks a namespace that is leaving the slice named by the label value
+	ApplicationNamespaceOffboardingLabelKey = "kubeslice.io/offboarding"
+	SliceGatewaySelectorLabelKey            = "kubeslice.io/slice-gw"
+	SliceGatewayEdgeTypeLabelKey            = "kubeslice.io/slice-gw-edge-type"
+	NodeTypeSelectorLabelKey                = "kubeslice.io/node-type"
+	PodTypeSelectorLabelKey                 = "kubeslice.io/pod-type"
+	PodTypeSelectorValueApp                 = "app"
+	TopologyKeySelector                     = "topology.kubeslice.io/gateway"
 )
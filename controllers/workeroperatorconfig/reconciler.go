@@ -0,0 +1,103 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package workeroperatorconfig
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reconciler applies the WorkerOperatorConfig to the running operator
+type Reconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=networking.kubeslice.io,resources=workeroperatorconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.kubeslice.io,resources=workeroperatorconfigs/status,verbs=get;update;patch
+
+// Reconcile applies the configuration of the WorkerOperatorConfig named operatorconfig.ConfigName
+// and reports the effective configuration in its status. Other objects are ignored.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("workeroperatorconfig", req.Name)
+	ctx = logger.WithLogger(ctx, log)
+
+	if req.Name != operatorconfig.ConfigName {
+		log.Info("ignoring workeroperatorconfig, only the config named " + operatorconfig.ConfigName + " is applied")
+		return ctrl.Result{}, nil
+	}
+
+	cfg := &kubeslicev1beta1.WorkerOperatorConfig{}
+	err := r.Get(ctx, req.NamespacedName, cfg)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("workeroperatorconfig deleted, falling back to the environment")
+			apply(nil)
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get workeroperatorconfig")
+		return ctrl.Result{}, err
+	}
+
+	apply(&cfg.Spec)
+	log.Info("applied workeroperatorconfig", "generation", cfg.Generation, "logLevel", logger.GetLogLevel())
+
+	status := kubeslicev1beta1.WorkerOperatorConfigStatus{
+		ObservedGeneration: cfg.Generation,
+		EffectiveConfig:    effectiveConfig(),
+	}
+	if equality.Semantic.DeepEqual(cfg.Status, status) {
+		return ctrl.Result{}, nil
+	}
+	cfg.Status = status
+	if err := r.Status().Update(ctx, cfg); err != nil {
+		log.Error(err, "Failed to update workeroperatorconfig status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// apply makes the configuration visible to the rest of the operator
+func apply(spec *kubeslicev1beta1.WorkerOperatorConfigSpec) {
+	operatorconfig.Set(spec)
+	logger.SetLogLevel(operatorconfig.LogLevel())
+}
+
+func effectiveConfig() kubeslicev1beta1.WorkerOperatorConfigSpec {
+	return operatorconfig.Effective(controllers.ReconcileInterval, controllers.SliceGatewayReconcileInterval, logger.GetLogLevel())
+}
+
+// SetupWithManager sets up reconciler with manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kubeslicev1beta1.WorkerOperatorConfig{}).
+		Complete(tracing.NewReconciler("workeroperatorconfig", r))
+}
@@ -0,0 +1,107 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package workeroperatorconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileAppliesConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(kubeslicev1beta1.AddToScheme(scheme))
+	cfg := &kubeslicev1beta1.WorkerOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: operatorconfig.ConfigName, Generation: 2},
+		Spec: kubeslicev1beta1.WorkerOperatorConfigSpec{
+			ReconcileInterval: &metav1.Duration{Duration: 45 * time.Second},
+			LogLevel:          "DEBUG",
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg).WithStatusSubresource(cfg).Build()
+	r := &Reconciler{Client: c, Log: logger.NewWrappedLogger(), Scheme: scheme}
+	defer apply(nil)
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: operatorconfig.ConfigName}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := operatorconfig.ReconcileInterval(10 * time.Second); got != 45*time.Second {
+		t.Fatalf("expected reconcile interval to be applied, got %s", got)
+	}
+	if logger.GetLogLevel() != "DEBUG" {
+		t.Fatalf("expected log level to be applied, got %s", logger.GetLogLevel())
+	}
+
+	updated := &kubeslicev1beta1.WorkerOperatorConfig{}
+	if err := c.Get(context.Background(), req.NamespacedName, updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Status.ObservedGeneration != 2 {
+		t.Fatalf("expected observed generation 2, got %d", updated.Status.ObservedGeneration)
+	}
+	if updated.Status.EffectiveConfig.ReconcileInterval.Duration != 45*time.Second ||
+		updated.Status.EffectiveConfig.SliceGatewayReconcileInterval == nil ||
+		updated.Status.EffectiveConfig.LogLevel != "DEBUG" {
+		t.Fatalf("unexpected effective config: %+v", updated.Status.EffectiveConfig)
+	}
+
+	// deleting the config falls back to the environment
+	if err := c.Delete(context.Background(), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := operatorconfig.ReconcileInterval(10 * time.Second); got != 10*time.Second {
+		t.Fatalf("expected default reconcile interval after delete, got %s", got)
+	}
+	if logger.GetLogLevel() != "INFO" {
+		t.Fatalf("expected default log level after delete, got %s", logger.GetLogLevel())
+	}
+}
+
+func TestReconcileIgnoresOtherConfigs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(kubeslicev1beta1.AddToScheme(scheme))
+	cfg := &kubeslicev1beta1.WorkerOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "other"},
+		Spec:       kubeslicev1beta1.WorkerOperatorConfigSpec{LogLevel: "ERROR"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg).WithStatusSubresource(cfg).Build()
+	r := &Reconciler{Client: c, Log: logger.NewWrappedLogger(), Scheme: scheme}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "other"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if operatorconfig.LogLevel() == "ERROR" {
+		t.Fatalf("expected config other than %s to be ignored", operatorconfig.ConfigName)
+	}
+}
@@ -702,6 +702,94 @@ var EventsMap = map[events.EventName]*events.EventSchema{
 		ReportingController: "worker",
 		Message:             "Gateway recycling failed",
 	},
+	"WebhookAuditMutationSkipped": {
+		Name:                "WebhookAuditMutationSkipped",
+		Reason:              "WebhookAuditMutationSkipped",
+		Action:              "None",
+		Type:                events.EventTypeNormal,
+		ReportingController: "worker",
+		Message:             "Webhook in audit mode - object would have been onboarded to the slice",
+	},
+	"WebhookAuditDenialSkipped": {
+		Name:                "WebhookAuditDenialSkipped",
+		Reason:              "WebhookAuditDenialSkipped",
+		Action:              "None",
+		Type:                events.EventTypeWarning,
+		ReportingController: "worker",
+		Message:             "Webhook in audit mode - object would have been rejected",
+	},
+	"AppNamespaceOffboardingStarted": {
+		Name:                "AppNamespaceOffboardingStarted",
+		Reason:              "AppNamespaceOffboardingStarted",
+		Action:              "DrainNamespace",
+		Type:                events.EventTypeNormal,
+		ReportingController: "worker",
+		Message:             "Application namespace offboarding started - new serviceexports are rejected until the namespace is offboarded",
+	},
+	"AppNamespaceOffboarded": {
+		Name:                "AppNamespaceOffboarded",
+		Reason:              "AppNamespaceOffboarded",
+		Action:              "RestartWorkloads",
+		Type:                events.EventTypeNormal,
+		ReportingController: "worker",
+		Message:             "Application namespace offboarded - workloads restarted to detach them from the slice",
+	},
+	"AppNamespaceOffboardingFailed": {
+		Name:                "AppNamespaceOffboardingFailed",
+		Reason:              "AppNamespaceOffboardingFailed",
+		Action:              "RestartWorkloads",
+		Type:                events.EventTypeWarning,
+		ReportingController: "worker",
+		Message:             "Application namespace offboarding failed - unable to restart workloads of the namespace",
+	},
+	"SliceSubnetOverlapDetected": {
+		Name:                "SliceSubnetOverlapDetected",
+		Reason:              "SliceSubnetOverlapDetected",
+		Action:              "ValidateSliceSubnets",
+		Type:                events.EventTypeWarning,
+		ReportingController: "worker",
+		Message:             "Slice subnets overlap with the cluster CIDRs - slice router and gateways are not deployed, please ask admin to change the slice subnet",
+	},
+	"SliceSubnetOverlapResolved": {
+		Name:                "SliceSubnetOverlapResolved",
+		Reason:              "SliceSubnetOverlapResolved",
+		Action:              "ValidateSliceSubnets",
+		Type:                events.EventTypeNormal,
+		ReportingController: "worker",
+		Message:             "Slice subnets no longer overlap with the cluster CIDRs",
+	},
+	"SliceCaptureSucceeded": {
+		Name:                "SliceCaptureSucceeded",
+		Reason:              "SliceCaptureSucceeded",
+		Action:              "CapturePackets",
+		Type:                events.EventTypeNormal,
+		ReportingController: "worker",
+		Message:             "Slice packet capture completed - the pcap files are stored at the location of the slicecapture status",
+	},
+	"SliceCaptureFailed": {
+		Name:                "SliceCaptureFailed",
+		Reason:              "SliceCaptureFailed",
+		Action:              "CapturePackets",
+		Type:                events.EventTypeWarning,
+		ReportingController: "worker",
+		Message:             "Slice packet capture failed - see the message of the slicecapture status",
+	},
+	"SliceMaintenanceStarted": {
+		Name:                "SliceMaintenanceStarted",
+		Reason:              "SliceMaintenanceStarted",
+		Action:              "SuspendSliceReconciliation",
+		Type:                events.EventTypeNormal,
+		ReportingController: "worker",
+		Message:             "Slice maintenance started - restarts, rebalancing, recycling and QoS pushes are suspended on this cluster",
+	},
+	"SliceMaintenanceEnded": {
+		Name:                "SliceMaintenanceEnded",
+		Reason:              "SliceMaintenanceEnded",
+		Action:              "ResumeSliceReconciliation",
+		Type:                events.EventTypeNormal,
+		ReportingController: "worker",
+		Message:             "Slice maintenance ended - reconciliation of the slice components resumed on this cluster",
+	},
 }
 
 var (
@@ -790,4 +878,15 @@ var (
 	EventTriggeredFSMToRecycleGateways                    events.EventName = "TriggeredFSMToRecycleGateways"
 	EventGatewayRecyclingSuccessful                       events.EventName = "GatewayRecyclingSuccessful"
 	EventGatewayRecyclingFailed                           events.EventName = "GatewayRecyclingFailed"
+	EventWebhookAuditMutationSkipped                      events.EventName = "WebhookAuditMutationSkipped"
+	EventWebhookAuditDenialSkipped                        events.EventName = "WebhookAuditDenialSkipped"
+	EventAppNamespaceOffboardingStarted                   events.EventName = "AppNamespaceOffboardingStarted"
+	EventAppNamespaceOffboarded                           events.EventName = "AppNamespaceOffboarded"
+	EventAppNamespaceOffboardingFailed                    events.EventName = "AppNamespaceOffboardingFailed"
+	EventSliceSubnetOverlapDetected                       events.EventName = "SliceSubnetOverlapDetected"
+	EventSliceSubnetOverlapResolved                       events.EventName = "SliceSubnetOverlapResolved"
+	EventSliceCaptureSucceeded                            events.EventName = "SliceCaptureSucceeded"
+	EventSliceCaptureFailed                               events.EventName = "SliceCaptureFailed"
+	EventSliceMaintenanceStarted                          events.EventName = "SliceMaintenanceStarted"
+	EventSliceMaintenanceEnded                            events.EventName = "SliceMaintenanceEnded"
 )
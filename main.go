@@ -19,9 +19,13 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/kubeslice/kubeslice-monitoring/pkg/metrics"
 	"github.com/kubeslice/worker-operator/controllers"
@@ -63,12 +67,21 @@ import (
 	"github.com/kubeslice/worker-operator/controllers/serviceexport"
 	"github.com/kubeslice/worker-operator/controllers/serviceimport"
 	"github.com/kubeslice/worker-operator/controllers/slice"
+	"github.com/kubeslice/worker-operator/controllers/slicecapture"
 	"github.com/kubeslice/worker-operator/controllers/slicegateway"
+	"github.com/kubeslice/worker-operator/controllers/workeroperatorconfig"
 	ossEvents "github.com/kubeslice/worker-operator/events"
+	"github.com/kubeslice/worker-operator/pkg/debug"
+	"github.com/kubeslice/worker-operator/pkg/diag"
+	"github.com/kubeslice/worker-operator/pkg/gc"
+	"github.com/kubeslice/worker-operator/pkg/health"
 	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
 	"github.com/kubeslice/worker-operator/pkg/hub/manager"
 	"github.com/kubeslice/worker-operator/pkg/logger"
 	"github.com/kubeslice/worker-operator/pkg/networkpolicy"
+	"github.com/kubeslice/worker-operator/pkg/networkpolicy/backend"
+	"github.com/kubeslice/worker-operator/pkg/probe"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 	podwh "github.com/kubeslice/worker-operator/pkg/webhook/pod"
 	//+kubebuilder:scaffold:imports
@@ -89,14 +102,38 @@ func init() {
 }
 
 func main() {
+	// the diag subcommand collects the support bundle of a slice instead of running the operator
+	if len(os.Args) > 1 && os.Args[1] == "diag" {
+		if err := diag.Run(ctrl.SetupSignalHandler(), os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	// the probe subcommand runs the agent of the connectivity probe pods of the slices
+	if len(os.Args) > 1 && os.Args[1] == "probe" {
+		if err := probe.Run(ctrl.SetupSignalHandler(), os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var leaseDuration, renewDeadline, retryPeriod time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&leaseDuration, "leader-elect-lease-duration", 15*time.Second,
+		"The duration that non-leader candidates will wait before forcing to acquire leadership.")
+	flag.DurationVar(&renewDeadline, "leader-elect-renew-deadline", 10*time.Second,
+		"The duration that the leader will retry refreshing leadership before giving up.")
+	flag.DurationVar(&retryPeriod, "leader-elect-retry-period", 2*time.Second,
+		"The duration the candidates should wait between tries of actions.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -105,14 +142,41 @@ func main() {
 
 	ctrl.SetLogger(logger.NewWrappedLogger())
 
+	// the sidecar checks are served next to the metrics, they are not part of the readiness probe
+	statusHandler := health.NewStatusHandler()
 	mgrMetrics := metricsserver.Options{
 		BindAddress: metricsAddr,
+		ExtraHandlers: map[string]http.Handler{
+			health.StatusPath: statusHandler,
+		},
+	}
+	if debug.Enabled {
+		debugClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+		if err != nil {
+			setupLog.With("error", err).Error("unable to create kube client for the debug endpoint")
+			os.Exit(1)
+		}
+		// the in-memory state of the controllers is served next to the metrics, for the users allowed
+		// to get the path of the endpoint
+		mgrMetrics.ExtraHandlers[debug.StatePath] = debug.Handler(debug.NewReviewAuthorizer(debugClient))
+		debug.Register("slicegateway", slicegateway.DebugState)
 	}
 
+	webhookCertDir := utils.GetEnvOrDefault("WEBHOOK_CERTS_DIR", "/etc/webhook/certs")
 	webhookServer := webhook.NewServer(webhook.Options{
 		Port:    9443,
-		CertDir: utils.GetEnvOrDefault("WEBHOOK_CERTS_DIR", "/etc/webhook/certs"),
+		CertDir: webhookCertDir,
+	})
+
+	mf, err := metrics.NewMetricsFactory(ctrlmetrics.Registry, metrics.MetricsFactoryOptions{
+		Cluster:             controllers.ClusterName,
+		Project:             strings.TrimPrefix(hub.ProjectNamespace, "kubeslice_"),
+		ReportingController: "workerOperator",
 	})
+	if err != nil {
+		setupLog.With("error", err).Error("unable to initializ metrics factory")
+		os.Exit(1)
+	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
@@ -121,6 +185,12 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "f7425d89.kubeslice.io",
+		// the process exits right after the manager stops, release the lease so that a standby
+		// replica takes over without waiting for the lease to expire
+		LeaderElectionReleaseOnCancel: true,
+		LeaseDuration:                 &leaseDuration,
+		RenewDeadline:                 &renewDeadline,
+		RetryPeriod:                   &retryPeriod,
 	})
 
 	er := &monitoring.EventRecorder{
@@ -132,12 +202,30 @@ func main() {
 	}
 
 	// Use an environment variable to be able to disable webhooks, so that we can run the operator locally
-	if utils.GetEnvOrDefault("ENABLE_WEBHOOKS", "true") == "true" {
+	enableWebhooks := utils.GetEnvOrDefault("ENABLE_WEBHOOKS", "true") == "true"
+	if enableWebhooks {
+		// additional workload kinds to onboard, eg: Rollout.argoproj.io=spec.template
+		if err := podwh.RegisterCustomWorkloadKinds(os.Getenv("WEBHOOK_CUSTOM_WORKLOADS")); err != nil {
+			setupLog.With("error", err).Error("unable to register custom workload kinds")
+			os.Exit(1)
+		}
+		// audit mode allows every request and only records what the webhook would have done
+		webhookEventRecorder := monitoringEvents.NewEventRecorder(mgr.GetClient(), scheme, ossEvents.EventsMap, monitoringEvents.EventRecorderOptions{
+			Version:   utils.EventsVersion,
+			Slice:     utils.NotApplicable,
+			Cluster:   controllers.ClusterName,
+			Project:   hub.ProjectNamespace,
+			Component: "webhook",
+		})
+		auditRecorder := podwh.NewAuditRecorder(&webhookEventRecorder, er, mf)
+		auditMode := utils.GetEnvOrDefault("WEBHOOK_AUDIT_MODE", "false") == "true"
 		mgr.GetWebhookServer().Register("/mutate-webhook", &webhook.Admission{
 			Handler: &podwh.WebhookServer{
 				Client:          mgr.GetClient(),
 				SliceInfoClient: podwh.NewWebhookClient(),
 				Decoder:         admission.NewDecoder(mgr.GetScheme()),
+				AuditMode:       auditMode,
+				AuditRecorder:   auditRecorder,
 			},
 		})
 		mgr.GetWebhookServer().Register("/validate-webhook", &webhook.Admission{
@@ -145,6 +233,8 @@ func main() {
 				Client:          mgr.GetClient(),
 				SliceInfoClient: podwh.NewWebhookClient(),
 				Decoder:         admission.NewDecoder(mgr.GetScheme()),
+				AuditMode:       auditMode,
+				AuditRecorder:   auditRecorder,
 			},
 		})
 	}
@@ -165,11 +255,23 @@ func main() {
 		//view.SetReportingPeriod(10 * time.Millisecond)
 	}
 
-	hubClient, err := hub.NewHubClientConfig(er)
+	hubProjects, err := hub.LoadProjects()
+	if err != nil {
+		setupLog.With("error", err).Error("could not load hub projects")
+		os.Exit(1)
+	}
+	hubClients, err := hub.NewProjectHubClients(hubProjects, mgr.GetClient(), er, mf)
 	if err != nil {
 		setupLog.With("error", err).Error("could not create hub client for slice gateway reconciler")
 		os.Exit(1)
 	}
+	// probe the hubs in the background to track their reachability
+	for _, hubClient := range hubClients.Clients() {
+		if err := mgr.Add(hubClient.Connection); err != nil {
+			setupLog.With("error", err, "project", hubClient.Project.Name).Error("unable to add hub connection monitor")
+			os.Exit(1)
+		}
+	}
 
 	workerRouterClient, err := router.NewWorkerRouterClientProvider()
 	if err != nil {
@@ -195,13 +297,9 @@ func main() {
 
 	ctx := ctrl.SetupSignalHandler()
 
-	mf, err := metrics.NewMetricsFactory(ctrlmetrics.Registry, metrics.MetricsFactoryOptions{
-		Cluster:             controllers.ClusterName,
-		Project:             strings.TrimPrefix(hub.ProjectNamespace, "kubeslice_"),
-		ReportingController: "workerOperator",
-	})
+	shutdownTracing, err := tracing.Setup(ctx)
 	if err != nil {
-		setupLog.With("error", err).Error("unable to initializ metrics factory")
+		setupLog.With("error", err).Error("unable to set up tracing")
 		os.Exit(1)
 	}
 
@@ -213,8 +311,14 @@ func main() {
 		Component: "sliceController",
 		Namespace: controllers.ControlPlaneNamespace,
 	})
-	workerRecyclerClient, err := slicegwrecycler.NewRecyclerClient(ctx, clientForHubMgr, hubClient, &sliceEventRecorder, mgr.GetScheme())
+	workerRecyclerClient, err := slicegwrecycler.NewRecyclerClient(ctx, clientForHubMgr, hubClients, &sliceEventRecorder, mgr.GetScheme())
+	if err != nil {
+		os.Exit(1)
+	}
+
+	netpolBackend, err := backend.New(controllers.NetworkPolicyBackend, mgr.GetClient())
 	if err != nil {
+		setupLog.With("error", err).Error("unable to create network policy backend", "backend", controllers.NetworkPolicyBackend)
 		os.Exit(1)
 	}
 
@@ -222,11 +326,13 @@ func main() {
 		Client:                  mgr.GetClient(),
 		Log:                     ctrl.Log.WithName("controllers").WithName("Slice"),
 		Scheme:                  mgr.GetScheme(),
-		HubClient:               hubClient,
+		HubClient:               hubClients,
 		EventRecorder:           &sliceEventRecorder,
 		WorkerRouterClient:      workerRouterClient,
 		WorkerNetOpClient:       workerNetOPClient,
 		WorkerGatewayEdgeClient: workerGatewayEdgeClient,
+		NetworkPolicyBackend:    netpolBackend,
+		HubConnection:           hubClients,
 	}).Setup(mgr, mf); err != nil {
 		setupLog.With("error", err).Error("unable to create controller", "controller", "Slice")
 		os.Exit(1)
@@ -241,14 +347,14 @@ func main() {
 		Client:                mgr.GetClient(),
 		Log:                   ctrl.Log.WithName("controllers").WithName("SliceGw"),
 		Scheme:                mgr.GetScheme(),
-		HubClient:             hubClient,
+		HubClient:             hubClients,
 		WorkerGWSidecarClient: workerGWClient,
 		WorkerRouterClient:    workerRouterClient,
 		WorkerNetOpClient:     workerNetOPClient,
 		WorkerRecyclerClient:  workerRecyclerClient,
 		EventRecorder:         &sliceEventRecorder,
 		NumberOfGateways:      2,
-	}).SetupWithManager(mgr); err != nil {
+	}).Setup(mgr, mf); err != nil {
 		setupLog.With("error", err).Error("unable to create controller", "controller", "SliceGw")
 		os.Exit(1)
 	}
@@ -257,7 +363,7 @@ func main() {
 		Client:        mgr.GetClient(),
 		Log:           ctrl.Log.WithName("controllers").WithName("ServiceExport"),
 		Scheme:        mgr.GetScheme(),
-		HubClient:     hubClient,
+		HubClient:     hubClients,
 		EventRecorder: &sliceEventRecorder,
 	}).Setup(mgr, mf); err != nil {
 		setupLog.With("error", err, "controller", "ServiceExport").Error("unable to create controller")
@@ -279,7 +385,7 @@ func main() {
 		Log:           ctrl.Log.WithName("controllers").WithName("namespace"),
 		Scheme:        mgr.GetScheme(),
 		EventRecorder: &sliceEventRecorder,
-		Hubclient:     hubClient,
+		Hubclient:     hubClients,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.With("error", err, "controller", "namespace").Error("unable to create controller")
 		os.Exit(1)
@@ -293,28 +399,92 @@ func main() {
 		setupLog.With("error", err, "controller", "networkpolicy").Error("unable to create controller")
 		os.Exit(1)
 	}
+	if err = (&workeroperatorconfig.Reconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("WorkerOperatorConfig"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.With("error", err, "controller", "WorkerOperatorConfig").Error("unable to create controller")
+		os.Exit(1)
+	}
+	captureLogs, err := slicecapture.NewLogReader(mgr.GetConfig())
+	if err != nil {
+		setupLog.With("error", err).Error("unable to create the log reader of the slicecaptures")
+		os.Exit(1)
+	}
+	if err = (&slicecapture.Reconciler{
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("SliceCapture"),
+		Scheme:        mgr.GetScheme(),
+		Logs:          captureLogs,
+		EventRecorder: &sliceEventRecorder,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.With("error", err, "controller", "SliceCapture").Error("unable to create controller")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.With("error", err).Error("unable to set up health check")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.With("error", err).Error("unable to set up ready check")
-		os.Exit(1)
+	// every check is also served individually on /readyz/<name>. The hub checks only fail once the hub
+	// has been unreachable for longer than HUB_READINESS_GRACE_PERIOD.
+	readyzChecks := map[string]healthz.Checker{
+		"readyz":    healthz.Ping,
+		"informers": health.CacheSyncChecker(mgr.GetCache()),
+		"hub":       health.HubChecker(hubClients.Primary().Connection),
+	}
+	for _, hubClient := range hubClients.Clients()[1:] {
+		readyzChecks["hub-"+hubClient.Project.Name] = health.HubChecker(hubClient.Connection)
+	}
+	if enableWebhooks {
+		readyzChecks["webhook"] = health.WebhookChecker(mgr.GetWebhookServer().StartedChecker(), webhookCertDir)
+	}
+	if health.SidecarReadinessChecks {
+		statusHandler.AddCheck("sidecars", health.SidecarChecker(mgr.GetClient()))
+	}
+	for name, checker := range readyzChecks {
+		if err := mgr.AddReadyzCheck(name, checker); err != nil {
+			setupLog.With("error", err, "check", name).Error("unable to set up ready check")
+			os.Exit(1)
+		}
 	}
 
 	if err != nil {
 		setupLog.With("error", err).Error("unable to create kube client for hub manager")
 		os.Exit(1)
 	}
-	go func() {
-		setupLog.Info("starting hub manager")
-		manager.Start(clientForHubMgr, hubClient, ctx)
-	}()
+	// one hub manager runs the controllers of each project the worker is registered in. They are started
+	// by the manager once this replica is elected, so that a single replica reconciles the hub objects.
+	for _, hubClient := range hubClients.Clients() {
+		if err := mgr.Add(manager.NewRunnable(clientForHubMgr, hubClient, hubClients)); err != nil {
+			setupLog.With("error", err, "project", hubClient.Project.Name).Error("unable to add hub manager")
+			os.Exit(1)
+		}
+	}
+
+	if gc.SweepMode != gc.ModeDisabled {
+		if err := mgr.Add(gc.NewSweeper(mgr.GetAPIReader(), mgr.GetClient(), mf)); err != nil {
+			setupLog.With("error", err).Error("unable to add orphan sweeper")
+			os.Exit(1)
+		}
+	}
+
+	if err := mgr.Add(probe.NewProber(mgr.GetClient(), mf)); err != nil {
+		setupLog.With("error", err).Error("unable to add connectivity prober")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctx); err != nil {
+	err = mgr.Start(ctx)
+	// flush the pending spans, the context of the manager is already cancelled
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		setupLog.With("error", err).Error("unable to flush traces")
+	}
+	cancel()
+	if err != nil {
 		setupLog.With("error", err).Error("problem running manager")
 		os.Exit(1)
 	}
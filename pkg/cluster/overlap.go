@@ -0,0 +1,115 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cluster
+
+import (
+	"context"
+	"net/netip"
+	"sort"
+	"strings"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// NsmConfigMap is the configmap in which nsm publishes the pod, service and other excluded CIDRs of the cluster
+	NsmConfigMap = "nsm-config"
+	// NsmConfigNamespace is the namespace of the nsm configmap
+	NsmConfigNamespace = "kubeslice-system"
+)
+
+// SubnetOverlaps returns the subnets that overlap with one of the cluster CIDRs. subnets maps the name
+// of a subnet to its CIDR, empty or invalid CIDRs are skipped. A cluster CIDR without prefix length is
+// a single address.
+func SubnetOverlaps(subnets map[string]string, clusterCIDRs []string) []kubeslicev1beta1.SubnetOverlap {
+	cidrs := []netip.Prefix{}
+	for _, c := range clusterCIDRs {
+		if p, ok := parsePrefix(c); ok {
+			cidrs = append(cidrs, p)
+		}
+	}
+	names := make([]string, 0, len(subnets))
+	for name := range subnets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	overlaps := []kubeslicev1beta1.SubnetOverlap{}
+	for _, name := range names {
+		subnet, ok := parsePrefix(subnets[name])
+		if !ok {
+			continue
+		}
+		for _, cidr := range cidrs {
+			if subnet.Overlaps(cidr) {
+				overlaps = append(overlaps, kubeslicev1beta1.SubnetOverlap{
+					Name:        name,
+					Subnet:      subnets[name],
+					ClusterCIDR: cidr.String(),
+				})
+			}
+		}
+	}
+	return overlaps
+}
+
+func parsePrefix(s string) (netip.Prefix, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return netip.Prefix{}, false
+	}
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, false
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), true
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return p.Masked(), true
+}
+
+// CheckSubnetOverlaps checks the subnets against the CIDRs of the cluster published in the nsm configmap.
+// No overlaps are reported when the configmap is not present, which is the case for clusters installed
+// without networking.
+func CheckSubnetOverlaps(ctx context.Context, c client.Client, subnets map[string]string) ([]kubeslicev1beta1.SubnetOverlap, error) {
+	nsmconfig := corev1.ConfigMap{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: NsmConfigNamespace, Name: NsmConfigMap}, &nsmconfig)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if _, ok := nsmconfig.Data["excluded_prefixes_output.yaml"]; !ok {
+		return nil, nil
+	}
+	prefixes, err := getPrefixes(nsmconfig)
+	if err != nil {
+		return nil, err
+	}
+	return SubnetOverlaps(subnets, prefixes), nil
+}
@@ -0,0 +1,88 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSubnetOverlaps(t *testing.T) {
+	clusterCIDRs := []string{"192.168.0.0/16", "10.96.0.0/12", "172.20.0.10"}
+	var tests = []struct {
+		description string
+		subnets     map[string]string
+		expected    []kubeslicev1beta1.SubnetOverlap
+	}{
+		{"no overlap", map[string]string{"sliceSubnet": "10.1.0.0/16", "clusterSubnetCIDR": "10.1.1.0/24"},
+			[]kubeslicev1beta1.SubnetOverlap{}},
+		{"slice subnet contains cluster cidr", map[string]string{"sliceSubnet": "192.0.0.0/8", "clusterSubnetCIDR": "192.1.1.0/24"},
+			[]kubeslicev1beta1.SubnetOverlap{{Name: "sliceSubnet", Subnet: "192.0.0.0/8", ClusterCIDR: "192.168.0.0/16"}}},
+		{"cluster cidr contains subnets", map[string]string{"sliceSubnet": "10.100.0.0/16", "clusterSubnetCIDR": "10.100.1.0/24"},
+			[]kubeslicev1beta1.SubnetOverlap{
+				{Name: "clusterSubnetCIDR", Subnet: "10.100.1.0/24", ClusterCIDR: "10.96.0.0/12"},
+				{Name: "sliceSubnet", Subnet: "10.100.0.0/16", ClusterCIDR: "10.96.0.0/12"},
+			}},
+		{"single address cluster cidr", map[string]string{"sliceGatewayRemoteSubnet/green-worker-1-worker-2": "172.20.0.0/24"},
+			[]kubeslicev1beta1.SubnetOverlap{{Name: "sliceGatewayRemoteSubnet/green-worker-1-worker-2", Subnet: "172.20.0.0/24", ClusterCIDR: "172.20.0.10/32"}}},
+		{"empty and invalid subnets are skipped", map[string]string{"sliceSubnet": "", "clusterSubnetCIDR": "10.96.0.0/33"},
+			[]kubeslicev1beta1.SubnetOverlap{}},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			actual := SubnetOverlaps(test.subnets, clusterCIDRs)
+			if diff := cmp.Diff(actual, test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
+
+func TestCheckSubnetOverlaps(t *testing.T) {
+	subnets := map[string]string{"sliceSubnet": "10.96.0.0/16"}
+	var tests = []struct {
+		description string
+		objs        []runtime.Object
+		expected    []kubeslicev1beta1.SubnetOverlap
+	}{
+		{"networking disabled", nil, nil},
+		{"overlap with nsm excluded prefix", []runtime.Object{configMap(NsmConfigMap, NsmConfigNamespace, `
+Prefixes:
+- 192.168.0.0/16
+- 10.96.0.0/12
+`)}, []kubeslicev1beta1.SubnetOverlap{{Name: "sliceSubnet", Subnet: "10.96.0.0/16", ClusterCIDR: "10.96.0.0/12"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithRuntimeObjects(test.objs...).Build()
+			actual, err := CheckSubnetOverlaps(context.Background(), c, subnets)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(actual, test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package debug serves the in-memory state of the operator as JSON on the metrics server, eg: the node
+// ports allocated to the gateway clients, the last status polled from the gateway pods and the state
+// machines of the gateway recyclers. The controllers register providers of their state, which is dumped
+// per slice and gateway. Requests are authenticated with their bearer token and authorized by RBAC on the
+// path of the endpoint, so that access can be granted with a ClusterRole on the nonResourceURL.
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/utils"
+)
+
+// StatePath is the path of the state endpoint on the metrics server
+const StatePath = "/debug/state"
+
+var (
+	// Enabled serves the state endpoint on the metrics server, disabled by default
+	Enabled = utils.GetEnvOrDefault("DEBUG_ENDPOINT_ENABLED", "false") == "true"
+
+	log = logger.NewWrappedLogger().WithName("debug")
+)
+
+// Entry is a piece of state of a slice or of one of its gateways
+type Entry struct {
+	Slice string
+	// Gateway is empty for the state of the slice
+	Gateway string
+	// Kind groups the entries of the same type of state, eg: nodePortAllocations
+	Kind  string
+	State interface{}
+}
+
+// Provider returns the current state of a controller. It is called on each request and must be safe
+// to call concurrently with the reconciles of the controller.
+type Provider func() []Entry
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{}
+)
+
+// Register registers the state provider of a controller, replacing the one registered with the same name
+func Register(name string, p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[name] = p
+}
+
+// Unregister removes the state provider of a controller
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(providers, name)
+}
+
+type sliceState struct {
+	State    map[string][]interface{}            `json:"state,omitempty"`
+	Gateways map[string]map[string][]interface{} `json:"gateways,omitempty"`
+}
+
+type stateDump struct {
+	Time   time.Time              `json:"time"`
+	Slices map[string]*sliceState `json:"slices"`
+}
+
+// Dump returns the state of every registered provider, filtered by slice and gateway if they are not empty
+func Dump(slice, gateway string) interface{} {
+	mu.RLock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ps := make([]Provider, 0, len(names))
+	for _, name := range names {
+		ps = append(ps, providers[name])
+	}
+	mu.RUnlock()
+
+	dump := &stateDump{Time: time.Now(), Slices: map[string]*sliceState{}}
+	for _, p := range ps {
+		for _, e := range p() {
+			if (slice != "" && e.Slice != slice) || (gateway != "" && e.Gateway != gateway) {
+				continue
+			}
+			s, ok := dump.Slices[e.Slice]
+			if !ok {
+				s = &sliceState{}
+				dump.Slices[e.Slice] = s
+			}
+			if e.Gateway == "" {
+				if s.State == nil {
+					s.State = map[string][]interface{}{}
+				}
+				s.State[e.Kind] = append(s.State[e.Kind], e.State)
+				continue
+			}
+			if s.Gateways == nil {
+				s.Gateways = map[string]map[string][]interface{}{}
+			}
+			if s.Gateways[e.Gateway] == nil {
+				s.Gateways[e.Gateway] = map[string][]interface{}{}
+			}
+			s.Gateways[e.Gateway][e.Kind] = append(s.Gateways[e.Gateway][e.Kind], e.State)
+		}
+	}
+	return dump
+}
+
+// Authorizer authenticates and authorizes the requests to the state endpoint
+type Authorizer interface {
+	Authorize(ctx context.Context, token, path string) (bool, error)
+}
+
+// reviewAuthorizer authenticates the bearer token with a TokenReview, and authorizes the user with a
+// SubjectAccessReview of a get of the path
+type reviewAuthorizer struct {
+	client client.Client
+}
+
+// NewReviewAuthorizer returns the authorizer of the requests using the API server of the cluster
+func NewReviewAuthorizer(c client.Client) Authorizer {
+	return &reviewAuthorizer{client: c}
+}
+
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+func (a *reviewAuthorizer) Authorize(ctx context.Context, token, path string) (bool, error) {
+	tr := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := a.client.Create(ctx, tr); err != nil {
+		return false, err
+	}
+	if !tr.Status.Authenticated {
+		return false, nil
+	}
+	extra := map[string]authorizationv1.ExtraValue{}
+	for k, v := range tr.Status.User.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   tr.Status.User.Username,
+			UID:    tr.Status.User.UID,
+			Groups: tr.Status.User.Groups,
+			Extra:  extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: path,
+				Verb: "get",
+			},
+		},
+	}
+	if err := a.client.Create(ctx, sar); err != nil {
+		return false, err
+	}
+	return sar.Status.Allowed, nil
+}
+
+// Handler serves the state of the operator as JSON. The state can be filtered with the slice and gateway
+// query parameters, eg: /debug/state?slice=red
+func Handler(authorizer Authorizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		allowed, err := authorizer.Authorize(req.Context(), token, StatePath)
+		if err != nil {
+			log.Error(err, "failed to authorize debug request")
+			http.Error(w, "authorization failed", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		query := req.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(Dump(query.Get("slice"), query.Get("gateway"))); err != nil {
+			log.Error(err, "failed to write debug state")
+		}
+	})
+}
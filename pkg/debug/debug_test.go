@@ -0,0 +1,114 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeAuthorizer struct {
+	tokens map[string]bool
+}
+
+func (a *fakeAuthorizer) Authorize(ctx context.Context, token, path string) (bool, error) {
+	return a.tokens[token] && path == StatePath, nil
+}
+
+func TestHandler(t *testing.T) {
+	Register("test", func() []Entry {
+		return []Entry{
+			{Slice: "red", Gateway: "red-gw-1", Kind: "nodePortAllocations", State: map[string]int{"port": 30001}},
+			{Slice: "red", Gateway: "red-gw-2", Kind: "nodePortAllocations", State: map[string]int{"port": 30002}},
+			{Slice: "red", Kind: "summary", State: "ok"},
+			{Slice: "blue", Gateway: "blue-gw-1", Kind: "recyclers", State: "init"},
+		}
+	})
+	defer Unregister("test")
+	h := Handler(&fakeAuthorizer{tokens: map[string]bool{"allowed": true, "denied": false}})
+
+	cases := map[string]int{
+		"":       http.StatusUnauthorized,
+		"denied": http.StatusForbidden,
+	}
+	for token, code := range cases {
+		req := httptest.NewRequest(http.MethodGet, StatePath, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != code {
+			t.Errorf("token %q: expected %d, got %d", token, code, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, StatePath+"?slice=red&gateway=red-gw-1", nil)
+	req.Header.Set("Authorization", "Bearer allowed")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	dump := struct {
+		Slices map[string]struct {
+			State    map[string][]interface{}            `json:"state"`
+			Gateways map[string]map[string][]interface{} `json:"gateways"`
+		} `json:"slices"`
+	}{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &dump); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if len(dump.Slices) != 1 {
+		t.Fatalf("expected the state of red only, got %v", dump.Slices)
+	}
+	red := dump.Slices["red"]
+	if len(red.Gateways) != 1 || len(red.Gateways["red-gw-1"]["nodePortAllocations"]) != 1 {
+		t.Errorf("expected the state of red-gw-1 only, got %v", red.Gateways)
+	}
+	if len(red.State) != 0 {
+		t.Errorf("expected the state of the slice to be filtered out by the gateway, got %v", red.State)
+	}
+}
+
+func TestDumpGroupsBySliceAndGateway(t *testing.T) {
+	Register("a", func() []Entry {
+		return []Entry{{Slice: "red", Kind: "summary", State: "a"}}
+	})
+	Register("b", func() []Entry {
+		return []Entry{{Slice: "red", Kind: "summary", State: "b"}, {Slice: "red", Gateway: "gw", Kind: "gwPodStatus", State: "up"}}
+	})
+	defer Unregister("a")
+	defer Unregister("b")
+
+	dump := Dump("", "").(*stateDump)
+	red := dump.Slices["red"]
+	if red == nil {
+		t.Fatalf("expected the state of red")
+	}
+	if got := red.State["summary"]; len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("expected the summaries of both providers in order, got %v", got)
+	}
+	if got := red.Gateways["gw"]["gwPodStatus"]; len(got) != 1 {
+		t.Errorf("expected the pod status of gw, got %v", got)
+	}
+}
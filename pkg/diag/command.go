@@ -0,0 +1,157 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package diag
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/gwsidecar"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
+	"github.com/kubeslice/worker-operator/pkg/router"
+)
+
+const usage = `Usage: manager diag --slice <name> [flags]
+
+Collects the support bundle of a slice into a gzipped tarball. Run it in the operator pod to reach
+the sidecars and the hub, eg:
+
+  kubectl exec -n kubeslice-system deploy/kubeslice-operator -c manager -- /manager diag --slice red --output - > red.tar.gz
+
+Flags:
+`
+
+// podLogReader reads the last lines of the logs of the containers
+type podLogReader struct {
+	pods     typedcorev1.PodsGetter
+	tailLine int64
+}
+
+func (r *podLogReader) ReadLogs(ctx context.Context, namespace, pod, container string) ([]byte, error) {
+	return r.pods.Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &r.tailLine,
+	}).DoRaw(ctx)
+}
+
+//+kubebuilder:rbac:groups=core,resources=pods/log,verbs=get
+//+kubebuilder:rbac:groups=core,resources=events,verbs=get;list
+
+// Run runs the diag subcommand of the operator with the given arguments
+func Run(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("diag", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	sliceName := fs.String("slice", "", "Name of the slice to collect the bundle of.")
+	output := fs.String("output", "", "Path of the bundle, - for stdout. Defaults to kubeslice-diag-<slice>.tar.gz.")
+	logLines := fs.Int64("log-lines", 1000, "Number of lines of the logs of each container to collect.")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Timeout of the collection.")
+	noHub := fs.Bool("no-hub", false, "Do not collect the recyclers and the vpn key rotation from the hub.")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if *sliceName == "" {
+		fs.Usage()
+		return errors.New("--slice is required")
+	}
+
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return err
+	}
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(networkingv1.AddToScheme(scheme))
+	utilruntime.Must(kubeslicev1beta1.AddToScheme(scheme))
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	coreClient, err := typedcorev1.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	gwSidecarClient, err := gwsidecar.NewWorkerGWSidecarClientProvider()
+	if err != nil {
+		return err
+	}
+	routerClient, err := router.NewWorkerRouterClientProvider()
+	if err != nil {
+		return err
+	}
+	collector := &Collector{
+		Client:                c,
+		Logs:                  &podLogReader{pods: coreClient, tailLine: *logLines},
+		WorkerGWSidecarClient: gwSidecarClient,
+		WorkerRouterClient:    routerClient,
+	}
+	if !*noHub {
+		projects, err := hub.LoadProjects()
+		if err != nil {
+			return err
+		}
+		hubClients, err := hub.NewProjectHubClients(projects, c, nil, nil)
+		if err != nil {
+			return err
+		}
+		collector.HubClient = hubClients
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+	if *output == "-" {
+		return collector.Collect(ctx, *sliceName, stdout)
+	}
+	path := *output
+	if path == "" {
+		path = "kubeslice-diag-" + *sliceName + ".tar.gz"
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := collector.Collect(ctx, *sliceName, f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "support bundle written to", path)
+	return nil
+}
@@ -0,0 +1,435 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package diag collects the support bundle of a slice on the worker cluster. The bundle is a gzipped
+// tarball of the slice and slice gateway objects, the logs of the gateway and router pods, the status
+// reported by the sidecars, the network policies and the events of the slice, and the recyclers and
+// VPN key rotation of the slice on the hub. Credentials found in the collected data are redacted.
+package diag
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	sidecar "github.com/kubeslice/router-sidecar/pkg/sidecar/sidecarpb"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hubv1alpha1 "github.com/kubeslice/apis/pkg/controller/v1alpha1"
+	spokev1alpha1 "github.com/kubeslice/apis/pkg/worker/v1alpha1"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	"github.com/kubeslice/worker-operator/pkg/gwsidecar"
+	"github.com/kubeslice/worker-operator/pkg/router"
+)
+
+const sidecarGrpcPort = "5000"
+
+type GwSidecarClientProvider interface {
+	GetStatus(ctx context.Context, serverAddr string) (*gwsidecar.GwStatus, error)
+}
+
+type RouterClientProvider interface {
+	GetRouteInKernel(ctx context.Context, serverAddr string, sliceRouterConnCtx *router.GetRouteConfig) (*sidecar.VerifyRouteAddResponse, error)
+}
+
+type HubClientProvider interface {
+	GetVPNKeyRotation(ctx context.Context, rotationName string) (*hubv1alpha1.VpnKeyRotation, error)
+	ListWorkerSliceGwRecycler(ctx context.Context, sliceGWName string) ([]spokev1alpha1.WorkerSliceGwRecycler, error)
+}
+
+// LogReader reads the logs of a container of a pod
+type LogReader interface {
+	ReadLogs(ctx context.Context, namespace, pod, container string) ([]byte, error)
+}
+
+// Collector collects the support bundle of a slice. The collection is best effort: the data that cannot
+// be collected is skipped and the errors are recorded in errors.txt of the bundle.
+type Collector struct {
+	Client                client.Client
+	Logs                  LogReader
+	WorkerGWSidecarClient GwSidecarClientProvider
+	WorkerRouterClient    RouterClientProvider
+	// HubClient is optional, the hub objects are not collected without it
+	HubClient HubClientProvider
+	// Now returns the time of the collection, time.Now if nil
+	Now func() time.Time
+}
+
+type bundle struct {
+	tw     *tar.Writer
+	prefix string
+	now    time.Time
+	errs   []string
+}
+
+func (b *bundle) add(name string, data []byte) error {
+	data = Redact(data)
+	if err := b.tw.WriteHeader(&tar.Header{
+		Name:    b.prefix + name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: b.now,
+	}); err != nil {
+		return err
+	}
+	_, err := b.tw.Write(data)
+	return err
+}
+
+func (b *bundle) addJSON(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		b.recordError(name, err)
+		return nil
+	}
+	return b.add(name, append(data, '\n'))
+}
+
+func (b *bundle) recordError(what string, err error) {
+	b.errs = append(b.errs, fmt.Sprintf("%s: %v", what, err))
+}
+
+// Collect writes the support bundle of the slice to w
+func (c *Collector) Collect(ctx context.Context, sliceName string, w io.Writer) error {
+	now := time.Now()
+	if c.Now != nil {
+		now = c.Now()
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	b := &bundle{
+		tw:     tw,
+		prefix: fmt.Sprintf("kubeslice-diag-%s-%s/", sliceName, now.UTC().Format("20060102T150405Z")),
+		now:    now,
+	}
+
+	if err := c.collect(ctx, b, sliceName); err != nil {
+		return err
+	}
+	if len(b.errs) > 0 {
+		if err := b.add("errors.txt", []byte(strings.Join(b.errs, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// collect adds the objects of the slice to the bundle. It returns an error only if the bundle cannot be
+// written, the errors of the collection are recorded in the bundle.
+func (c *Collector) collect(ctx context.Context, b *bundle, sliceName string) error {
+	slice := &kubeslicev1beta1.Slice{}
+	if err := c.Client.Get(ctx, client.ObjectKey{Name: sliceName, Namespace: controllers.ControlPlaneNamespace}, slice); err != nil {
+		// the rest of the bundle can still help when the slice is gone, eg: leftover gateways
+		b.recordError("slice", err)
+		slice = nil
+	} else {
+		sanitize(slice)
+		if err := b.addJSON("slice.json", slice); err != nil {
+			return err
+		}
+		if err := b.addJSON("sliceconfig.json", slice.Status.SliceConfig); err != nil {
+			return err
+		}
+	}
+
+	gateways, err := c.sliceGateways(ctx, sliceName)
+	if err != nil {
+		b.recordError("slicegateways", err)
+	}
+	involved := map[string]bool{sliceName: true}
+	for i := range gateways {
+		gw := &gateways[i]
+		involved[gw.Name] = true
+		if err := c.collectGateway(ctx, b, gw, involved); err != nil {
+			return err
+		}
+	}
+	if err := c.collectRouter(ctx, b, sliceName, gateways, involved); err != nil {
+		return err
+	}
+	if err := c.collectNetworkPolicies(ctx, b, slice); err != nil {
+		return err
+	}
+	if err := c.collectEvents(ctx, b, slice, involved); err != nil {
+		return err
+	}
+	if c.HubClient != nil {
+		rotation, err := c.HubClient.GetVPNKeyRotation(ctx, sliceName)
+		if err != nil {
+			b.recordError("vpnkeyrotation", err)
+		} else {
+			sanitize(rotation)
+			if err := b.addJSON("vpnkeyrotation.json", rotation); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Collector) sliceGateways(ctx context.Context, sliceName string) ([]kubeslicev1beta1.SliceGateway, error) {
+	list := &kubeslicev1beta1.SliceGatewayList{}
+	if err := c.Client.List(ctx, list, client.InNamespace(controllers.ControlPlaneNamespace)); err != nil {
+		return nil, err
+	}
+	var gateways []kubeslicev1beta1.SliceGateway
+	for _, gw := range list.Items {
+		if gw.Spec.SliceName == sliceName {
+			gateways = append(gateways, gw)
+		}
+	}
+	return gateways, nil
+}
+
+func (c *Collector) collectGateway(ctx context.Context, b *bundle, gw *kubeslicev1beta1.SliceGateway, involved map[string]bool) error {
+	dir := "slicegateways/" + gw.Name + "/"
+	sanitize(gw)
+	if err := b.addJSON(dir+"slicegateway.json", gw); err != nil {
+		return err
+	}
+	if err := b.addJSON(dir+"gwpodinfo.json", gw.Status.GatewayPodStatus); err != nil {
+		return err
+	}
+	if c.HubClient != nil {
+		recyclers, err := c.HubClient.ListWorkerSliceGwRecycler(ctx, gw.Name)
+		if err != nil {
+			b.recordError(dir+"recyclers", err)
+		} else {
+			for i := range recyclers {
+				sanitize(&recyclers[i])
+			}
+			if err := b.addJSON(dir+"recyclers.json", recyclers); err != nil {
+				return err
+			}
+		}
+	}
+
+	pods := &corev1.PodList{}
+	if err := c.Client.List(ctx, pods, client.InNamespace(controllers.ControlPlaneNamespace), client.MatchingLabels{
+		controllers.PodTypeSelectorLabelKey: "slicegateway",
+		"kubeslice.io/slice-gw":             gw.Name,
+	}); err != nil {
+		b.recordError(dir+"pods", err)
+		return nil
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		involved[pod.Name] = true
+		if err := c.collectLogs(ctx, b, dir+"pods/", pod); err != nil {
+			return err
+		}
+		if pod.Status.PodIP == "" {
+			continue
+		}
+		status, err := c.WorkerGWSidecarClient.GetStatus(ctx, pod.Status.PodIP+":"+sidecarGrpcPort)
+		if err != nil {
+			b.recordError(dir+"pods/"+pod.Name+"/sidecar-status", err)
+			continue
+		}
+		// the nsm and tunnel status have fields with the same names, keep them apart
+		if err := b.addJSON(dir+"pods/"+pod.Name+"/sidecar-status.json", map[string]interface{}{
+			"nsmStatus":    status.NsmStatus,
+			"tunnelStatus": status.TunnelStatus,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Collector) collectLogs(ctx context.Context, b *bundle, dir string, pod *corev1.Pod) error {
+	if c.Logs == nil {
+		return nil
+	}
+	for _, container := range pod.Spec.Containers {
+		logs, err := c.Logs.ReadLogs(ctx, pod.Namespace, pod.Name, container.Name)
+		if err != nil {
+			b.recordError(dir+pod.Name+"/"+container.Name+".log", err)
+			continue
+		}
+		if err := b.add(dir+pod.Name+"/"+container.Name+".log", logs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// routeCheck is the result of the check of the route of a gateway pod in the slice router
+type routeCheck struct {
+	SliceGateway   string `json:"sliceGateway"`
+	GatewayPod     string `json:"gatewayPod"`
+	NsmIP          string `json:"nsmIP"`
+	RemoteSubnet   string `json:"remoteSubnet"`
+	IsRoutePresent bool   `json:"isRoutePresent"`
+	Error          string `json:"error,omitempty"`
+}
+
+func (c *Collector) collectRouter(ctx context.Context, b *bundle, sliceName string, gateways []kubeslicev1beta1.SliceGateway, involved map[string]bool) error {
+	podName, podIP, err := controllers.GetSliceRouterPodNameAndIP(ctx, c.Client, sliceName)
+	if err != nil {
+		b.recordError("router", err)
+		return nil
+	}
+	if podName == "" {
+		b.recordError("router", fmt.Errorf("no running slice router pod"))
+		return nil
+	}
+	involved[podName] = true
+	pod := &corev1.Pod{}
+	if err := c.Client.Get(ctx, client.ObjectKey{Name: podName, Namespace: controllers.ControlPlaneNamespace}, pod); err != nil {
+		b.recordError("router/"+podName, err)
+	} else if err := c.collectLogs(ctx, b, "router/", pod); err != nil {
+		return err
+	}
+
+	var routes []routeCheck
+	for _, gw := range gateways {
+		for _, gwPod := range gw.Status.GatewayPodStatus {
+			if gwPod == nil || gwPod.LocalNsmIP == "" {
+				continue
+			}
+			check := routeCheck{
+				SliceGateway: gw.Name,
+				GatewayPod:   gwPod.PodName,
+				NsmIP:        gwPod.LocalNsmIP,
+				RemoteSubnet: gw.Status.Config.SliceGatewayRemoteSubnet,
+			}
+			res, err := c.WorkerRouterClient.GetRouteInKernel(ctx, podIP+":"+sidecarGrpcPort, &router.GetRouteConfig{
+				RemoteSliceGwNsmSubnet: check.RemoteSubnet,
+				NsmIp:                  check.NsmIP,
+			})
+			if err != nil {
+				check.Error = err.Error()
+			} else {
+				check.IsRoutePresent = res.IsRoutePresent
+			}
+			routes = append(routes, check)
+		}
+	}
+	return b.addJSON("router/routes.json", routes)
+}
+
+// sliceNamespaces returns the namespaces of the slice, the control plane namespace first
+func sliceNamespaces(slice *kubeslicev1beta1.Slice) []string {
+	seen := map[string]bool{controllers.ControlPlaneNamespace: true}
+	var namespaces []string
+	if slice != nil {
+		if slice.Status.SliceConfig != nil && slice.Status.SliceConfig.NamespaceIsolationProfile != nil {
+			for _, ns := range slice.Status.SliceConfig.NamespaceIsolationProfile.ApplicationNamespaces {
+				if !seen[ns] {
+					seen[ns] = true
+					namespaces = append(namespaces, ns)
+				}
+			}
+		}
+		for _, ns := range slice.Status.ApplicationNamespaces {
+			if !seen[ns] {
+				seen[ns] = true
+				namespaces = append(namespaces, ns)
+			}
+		}
+	}
+	sort.Strings(namespaces)
+	return append([]string{controllers.ControlPlaneNamespace}, namespaces...)
+}
+
+func (c *Collector) collectNetworkPolicies(ctx context.Context, b *bundle, slice *kubeslicev1beta1.Slice) error {
+	for _, ns := range sliceNamespaces(slice) {
+		netpols := &networkingv1.NetworkPolicyList{}
+		if err := c.Client.List(ctx, netpols, client.InNamespace(ns)); err != nil {
+			b.recordError("networkpolicies/"+ns, err)
+			continue
+		}
+		if len(netpols.Items) == 0 {
+			continue
+		}
+		for i := range netpols.Items {
+			sanitize(&netpols.Items[i])
+		}
+		if err := b.addJSON("networkpolicies/"+ns+".json", netpols.Items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectEvents adds the events of the slice, its gateways and their pods in the namespaces of the slice
+func (c *Collector) collectEvents(ctx context.Context, b *bundle, slice *kubeslicev1beta1.Slice, involved map[string]bool) error {
+	var events []corev1.Event
+	for _, ns := range sliceNamespaces(slice) {
+		list := &corev1.EventList{}
+		if err := c.Client.List(ctx, list, client.InNamespace(ns)); err != nil {
+			b.recordError("events/"+ns, err)
+			continue
+		}
+		for _, e := range list.Items {
+			// the events of the application namespaces are all about the slice
+			if ns != controllers.ControlPlaneNamespace || involved[e.InvolvedObject.Name] {
+				sanitize(&e)
+				events = append(events, e)
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastTimestamp.Before(&events[j].LastTimestamp)
+	})
+	return b.addJSON("events.json", events)
+}
+
+// sanitize drops the fields of the object that do not help debugging and may hold copies of its data
+func sanitize(obj client.Object) {
+	obj.SetManagedFields(nil)
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+		delete(annotations, corev1.LastAppliedConfigAnnotation)
+		obj.SetAnnotations(annotations)
+	}
+}
+
+const redacted = "[REDACTED]"
+
+var redactions = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`), redacted},
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), redacted},
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/-]+=*`), "${1}" + redacted},
+	{regexp.MustCompile(`(?i)("?[a-z_.-]*(?:password|passwd|secret|token|apikey|api_key|private_key|privatekey)"?\s*[:=]\s*"?)[^\s",}]+`), "${1}" + redacted},
+}
+
+// Redact masks private keys, tokens, passwords and secrets in data
+func Redact(data []byte) []byte {
+	for _, r := range redactions {
+		data = r.re.ReplaceAll(data, []byte(r.repl))
+	}
+	return data
+}
@@ -21,11 +21,17 @@ package featureflag
 import (
 	"os"
 	"strings"
+
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
 )
 
 // Check if a feature flag is enabled
-// for a feature xxx to be enabled, an environment variable FEATURE_XXX=true is expected
+// for a feature xxx to be enabled, an environment variable FEATURE_XXX=true is expected.
+// A flag set in the WorkerOperatorConfig takes precedence over the environment.
 func IsEnabled(feature string) bool {
+	if enabled, ok := operatorconfig.FeatureFlag(feature); ok {
+		return enabled
+	}
 	f := os.Getenv("FEATURE_" + strings.ToUpper(feature))
 	return strings.ToLower(f) == "true"
 }
@@ -19,7 +19,9 @@
 package featureflag_test
 
 import (
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
 	"github.com/kubeslice/worker-operator/pkg/featureflag"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
 	"os"
 	"testing"
 )
@@ -49,3 +51,19 @@ func TestFeatureFlag(t *testing.T) {
 	}
 
 }
+
+func TestFeatureFlagFromOperatorConfig(t *testing.T) {
+	os.Setenv("FEATURE_ABC", "true")
+	defer os.Unsetenv("FEATURE_ABC")
+	operatorconfig.Set(&kubeslicev1beta1.WorkerOperatorConfigSpec{
+		FeatureFlags: map[string]bool{"abc": false, "def": true},
+	})
+	defer operatorconfig.Set(nil)
+
+	if featureflag.IsEnabled("abc") {
+		t.Fatalf("expected feature abc to be disabled by the operator config")
+	}
+	if !featureflag.IsEnabled("DEF") {
+		t.Fatalf("expected feature def to be enabled by the operator config")
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"context"
 
 	edgeproto "github.com/kubeslice/slicegw-edge/pkg/edgeservice"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -46,7 +47,7 @@ func NewWorkerGatewayEdgeClientProvider() (*gwEdgeClient, error) {
 }
 
 func (e gwEdgeClient) UpdateSliceGwServiceMap(ctx context.Context, serverAddr string, gwSvcMap *SliceGwServiceMap) (*GwEdgeResponse, error) {
-	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), tracing.DialOption())
 	if err != nil {
 		return nil, err
 	}
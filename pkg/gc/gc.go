@@ -0,0 +1,387 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package gc sweeps the objects left behind by the slice, slice gateway and service discovery
+// controllers when their cleanup did not run, eg: the operator crashed during a delete or a finalizer
+// was removed by hand. An object is orphaned when the slice, slice gateway, serviceexport or
+// serviceimport it was created for, as recorded in its kubeslice labels, no longer exists. The sweeper
+// runs in report mode by default, where orphans are only logged and counted.
+package gc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kubeslice/kubeslice-monitoring/pkg/metrics"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	istiov1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Mode is the mode of the sweeper
+type Mode string
+
+const (
+	// ModeDisabled does not run the sweeper
+	ModeDisabled Mode = "disabled"
+	// ModeReport logs and counts the orphans without deleting them
+	ModeReport Mode = "report"
+	// ModeDelete deletes the orphans
+	ModeDelete Mode = "delete"
+)
+
+var (
+	// SweepMode is the mode of the sweeper: disabled, report or delete
+	SweepMode = Mode(utils.GetEnvOrDefault("ORPHAN_GC_MODE", string(ModeReport)))
+	// SweepInterval is the interval between two sweeps, eg: 10m
+	SweepInterval = utils.GetEnvOrDefault("ORPHAN_GC_INTERVAL", "10m")
+	// SweepGracePeriod is the minimum age of an object before it can be considered orphaned, so that
+	// objects created while their owner is being created or deleted are left to the controllers
+	SweepGracePeriod = utils.GetEnvOrDefault("ORPHAN_GC_GRACE_PERIOD", "10m")
+)
+
+const (
+	defaultSweepInterval    = 10 * time.Minute
+	defaultSweepGracePeriod = 10 * time.Minute
+
+	// label of the gateway deployments and services
+	sliceGwLabelKey = "kubeslice.io/slicegw"
+	// labels of the istio objects created for serviceexports and serviceimports
+	serviceLabelKey   = "kubeslice-service"
+	serviceNsLabelKey = "kubeslice-service-ns"
+)
+
+var log = logger.NewWrappedLogger().WithName("orphan-gc")
+
+// owner is the object an orphan candidate was created for
+type owner struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (o owner) String() string {
+	return o.Kind + " " + o.Namespace + "/" + o.Name
+}
+
+// Orphan is an object whose owner no longer exists
+type Orphan struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Owner     string `json:"owner"`
+
+	object client.Object
+}
+
+// candidate describes a kind of objects created by the controllers and how to find their owners
+type candidate struct {
+	kind string
+	list func() client.ObjectList
+	// namespace to list the objects in, all namespaces when empty
+	namespace string
+	// objects without any of these labels are ignored
+	labels []string
+	owners func(client.Object) []owner
+}
+
+// candidates are the kinds of objects swept. Deployments, services and secrets are only swept in the
+// control plane namespace, the application namespaces may contain workloads labelled by users.
+var candidates = []candidate{{
+	kind:      "Deployment",
+	list:      func() client.ObjectList { return &appsv1.DeploymentList{} },
+	namespace: controllers.ControlPlaneNamespace,
+	labels:    []string{sliceGwLabelKey, controllers.ApplicationNamespaceSelectorLabelKey},
+	owners:    sliceOrGatewayOwners,
+}, {
+	kind:      "Service",
+	list:      func() client.ObjectList { return &corev1.ServiceList{} },
+	namespace: controllers.ControlPlaneNamespace,
+	labels:    []string{sliceGwLabelKey, controllers.ApplicationNamespaceSelectorLabelKey},
+	owners:    sliceOrGatewayOwners,
+}, {
+	kind:      "Secret",
+	list:      func() client.ObjectList { return &corev1.SecretList{} },
+	namespace: controllers.ControlPlaneNamespace,
+	labels:    []string{controllers.SliceGatewaySelectorLabelKey},
+	owners:    sliceOrGatewayOwners,
+}, {
+	kind:   "NetworkPolicy",
+	list:   func() client.ObjectList { return &networkingv1.NetworkPolicyList{} },
+	labels: []string{controllers.ApplicationNamespaceSelectorLabelKey},
+	owners: networkPolicyOwners,
+}, {
+	kind:   "ServiceEntry",
+	list:   func() client.ObjectList { return &istiov1beta1.ServiceEntryList{} },
+	labels: []string{serviceLabelKey},
+	owners: serviceOwners,
+}, {
+	kind:   "VirtualService",
+	list:   func() client.ObjectList { return &istiov1beta1.VirtualServiceList{} },
+	labels: []string{serviceLabelKey},
+	owners: serviceOwners,
+}}
+
+// sliceOrGatewayOwners returns the slice gateway of a gateway object, or else the slice of the object
+func sliceOrGatewayOwners(obj client.Object) []owner {
+	labels := obj.GetLabels()
+	for _, key := range []string{sliceGwLabelKey, controllers.SliceGatewaySelectorLabelKey} {
+		if gw := labels[key]; gw != "" {
+			return []owner{{Kind: "SliceGateway", Namespace: controllers.ControlPlaneNamespace, Name: gw}}
+		}
+	}
+	if slice := labels[controllers.ApplicationNamespaceSelectorLabelKey]; slice != "" {
+		return []owner{{Kind: "Slice", Namespace: controllers.ControlPlaneNamespace, Name: slice}}
+	}
+	return nil
+}
+
+// networkPolicyOwners returns the slice of the network policies installed by the slice reconciler,
+// which are named after the slice and the application namespace
+func networkPolicyOwners(obj client.Object) []owner {
+	slice := obj.GetLabels()[controllers.ApplicationNamespaceSelectorLabelKey]
+	if slice == "" || obj.GetName() != slice+"-"+obj.GetNamespace() {
+		return nil
+	}
+	return []owner{{Kind: "Slice", Namespace: controllers.ControlPlaneNamespace, Name: slice}}
+}
+
+// serviceOwners returns the serviceexport and the serviceimport an istio object may belong to. Both use
+// the same labels, the object is orphaned when neither of them exists.
+func serviceOwners(obj client.Object) []owner {
+	labels := obj.GetLabels()
+	name, ns := labels[serviceLabelKey], labels[serviceNsLabelKey]
+	if name == "" || ns == "" {
+		return nil
+	}
+	return []owner{
+		{Kind: "ServiceExport", Namespace: ns, Name: name},
+		{Kind: "ServiceImport", Namespace: ns, Name: name},
+	}
+}
+
+// Sweeper periodically finds and deletes orphaned objects. It is run by the elected replica only.
+type Sweeper struct {
+	// Reader reads the objects from the api server, the owners must not be read from a stale cache
+	Reader client.Reader
+	// Client deletes the orphans
+	Client      client.Client
+	Mode        Mode
+	Interval    time.Duration
+	GracePeriod time.Duration
+
+	now            func() time.Time
+	gaugeOrphans   *prometheus.GaugeVec
+	counterDeleted *prometheus.CounterVec
+}
+
+// NewSweeper returns a sweeper configured from the environment
+func NewSweeper(reader client.Reader, c client.Client, mf metrics.MetricsFactory) *Sweeper {
+	interval, err := time.ParseDuration(SweepInterval)
+	if err != nil || interval <= 0 {
+		interval = defaultSweepInterval
+	}
+	gracePeriod, err := time.ParseDuration(SweepGracePeriod)
+	if err != nil || gracePeriod < 0 {
+		gracePeriod = defaultSweepGracePeriod
+	}
+	s := &Sweeper{
+		Reader:      reader,
+		Client:      c,
+		Mode:        SweepMode,
+		Interval:    interval,
+		GracePeriod: gracePeriod,
+	}
+	if mf != nil {
+		s.gaugeOrphans = mf.NewGauge("orphaned_resources", "Orphaned objects found by the last sweep", []string{"kind"})
+		s.counterDeleted = mf.NewCounter("orphaned_resources_deleted", "Orphaned objects deleted by the sweeper", []string{"kind"})
+	}
+	return s
+}
+
+// Start runs a sweep every interval until the context is done
+func (s *Sweeper) Start(ctx context.Context) error {
+	log.Info("starting orphan sweeper", "mode", s.Mode, "interval", s.Interval, "gracePeriod", s.GracePeriod)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if _, err := s.Sweep(ctx); err != nil {
+			log.Error(err, "orphan sweep failed")
+		}
+	}, s.Interval)
+	return nil
+}
+
+// NeedLeaderElection runs the sweeper on the elected replica only
+func (s *Sweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// Sweep finds the orphaned objects and deletes them in delete mode. It returns the orphans found.
+func (s *Sweeper) Sweep(ctx context.Context) ([]Orphan, error) {
+	orphans, err := s.findOrphans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]int{}
+	for _, c := range candidates {
+		found[c.kind] = 0
+	}
+	var errs []error
+	for _, o := range orphans {
+		found[o.Kind]++
+		if s.Mode != ModeDelete {
+			log.Info("found orphaned object", "kind", o.Kind, "namespace", o.Namespace, "name", o.Name, "owner", o.Owner)
+			continue
+		}
+		uid := o.object.GetUID()
+		err := s.Client.Delete(ctx, o.object, client.PropagationPolicy(metav1.DeletePropagationBackground),
+			client.Preconditions{UID: &uid})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete %s %s/%s: %w", o.Kind, o.Namespace, o.Name, err))
+			continue
+		}
+		log.Info("deleted orphaned object", "kind", o.Kind, "namespace", o.Namespace, "name", o.Name, "owner", o.Owner)
+		if s.counterDeleted != nil {
+			s.counterDeleted.WithLabelValues(o.Kind).Inc()
+		}
+	}
+	if s.gaugeOrphans != nil {
+		for kind, n := range found {
+			s.gaugeOrphans.WithLabelValues(kind).Set(float64(n))
+		}
+	}
+	if len(errs) > 0 {
+		return orphans, fmt.Errorf("failed to delete %d orphaned objects, first error: %w", len(errs), errs[0])
+	}
+	return orphans, nil
+}
+
+func (s *Sweeper) findOrphans(ctx context.Context) ([]Orphan, error) {
+	now := time.Now()
+	if s.now != nil {
+		now = s.now()
+	}
+	exists := map[owner]bool{}
+	orphans := []Orphan{}
+	for _, c := range candidates {
+		list := c.list()
+		opts := []client.ListOption{}
+		if c.namespace != "" {
+			opts = append(opts, client.InNamespace(c.namespace))
+		}
+		if err := s.Reader.List(ctx, list, opts...); err != nil {
+			if meta.IsNoMatchError(err) {
+				// istio is not installed
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s objects: %w", c.kind, err)
+		}
+		objs, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			obj, ok := o.(client.Object)
+			if !ok || !hasAnyLabel(obj, c.labels) || !obj.GetDeletionTimestamp().IsZero() ||
+				now.Sub(obj.GetCreationTimestamp().Time) < s.GracePeriod {
+				continue
+			}
+			owners := c.owners(obj)
+			if len(owners) == 0 {
+				continue
+			}
+			orphaned := true
+			for _, ow := range owners {
+				found, ok := exists[ow]
+				if !ok {
+					if found, err = s.ownerExists(ctx, ow); err != nil {
+						return nil, err
+					}
+					exists[ow] = found
+				}
+				if found {
+					orphaned = false
+					break
+				}
+			}
+			if orphaned {
+				orphans = append(orphans, Orphan{
+					Kind:      c.kind,
+					Namespace: obj.GetNamespace(),
+					Name:      obj.GetName(),
+					Owner:     owners[0].String(),
+					object:    obj,
+				})
+			}
+		}
+	}
+	sort.SliceStable(orphans, func(i, j int) bool {
+		if orphans[i].Kind != orphans[j].Kind {
+			return orphans[i].Kind < orphans[j].Kind
+		}
+		if orphans[i].Namespace != orphans[j].Namespace {
+			return orphans[i].Namespace < orphans[j].Namespace
+		}
+		return orphans[i].Name < orphans[j].Name
+	})
+	return orphans, nil
+}
+
+func (s *Sweeper) ownerExists(ctx context.Context, o owner) (bool, error) {
+	var obj client.Object
+	switch o.Kind {
+	case "Slice":
+		obj = &kubeslicev1beta1.Slice{}
+	case "SliceGateway":
+		obj = &kubeslicev1beta1.SliceGateway{}
+	case "ServiceExport":
+		obj = &kubeslicev1beta1.ServiceExport{}
+	case "ServiceImport":
+		obj = &kubeslicev1beta1.ServiceImport{}
+	default:
+		return false, fmt.Errorf("unknown owner kind %s", o.Kind)
+	}
+	err := s.Reader.Get(ctx, types.NamespacedName{Namespace: o.Namespace, Name: o.Name}, obj)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func hasAnyLabel(obj client.Object, keys []string) bool {
+	labels := obj.GetLabels()
+	for _, key := range keys {
+		if _, ok := labels[key]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package gc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	istiov1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newSweepTestObjects(now time.Time) []client.Object {
+	old := metav1.NewTime(now.Add(-time.Hour))
+	young := metav1.NewTime(now.Add(-time.Minute))
+	meta := func(ns, name string, created metav1.Time, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: ns, Name: name, CreationTimestamp: created, Labels: labels}
+	}
+	cp := controllers.ControlPlaneNamespace
+	return []client.Object{
+		&kubeslicev1beta1.Slice{ObjectMeta: meta(cp, "green", old, nil)},
+		&kubeslicev1beta1.SliceGateway{ObjectMeta: meta(cp, "green-w1-w2", old, nil)},
+		&kubeslicev1beta1.ServiceExport{ObjectMeta: meta("iperf", "iperf-server", old, nil)},
+		// gateway of a deleted slice gateway
+		&appsv1.Deployment{ObjectMeta: meta(cp, "green-w1-w3-0-0", old,
+			map[string]string{"kubeslice.io/slice": "green", "kubeslice.io/slicegw": "green-w1-w3"})},
+		&appsv1.Deployment{ObjectMeta: meta(cp, "green-w1-w2-0-0", old,
+			map[string]string{"kubeslice.io/slice": "green", "kubeslice.io/slicegw": "green-w1-w2"})},
+		// too young to be swept
+		&appsv1.Deployment{ObjectMeta: meta(cp, "green-w1-w4-0-0", young,
+			map[string]string{"kubeslice.io/slice": "green", "kubeslice.io/slicegw": "green-w1-w4"})},
+		&corev1.Secret{ObjectMeta: meta(cp, "green-w1-w3", old, map[string]string{"kubeslice.io/slice-gw": "green-w1-w3"})},
+		&corev1.Service{ObjectMeta: meta(cp, "vl3-slice-router-red", old, map[string]string{"kubeslice.io/slice": "red"})},
+		&networkingv1.NetworkPolicy{ObjectMeta: meta("iperf", "red-iperf", old, map[string]string{"kubeslice.io/slice": "red"})},
+		&networkingv1.NetworkPolicy{ObjectMeta: meta("iperf", "green-iperf", old, map[string]string{"kubeslice.io/slice": "green"})},
+		// not installed by the slice reconciler
+		&networkingv1.NetworkPolicy{ObjectMeta: meta("iperf", "deny-all", old, map[string]string{"kubeslice.io/slice": "red"})},
+		&istiov1beta1.ServiceEntry{ObjectMeta: meta(cp, "iperf-server-w2", old,
+			map[string]string{"kubeslice-service": "iperf-server", "kubeslice-service-ns": "iperf", "kubeslice-slice": "green"})},
+		&istiov1beta1.VirtualService{ObjectMeta: meta("iperf", "iperf-client", old,
+			map[string]string{"kubeslice-service": "iperf-client", "kubeslice-service-ns": "iperf", "kubeslice-slice": "green"})},
+	}
+}
+
+func newTestSweeper(mode Mode, now time.Time) *Sweeper {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(kubeslicev1beta1.AddToScheme(scheme))
+	utilruntime.Must(istiov1beta1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newSweepTestObjects(now)...).Build()
+	s := NewSweeper(c, c, nil)
+	s.Mode = mode
+	s.GracePeriod = 10 * time.Minute
+	s.now = func() time.Time { return now }
+	return s
+}
+
+var expectedOrphans = []string{
+	"Deployment kubeslice-system/green-w1-w3-0-0",
+	"NetworkPolicy iperf/red-iperf",
+	"Secret kubeslice-system/green-w1-w3",
+	"Service kubeslice-system/vl3-slice-router-red",
+	"VirtualService iperf/iperf-client",
+}
+
+func orphanNames(orphans []Orphan) []string {
+	names := []string{}
+	for _, o := range orphans {
+		names = append(names, o.Kind+" "+o.Namespace+"/"+o.Name)
+	}
+	return names
+}
+
+func TestSweepReport(t *testing.T) {
+	now := time.Now()
+	s := newTestSweeper(ModeReport, now)
+	orphans, err := s.Sweep(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if got := orphanNames(orphans); !reflect.DeepEqual(got, expectedOrphans) {
+		t.Errorf("unexpected orphans %v, expected %v", got, expectedOrphans)
+	}
+	// nothing is deleted in report mode
+	for _, o := range orphans {
+		if err := s.Client.Get(context.Background(), client.ObjectKeyFromObject(o.object), o.object); err != nil {
+			t.Errorf("%s %s/%s was deleted in report mode: %v", o.Kind, o.Namespace, o.Name, err)
+		}
+	}
+}
+
+func TestSweepDelete(t *testing.T) {
+	now := time.Now()
+	s := newTestSweeper(ModeDelete, now)
+	orphans, err := s.Sweep(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if got := orphanNames(orphans); !reflect.DeepEqual(got, expectedOrphans) {
+		t.Errorf("unexpected orphans %v, expected %v", got, expectedOrphans)
+	}
+	for _, o := range orphans {
+		err := s.Client.Get(context.Background(), client.ObjectKeyFromObject(o.object), o.object)
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("%s %s/%s was not deleted: %v", o.Kind, o.Namespace, o.Name, err)
+		}
+	}
+	kept := &appsv1.Deployment{}
+	if err := s.Client.Get(context.Background(), client.ObjectKey{Namespace: controllers.ControlPlaneNamespace, Name: "green-w1-w2-0-0"}, kept); err != nil {
+		t.Error("gateway deployment with an existing owner was deleted: ", err)
+	}
+
+	orphans, err = s.Sweep(context.Background())
+	if err != nil || len(orphans) != 0 {
+		t.Errorf("unexpected orphans after delete %v: %v", orphanNames(orphans), err)
+	}
+}
@@ -25,6 +25,8 @@ import (
 	sidecar "github.com/kubeslice/gateway-sidecar/pkg/sidecar/sidecarpb"
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
 	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/qos"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -64,7 +66,7 @@ func NewWorkerGWSidecarClientProvider() (*gwSidecarClient, error) {
 }
 
 func (worker gwSidecarClient) GetSliceGwRemotePodName(ctx context.Context, gwRemoteVpnIP string, serverAddr string) (string, error) {
-	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), tracing.DialOption())
 	if err != nil {
 		return "", err
 	}
@@ -95,7 +97,7 @@ func getTunnelState(tunnelState sidecar.TunnelStatusType) string {
 
 // GetStatus retrieves sidecar status
 func (worker gwSidecarClient) GetStatus(ctx context.Context, serverAddr string) (*GwStatus, error) {
-	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), tracing.DialOption())
 	if err != nil {
 		return nil, err
 	}
@@ -138,7 +140,7 @@ func (worker gwSidecarClient) GetStatus(ctx context.Context, serverAddr string)
 // SendConnectionContext sends connection context info to sidecar
 func (worker gwSidecarClient) SendConnectionContext(ctx context.Context, serverAddr string, gwConnCtx *GwConnectionContext) error {
 	log := logger.FromContext(ctx)
-	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), tracing.DialOption())
 	if err != nil {
 		return err
 	}
@@ -159,7 +161,12 @@ func (worker gwSidecarClient) SendConnectionContext(ctx context.Context, serverA
 }
 
 func (worker gwSidecarClient) UpdateSliceQosProfile(ctx context.Context, serverAddr string, slice *kubeslicev1beta1.Slice) error {
-	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// the profile is validated before dialing, an invalid profile fails on every node alike
+	discipline, err := qos.Resolve(slice.Status.SliceConfig.QosProfileDetails)
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), tracing.DialOption())
 	if err != nil {
 		return err
 	}
@@ -167,15 +174,12 @@ func (worker gwSidecarClient) UpdateSliceQosProfile(ctx context.Context, serverA
 
 	client := sidecar.NewGwSidecarServiceClient(conn)
 
-	// TODO change later if we add more TC types
-	tcType := sidecar.TcType_BANDWIDTH_CONTROL
-
 	qop := &sidecar.SliceQosProfile{
 		SliceName:      slice.Name,
 		SliceId:        slice.Name,
 		QosProfileName: slice.Name,
-		TcType:         tcType,
-		ClassType:      sidecar.ClassType_HTB,
+		TcType:         sidecar.TcType(discipline.TcTypeValue),
+		ClassType:      sidecar.ClassType(discipline.ClassTypeValue),
 		BwCeiling:      uint32(slice.Status.SliceConfig.QosProfileDetails.BandwidthCeilingKbps),
 		BwGuaranteed:   uint32(slice.Status.SliceConfig.QosProfileDetails.BandwidthGuaranteedKbps),
 		Priority:       uint32(slice.Status.SliceConfig.QosProfileDetails.Priority),
@@ -0,0 +1,175 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package health provides the readiness checks of the operator. Each check is registered with its own
+// name so that it can be queried individually through /readyz/<name>. The sidecar checks are served by
+// the StatusHandler of the metrics server and do not take the operator out of service.
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	controllerv1alpha1 "github.com/kubeslice/apis/pkg/controller/v1alpha1"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
+	"github.com/kubeslice/worker-operator/pkg/utils"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+var (
+	// HubReadinessGracePeriod is how long the hub may be unreachable before the hub check fails, eg: 5m.
+	// Slices keep running during short hub outages, so the operator should not be taken out of service for them.
+	HubReadinessGracePeriod = utils.GetEnvOrDefault("HUB_READINESS_GRACE_PERIOD", "5m")
+	// SidecarReadinessChecks enables the check of the router and gateway sidecars of every slice
+	SidecarReadinessChecks = utils.GetEnvOrDefault("READINESS_SIDECAR_CHECKS", "false") == "true"
+)
+
+const (
+	defaultHubReadinessGracePeriod = 5 * time.Minute
+	sidecarGrpcPort                = "5000"
+	// name prefix of the slice router service, the router sidecar listens on it
+	sliceRouterNamePrefix = "vl3-slice-router-"
+	checkTimeout          = 2 * time.Second
+)
+
+// HubConnection reports the state of the connection to the hub cluster
+type HubConnection interface {
+	Status() (hub.ConnectionState, time.Time, error)
+}
+
+// HubChecker fails when the hub has not been connected for longer than the grace period
+func HubChecker(conn HubConnection) healthz.Checker {
+	gracePeriod, err := time.ParseDuration(HubReadinessGracePeriod)
+	if err != nil || gracePeriod < 0 {
+		gracePeriod = defaultHubReadinessGracePeriod
+	}
+	return func(_ *http.Request) error {
+		state, since, lastErr := conn.Status()
+		if state == hub.HubConnected {
+			return nil
+		}
+		if state == hub.HubConnectionUnknown {
+			return errors.New("hub connection has not been probed yet")
+		}
+		if time.Since(since) < gracePeriod {
+			return nil
+		}
+		return fmt.Errorf("hub is %s since %s: %v", state, since.Format(time.RFC3339), lastErr)
+	}
+}
+
+// CacheSyncChecker fails until the informer caches of the manager have synced
+func CacheSyncChecker(c cache.Cache) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+		defer cancel()
+		if !c.WaitForCacheSync(ctx) {
+			return errors.New("informer caches have not synced")
+		}
+		return nil
+	}
+}
+
+// WebhookChecker fails until the webhook server is serving, and when its serving certificate is
+// missing, invalid or expired
+func WebhookChecker(started healthz.Checker, certDir string) healthz.Checker {
+	return func(req *http.Request) error {
+		if err := started(req); err != nil {
+			return err
+		}
+		pair, err := tls.LoadX509KeyPair(filepath.Join(certDir, "tls.crt"), filepath.Join(certDir, "tls.key"))
+		if err != nil {
+			return fmt.Errorf("invalid webhook certificate: %v", err)
+		}
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("invalid webhook certificate: %v", err)
+		}
+		now := time.Now()
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return fmt.Errorf("webhook certificate is not valid at %s, valid from %s to %s",
+				now.Format(time.RFC3339), cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+		}
+		return nil
+	}
+}
+
+// SidecarChecker fails when the router sidecar or a gateway sidecar of a slice cannot be reached
+func SidecarChecker(c client.Client) healthz.Checker {
+	return sidecarChecker(c, func(ctx context.Context, addr string) error {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+}
+
+func sidecarChecker(c client.Client, dial func(ctx context.Context, addr string) error) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+		defer cancel()
+
+		slices := &kubeslicev1beta1.SliceList{}
+		if err := c.List(ctx, slices, client.InNamespace(controllers.ControlPlaneNamespace)); err != nil {
+			return err
+		}
+		unreachable := []string{}
+		for _, slice := range slices.Items {
+			if slice.Status.SliceConfig == nil || slice.Status.SliceConfig.SliceOverlayNetworkDeploymentMode == controllerv1alpha1.NONET {
+				continue
+			}
+			addr := net.JoinHostPort(sliceRouterNamePrefix+slice.Name, sidecarGrpcPort)
+			if err := dial(ctx, addr); err != nil {
+				unreachable = append(unreachable, fmt.Sprintf("slice %s router %s", slice.Name, addr))
+			}
+		}
+
+		gateways := &kubeslicev1beta1.SliceGatewayList{}
+		if err := c.List(ctx, gateways, client.InNamespace(controllers.ControlPlaneNamespace)); err != nil {
+			return err
+		}
+		for _, gw := range gateways.Items {
+			for _, pod := range gw.Status.GatewayPodStatus {
+				if pod == nil || pod.PodIP == "" {
+					continue
+				}
+				addr := net.JoinHostPort(pod.PodIP, sidecarGrpcPort)
+				if err := dial(ctx, addr); err != nil {
+					unreachable = append(unreachable, fmt.Sprintf("slice %s gateway %s sidecar %s", gw.Spec.SliceName, pod.PodName, addr))
+				}
+			}
+		}
+		if len(unreachable) > 0 {
+			return fmt.Errorf("unreachable sidecars: %s", strings.Join(unreachable, ", "))
+		}
+		return nil
+	}
+}
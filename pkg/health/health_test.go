@@ -0,0 +1,206 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	controllerv1alpha1 "github.com/kubeslice/apis/pkg/controller/v1alpha1"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeHubConnection struct {
+	state hub.ConnectionState
+	since time.Time
+}
+
+func (f *fakeHubConnection) Status() (hub.ConnectionState, time.Time, error) {
+	return f.state, f.since, errors.New("connection refused")
+}
+
+func TestHubChecker(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/readyz/hub", nil)
+	cases := []struct {
+		desc      string
+		conn      *fakeHubConnection
+		expectErr bool
+	}{
+		{"connected", &fakeHubConnection{hub.HubConnected, time.Now().Add(-time.Hour)}, false},
+		{"not probed yet", &fakeHubConnection{hub.HubConnectionUnknown, time.Now()}, true},
+		{"disconnected within grace period", &fakeHubConnection{hub.HubDisconnected, time.Now().Add(-time.Minute)}, false},
+		{"disconnected after grace period", &fakeHubConnection{hub.HubDisconnected, time.Now().Add(-time.Hour)}, true},
+		{"unauthorized after grace period", &fakeHubConnection{hub.HubUnauthorized, time.Now().Add(-time.Hour)}, true},
+	}
+	for _, tc := range cases {
+		err := HubChecker(tc.conn)(req)
+		if (err != nil) != tc.expectErr {
+			t.Errorf("%s: expected error %t, got %v", tc.desc, tc.expectErr, err)
+		}
+	}
+}
+
+func TestWebhookChecker(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/readyz/webhook", nil)
+	started := func(_ *http.Request) error { return nil }
+	notStarted := func(_ *http.Request) error { return errors.New("webhook server has not been started yet") }
+
+	certDir := t.TempDir()
+	if err := WebhookChecker(started, certDir)(req); err == nil {
+		t.Fatal("expected error for missing certificate")
+	}
+
+	writeTestCert(t, certDir, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+	if err := WebhookChecker(started, certDir)(req); err == nil || !strings.Contains(err.Error(), "not valid") {
+		t.Fatal("expected error for expired certificate, got ", err)
+	}
+
+	writeTestCert(t, certDir, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err := WebhookChecker(started, certDir)(req); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if err := WebhookChecker(notStarted, certDir)(req); err == nil {
+		t.Fatal("expected error when the webhook server is not started")
+	}
+}
+
+func TestSidecarChecker(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(kubeslicev1beta1.AddToScheme(scheme))
+	slice := &kubeslicev1beta1.Slice{
+		ObjectMeta: metav1.ObjectMeta{Name: "red", Namespace: controllers.ControlPlaneNamespace},
+		Status: kubeslicev1beta1.SliceStatus{SliceConfig: &kubeslicev1beta1.SliceConfig{
+			SliceOverlayNetworkDeploymentMode: controllerv1alpha1.SINGLENET,
+		}},
+	}
+	noNetSlice := &kubeslicev1beta1.Slice{
+		ObjectMeta: metav1.ObjectMeta{Name: "blue", Namespace: controllers.ControlPlaneNamespace},
+		Status: kubeslicev1beta1.SliceStatus{SliceConfig: &kubeslicev1beta1.SliceConfig{
+			SliceOverlayNetworkDeploymentMode: controllerv1alpha1.NONET,
+		}},
+	}
+	gw := &kubeslicev1beta1.SliceGateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "red-worker-1-worker-2", Namespace: controllers.ControlPlaneNamespace},
+		Spec:       kubeslicev1beta1.SliceGatewaySpec{SliceName: "red"},
+		Status: kubeslicev1beta1.SliceGatewayStatus{GatewayPodStatus: []*kubeslicev1beta1.GwPodInfo{
+			{PodName: "red-gw-0", PodIP: "10.0.0.1"},
+			{PodName: "red-gw-1", PodIP: "10.0.0.2"},
+		}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(slice, noNetSlice, gw).Build()
+	req, _ := http.NewRequest(http.MethodGet, "/readyz/sidecars", nil)
+
+	dialed := map[string]bool{}
+	reachable := func(_ context.Context, addr string) error {
+		dialed[addr] = true
+		return nil
+	}
+	if err := sidecarChecker(c, reachable)(req); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	for _, addr := range []string{"vl3-slice-router-red:5000", "10.0.0.1:5000", "10.0.0.2:5000"} {
+		if !dialed[addr] {
+			t.Error("expected sidecar to be checked: ", addr)
+		}
+	}
+	if dialed["vl3-slice-router-blue:5000"] {
+		t.Error("expected router of a slice without network not to be checked")
+	}
+
+	gwDown := func(_ context.Context, addr string) error {
+		if addr == "10.0.0.2:5000" {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	err := sidecarChecker(c, gwDown)(req)
+	if err == nil || !strings.Contains(err.Error(), "red-gw-1") {
+		t.Fatal("expected unreachable gateway sidecar to be reported, got ", err)
+	}
+}
+
+func writeTestCert(t *testing.T, dir string, notBefore, notAfter time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "kubeslice-webhook-service.kubeslice-system.svc"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err := os.WriteFile(filepath.Join(dir, "tls.crt"), certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tls.key"), keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatusHandler(t *testing.T) {
+	h := NewStatusHandler()
+	h.AddCheck("sidecars", func(_ *http.Request) error { return nil })
+	h.AddCheck("sidecars-blue", func(_ *http.Request) error { return errors.New("unreachable sidecars") })
+	cases := []struct {
+		path   string
+		status int
+	}{
+		{"/readyz/sidecars", http.StatusOK},
+		{"/readyz/sidecars-blue", http.StatusInternalServerError},
+		{"/readyz/hub", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.path, tc.status, rec.Code)
+		}
+	}
+}
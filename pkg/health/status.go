@@ -0,0 +1,61 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package health
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// StatusPath is the path of the status checks on the metrics server
+const StatusPath = "/readyz/"
+
+// StatusHandler serves the checks that must not take the operator out of service, such as the
+// sidecar checks, on /readyz/<name> of the metrics server. The readiness probe only hits the
+// health probe server: an operator that is not ready leaves the endpoints of the pod webhook, which
+// fails the pod and workload creations of the whole cluster.
+type StatusHandler struct {
+	mu     sync.RWMutex
+	checks map[string]healthz.Checker
+}
+
+// NewStatusHandler returns a StatusHandler without checks
+func NewStatusHandler() *StatusHandler {
+	return &StatusHandler{checks: map[string]healthz.Checker{}}
+}
+
+// AddCheck serves the checker on /readyz/<name>
+func (h *StatusHandler) AddCheck(name string, checker healthz.Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = checker
+}
+
+func (h *StatusHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.mu.RLock()
+	checks := make(map[string]healthz.Checker, len(h.checks))
+	for name, checker := range h.checks {
+		checks[name] = checker
+	}
+	h.mu.RUnlock()
+	http.StripPrefix(strings.TrimSuffix(StatusPath, "/"), &healthz.Handler{Checks: checks}).ServeHTTP(w, req)
+}
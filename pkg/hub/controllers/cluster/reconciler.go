@@ -23,15 +23,19 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strconv"
 	"time"
 
 	hubv1alpha1 "github.com/kubeslice/apis/pkg/controller/v1alpha1"
 	"github.com/kubeslice/kubeslice-monitoring/pkg/events"
 	"github.com/kubeslice/kubeslice-monitoring/pkg/metrics"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
 	"github.com/kubeslice/worker-operator/controllers"
 	ossEvents "github.com/kubeslice/worker-operator/events"
 	"github.com/kubeslice/worker-operator/pkg/cluster"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
 	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/mtu"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -67,6 +71,8 @@ type Reconciler struct {
 	gaugeComponentUp *prometheus.GaugeVec
 
 	ReconcileInterval time.Duration
+	// Project is the hub project of the cluster object. Only the primary project deregisters the worker.
+	Project hub.Project
 }
 
 func NewReconciler(c client.Client, mc client.Client, er *events.EventRecorder, mf metrics.MetricsFactory) *Reconciler {
@@ -151,6 +157,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return res, err
 	}
 	utils.RecordEvent(ctx, r.EventRecorder, cr, nil, ossEvents.EventClusterNodeIpUpdateSuccessful, controllerName)
+	if err := r.updateUnderlayMTU(ctx, cr); err != nil {
+		log.Error(err, "unable to update underlay mtu of the cluster")
+	}
 	// Update dashboard creds if it hasn't already (only one time event)
 	if !r.isDashboardCredsUpdated(ctx, cr) {
 		if err := r.updateDashboardCreds(ctx, cr); err != nil {
@@ -485,6 +494,26 @@ func (r *Reconciler) updateNodeIps(ctx context.Context, cr *hubv1alpha1.Cluster)
 	return ctrl.Result{}, nil, false
 }
 
+// updateUnderlayMTU publishes the underlay MTU of the gateway nodes on the cluster object, the gateways of
+// the other clusters size their tunnels to the smaller MTU of both ends
+func (r *Reconciler) updateUnderlayMTU(ctx context.Context, cr *hubv1alpha1.Cluster) error {
+	underlay, err := mtu.Underlay(ctx, r.MeshClient)
+	if err != nil {
+		return err
+	}
+	value := strconv.Itoa(underlay)
+	if cr.Annotations[mtu.UnderlayAnnotation] == value {
+		return nil
+	}
+	base := cr.DeepCopy()
+	if cr.Annotations == nil {
+		cr.Annotations = map[string]string{}
+	}
+	cr.Annotations[mtu.UnderlayAnnotation] = value
+	logger.FromContext(ctx).Info("Updating underlay mtu of the cluster", "mtu", underlay)
+	return r.Patch(ctx, cr, client.MergeFrom(base))
+}
+
 // total -> external ip list of nodes in the k8s cluster
 // current -> ip list present in nodeIPs of cluster cr
 func validatenodeips(total, current []string) bool {
@@ -551,7 +580,7 @@ func (r *Reconciler) updateDashboardCreds(ctx context.Context, cr *hubv1alpha1.C
 	hubSecret := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      secretName,
-			Namespace: os.Getenv("HUB_PROJECT_NAMESPACE"),
+			Namespace: cr.Namespace,
 		},
 		Data: secretData,
 	}
@@ -606,6 +635,25 @@ func (r *Reconciler) handleClusterDeletion(cluster *hubv1alpha1.Cluster, ctx con
 			}
 		}
 	} else {
+		// The worker stays installed for its other projects when it leaves a secondary project,
+		// only the slices of the project are removed from it
+		if !r.Project.IsPrimary() {
+			remaining, err := r.deleteProjectSlices(ctx)
+			if err != nil {
+				log.Error(err, "unable to delete the slices of the project", "project", r.Project.Name)
+				return true, reconcile.Result{}, err
+			}
+			if remaining > 0 {
+				log.Info("waiting for the slices of the project to be deleted", "project", r.Project.Name, "slices", remaining)
+				return true, reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+			}
+			if controllerutil.RemoveFinalizer(cluster, clusterDeregisterFinalizer) {
+				if err := r.Update(ctx, cluster); err != nil {
+					return true, reconcile.Result{}, err
+				}
+			}
+			return true, reconcile.Result{}, nil
+		}
 		// The object is being deleted
 		if controllerutil.ContainsFinalizer(cluster, clusterDeregisterFinalizer) &&
 			cluster.Status.RegistrationStatus != hubv1alpha1.RegistrationStatusDeregisterInProgress &&
@@ -631,3 +679,27 @@ func (r *Reconciler) handleClusterDeletion(cluster *hubv1alpha1.Cluster, ctx con
 	}
 	return false, reconcile.Result{}, nil
 }
+
+// deleteProjectSlices deletes the slices of the project from the worker cluster and returns the number of
+// slices of the project that are still present
+func (r *Reconciler) deleteProjectSlices(ctx context.Context) (int, error) {
+	slices := &kubeslicev1beta1.SliceList{}
+	if err := r.MeshClient.List(ctx, slices, client.InNamespace(controllers.ControlPlaneNamespace)); err != nil {
+		return 0, err
+	}
+	remaining := 0
+	for i := range slices.Items {
+		slice := &slices.Items[i]
+		if !r.Project.BelongsTo(slice) {
+			continue
+		}
+		remaining++
+		if !slice.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := r.MeshClient.Delete(ctx, slice); err != nil && !apierrors.IsNotFound(err) {
+			return remaining, err
+		}
+	}
+	return remaining, nil
+}
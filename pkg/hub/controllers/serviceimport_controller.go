@@ -29,6 +29,7 @@ import (
 	"github.com/kubeslice/worker-operator/controllers"
 	sliceController "github.com/kubeslice/worker-operator/controllers/slice"
 	ossEvents "github.com/kubeslice/worker-operator/events"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
 	"github.com/kubeslice/worker-operator/pkg/logger"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 
@@ -46,6 +47,8 @@ type ServiceImportReconciler struct {
 	client.Client
 	MeshClient    client.Client
 	EventRecorder *events.EventRecorder
+	// Project maps the names of the slices in the hub project to the worker cluster
+	Project hub.Project
 }
 
 var svcimFinalizer = "controller.kubeslice.io/hubWorkerServiceImport-finalizer"
@@ -94,17 +97,17 @@ func getMeshServiceImportEpList(svcim *spokev1alpha1.WorkerServiceImport) []kube
 	return epList
 }
 
-func getMeshServiceImportObj(svcim *spokev1alpha1.WorkerServiceImport) *kubeslicev1beta1.ServiceImport {
+func getMeshServiceImportObj(svcim *spokev1alpha1.WorkerServiceImport, sliceName string) *kubeslicev1beta1.ServiceImport {
 	return &kubeslicev1beta1.ServiceImport{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      svcim.Spec.ServiceName,
 			Namespace: svcim.Spec.ServiceNamespace,
 			Labels: map[string]string{
-				controllers.ApplicationNamespaceSelectorLabelKey: svcim.Spec.SliceName,
+				controllers.ApplicationNamespaceSelectorLabelKey: sliceName,
 			},
 		},
 		Spec: kubeslicev1beta1.ServiceImportSpec{
-			Slice:   svcim.Spec.SliceName,
+			Slice:   sliceName,
 			DNSName: svcim.Spec.ServiceName + "." + svcim.Spec.ServiceNamespace + ".svc.slice.local",
 			Ports:   getMeshServiceImportPortList(svcim),
 			Aliases: svcim.Spec.Aliases,
@@ -136,7 +139,7 @@ func (r *ServiceImportReconciler) Reconcile(ctx context.Context, req reconcile.R
 		return result, err
 	}
 
-	sliceName := svcim.Spec.SliceName
+	sliceName := r.Project.LocalName(svcim.Spec.SliceName)
 	meshSlice := &kubeslicev1beta1.Slice{}
 	sliceRef := client.ObjectKey{
 		Name:      sliceName,
@@ -152,7 +155,8 @@ func (r *ServiceImportReconciler) Reconcile(ctx context.Context, req reconcile.R
 	}
 
 	// vpc access ns
-	vpcAccessNamespaceName := fmt.Sprintf(sliceController.VPC_NS_FMT, sliceName)
+	// the name of the vpc access namespace is the same on every cluster of the slice
+	vpcAccessNamespaceName := fmt.Sprintf(sliceController.VPC_NS_FMT, svcim.Spec.SliceName)
 	if svcim.Spec.ServiceNamespace == vpcAccessNamespaceName {
 		namespace := &corev1.Namespace{}
 		err := r.MeshClient.Get(ctx, types.NamespacedName{
@@ -213,7 +217,7 @@ func (r *ServiceImportReconciler) getMeshServiceImport(ctx context.Context, svci
 	}, meshSvcIm)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			meshSvcIm = getMeshServiceImportObj(svcim)
+			meshSvcIm = getMeshServiceImportObj(svcim, r.Project.LocalName(svcim.Spec.SliceName))
 			err = r.MeshClient.Create(ctx, meshSvcIm)
 			if err != nil {
 				log.Error(err, "unable to create service import in spoke cluster", "serviceimport", svcim.Name)
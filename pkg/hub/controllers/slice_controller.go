@@ -21,6 +21,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -30,7 +31,9 @@ import (
 	"github.com/kubeslice/kubeslice-monitoring/pkg/metrics"
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
 	ossEvents "github.com/kubeslice/worker-operator/events"
+	"github.com/kubeslice/worker-operator/pkg/cluster"
 	"github.com/kubeslice/worker-operator/pkg/gwsidecar"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
 	"github.com/kubeslice/worker-operator/pkg/logger"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 	"github.com/prometheus/client_golang/prometheus"
@@ -126,6 +129,8 @@ type SliceReconciler struct {
 	MeshClient        client.Client
 	EventRecorder     *events.EventRecorder
 	ReconcileInterval time.Duration
+	// Project maps the names of the slices in the hub project to the worker cluster
+	Project hub.Project
 
 	// metrics
 	counterSliceCreated        *prometheus.CounterVec
@@ -160,7 +165,7 @@ func (r *SliceReconciler) Reconcile(ctx context.Context, req reconcile.Request)
 
 	log.Info("got slice from hub", "slice", slice.Name)
 	debuglog.Info("got slice from hub", "slice", slice)
-	sliceName := slice.Spec.SliceName
+	sliceName := r.Project.LocalName(slice.Spec.SliceName)
 	*r.EventRecorder = (*r.EventRecorder).WithSlice(sliceName)
 	requeue, result, err := r.handleSliceDeletion(slice, ctx, req)
 	if requeue {
@@ -186,6 +191,7 @@ func (r *SliceReconciler) Reconcile(ctx context.Context, req reconcile.Request)
 				},
 				Spec: kubeslicev1beta1.SliceSpec{},
 			}
+			r.Project.SetProjectAnnotations(s, slice.Spec.SliceName)
 
 			err = r.MeshClient.Create(ctx, s)
 			if err != nil {
@@ -228,6 +234,10 @@ func (r *SliceReconciler) Reconcile(ctx context.Context, req reconcile.Request)
 			delete(meshSlice.ObjectMeta.Labels, key)
 		}
 	}
+	// slices created before their project was recorded get the annotations here
+	if r.Project.SetProjectAnnotations(meshSlice, slice.Spec.SliceName) {
+		updateRequired = true
+	}
 	if updateRequired {
 		if err := r.MeshClient.Update(ctx, meshSlice); err != nil {
 			return ctrl.Result{}, err
@@ -328,9 +338,55 @@ func (r *SliceReconciler) updateSliceConfig(ctx context.Context, meshSlice *kube
 		VPCServiceAccess: extGwCfg.VPCServiceAccess,
 	}
 
+	r.updateSubnetOverlaps(ctx, meshSlice, spokeSlice)
+
 	return r.MeshClient.Status().Update(ctx, meshSlice)
 }
 
+// updateSubnetOverlaps validates the slice and gateway subnets received from the hub against the pod,
+// service and other excluded CIDRs of the cluster. The slice router and gateways are not deployed
+// while the slice reports overlaps. The last result is kept if the cluster CIDRs cannot be read.
+func (r *SliceReconciler) updateSubnetOverlaps(ctx context.Context, meshSlice *kubeslicev1beta1.Slice, spokeSlice *spokev1alpha1.WorkerSliceConfig) {
+	log := logger.FromContext(ctx)
+	var overlaps []kubeslicev1beta1.SubnetOverlap
+	if meshSlice.Status.SliceConfig.SliceOverlayNetworkDeploymentMode != v1alpha1.NONET {
+		subnets := map[string]string{
+			"sliceSubnet":       meshSlice.Status.SliceConfig.SliceSubnet,
+			"clusterSubnetCIDR": meshSlice.Status.SliceConfig.ClusterSubnetCIDR,
+		}
+		sliceGwList := &kubeslicev1beta1.SliceGatewayList{}
+		err := r.MeshClient.List(ctx, sliceGwList,
+			client.MatchingLabels{"kubeslice.io/slice": meshSlice.Name}, client.InNamespace(ControlPlaneNamespace))
+		if err != nil {
+			log.Error(err, "unable to list slice gateways to validate their subnets")
+			return
+		}
+		for _, sliceGw := range sliceGwList.Items {
+			subnets["sliceGatewaySubnet/"+sliceGw.Name] = sliceGw.Status.Config.SliceGatewaySubnet
+			subnets["sliceGatewayRemoteSubnet/"+sliceGw.Name] = sliceGw.Status.Config.SliceGatewayRemoteSubnet
+		}
+		overlaps, err = cluster.CheckSubnetOverlaps(ctx, r.MeshClient, subnets)
+		if err != nil {
+			log.Error(err, "unable to validate the slice subnets against the cluster CIDRs")
+			return
+		}
+		if len(overlaps) == 0 {
+			overlaps = nil
+		}
+	}
+	if reflect.DeepEqual(overlaps, meshSlice.Status.SubnetOverlaps) {
+		return
+	}
+	if len(overlaps) > 0 {
+		log.Info("slice subnets overlap with the cluster CIDRs, slice router and gateways are not deployed", "overlaps", overlaps)
+		utils.RecordEvent(ctx, r.EventRecorder, spokeSlice, nil, ossEvents.EventSliceSubnetOverlapDetected, sliceControllerName)
+	} else {
+		log.Info("slice subnets no longer overlap with the cluster CIDRs")
+		utils.RecordEvent(ctx, r.EventRecorder, spokeSlice, nil, ossEvents.EventSliceSubnetOverlapResolved, sliceControllerName)
+	}
+	meshSlice.Status.SubnetOverlaps = overlaps
+}
+
 func (a *SliceReconciler) InjectClient(c client.Client) error {
 	a.Client = c
 	return nil
@@ -340,7 +396,7 @@ func (r *SliceReconciler) deleteSliceResourceOnSpoke(ctx context.Context, slice
 	log := logger.FromContext(ctx)
 	sliceOnSpoke := &kubeslicev1beta1.Slice{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      slice.Spec.SliceName,
+			Name:      r.Project.LocalName(slice.Spec.SliceName),
 			Namespace: ControlPlaneNamespace,
 		},
 	}
@@ -355,7 +411,7 @@ func (r *SliceReconciler) deleteSliceResourceOnSpoke(ctx context.Context, slice
 }
 
 func (r *SliceReconciler) handleSliceDeletion(slice *spokev1alpha1.WorkerSliceConfig, ctx context.Context, req reconcile.Request) (bool, reconcile.Result, error) {
-	sliceName := slice.Spec.SliceName
+	sliceName := r.Project.LocalName(slice.Spec.SliceName)
 	if slice.ObjectMeta.DeletionTimestamp.IsZero() {
 		// The object is not being deleted, so if it does not have our finalizer,
 		// then lets add the finalizer and update the object. This is equivalent
@@ -414,7 +470,7 @@ func (r *SliceReconciler) updateSliceHealth(ctx context.Context, slice *spokev1a
 	}
 	// check for components since slice network is enabled
 	for _, c := range components {
-		cs, err := r.getComponentStatus(ctx, &c, slice.Spec.SliceName)
+		cs, err := r.getComponentStatus(ctx, &c, r.Project.LocalName(slice.Spec.SliceName))
 		if err != nil {
 			log.Error(err, "unable to fetch component status")
 		}
@@ -427,9 +483,79 @@ func (r *SliceReconciler) updateSliceHealth(ctx context.Context, slice *spokev1a
 			debuglog.Info("updated slice health", "SliceHealth", slice.Status.SliceHealth)
 		}
 	}
+	overlap, err := r.getSubnetOverlapStatus(ctx, r.Project.LocalName(slice.Spec.SliceName))
+	if err != nil {
+		log.Error(err, "unable to fetch subnet overlaps")
+	}
+	if overlap != nil {
+		slice.Status.SliceHealth.ComponentStatuses = append(slice.Status.SliceHealth.ComponentStatuses, *overlap)
+		slice.Status.SliceHealth.SliceHealthStatus = spokev1alpha1.SliceHealthStatusWarning
+	}
+	bandwidth, err := r.getBandwidthStatuses(ctx, r.Project.LocalName(slice.Spec.SliceName))
+	if err != nil {
+		log.Error(err, "unable to fetch bandwidth usage")
+	}
+	for _, cs := range bandwidth {
+		slice.Status.SliceHealth.ComponentStatuses = append(slice.Status.SliceHealth.ComponentStatuses, cs)
+		if cs.ComponentHealthStatus != spokev1alpha1.ComponentHealthStatusNormal {
+			slice.Status.SliceHealth.SliceHealthStatus = spokev1alpha1.SliceHealthStatusWarning
+		}
+	}
 	return nil
 }
 
+// getSubnetOverlapStatus returns a subnet-overlap component in error when the slice subnets overlap with
+// the cluster CIDRs, so that the controller admin can pick other subnets for the slice
+func (r *SliceReconciler) getSubnetOverlapStatus(ctx context.Context, sliceName string) (*spokev1alpha1.ComponentStatus, error) {
+	meshSlice := &kubeslicev1beta1.Slice{}
+	err := r.MeshClient.Get(ctx, client.ObjectKey{Name: sliceName, Namespace: ControlPlaneNamespace}, meshSlice)
+	if err != nil {
+		return nil, err
+	}
+	if len(meshSlice.Status.SubnetOverlaps) == 0 {
+		return nil, nil
+	}
+	return &spokev1alpha1.ComponentStatus{
+		Component:             "subnet-overlap",
+		ComponentHealthStatus: spokev1alpha1.ComponentHealthStatusError,
+	}, nil
+}
+
+// share of the samples of a slice gateway at the bandwidth ceiling from which the bandwidth is reported as saturated
+const bandwidthSaturationRatio = 0.1
+
+// getBandwidthStatuses summarizes the bandwidth usage reported by the slice gateways in a
+// bandwidth-<remote cluster> component. The component is in warning when the tunnels to the
+// remote cluster are at the bandwidth ceiling of the slice in a significant share of the samples.
+func (r *SliceReconciler) getBandwidthStatuses(ctx context.Context, sliceName string) ([]spokev1alpha1.ComponentStatus, error) {
+	sliceGwList := &kubeslicev1beta1.SliceGatewayList{}
+	listOpts := []client.ListOption{
+		client.MatchingLabels(map[string]string{
+			"kubeslice.io/slice": sliceName,
+		}),
+		client.InNamespace(ControlPlaneNamespace),
+	}
+	if err := r.MeshClient.List(ctx, sliceGwList, listOpts...); err != nil {
+		return nil, err
+	}
+	statuses := []spokev1alpha1.ComponentStatus{}
+	for _, sliceGw := range sliceGwList.Items {
+		usage := sliceGw.Status.BandwidthUsage
+		if usage == nil || usage.Samples == 0 {
+			continue
+		}
+		cs := spokev1alpha1.ComponentStatus{
+			Component:             "bandwidth-" + sliceGw.Status.Config.SliceGatewayRemoteClusterID,
+			ComponentHealthStatus: spokev1alpha1.ComponentHealthStatusNormal,
+		}
+		if float64(usage.CeilingHits) >= bandwidthSaturationRatio*float64(usage.Samples) {
+			cs.ComponentHealthStatus = spokev1alpha1.ComponentHealthStatusWarning
+		}
+		statuses = append(statuses, cs)
+	}
+	return statuses, nil
+}
+
 func (r *SliceReconciler) getComponentStatus(ctx context.Context, c *component, sliceName string) (*spokev1alpha1.ComponentStatus, error) {
 	log := logger.FromContext(ctx)
 	debuglog := log.V(1)
@@ -193,6 +193,11 @@ func TestReconcileToUpdateWorkerSlice(t *testing.T) {
 		mock.IsType(sliceKey),
 		mock.IsType(&kubeslicev1beta1.Slice{}),
 	).Return(nil)
+	client.On("Get",
+		mock.IsType(ctx),
+		mock.IsType(sliceKey),
+		mock.IsType(&corev1.ConfigMap{}),
+	).Return(nil)
 	client.StatusMock.On("Update",
 		mock.IsType(ctx),
 		mock.IsType(workerslice),
@@ -280,6 +285,16 @@ func TestUpdateSliceConfig(t *testing.T) {
 		mock.IsType(&workerv1alpha1.WorkerSliceConfig{}),
 		mock.IsType([]k8sclient.SubResourceUpdateOption(nil)),
 	).Return(nil)
+	client.On("List",
+		mock.IsType(ctx),
+		mock.IsType(&kubeslicev1beta1.SliceGatewayList{}),
+		mock.IsType([]k8sclient.ListOption{}),
+	).Return(nil)
+	client.On("Get",
+		mock.IsType(ctx),
+		mock.IsType(types.NamespacedName{}),
+		mock.IsType(&corev1.ConfigMap{}),
+	).Return(nil)
 	err := reconciler.updateSliceConfig(expected.ctx, workerslice, controllerSlice)
 	if expected.err != err {
 		t.Error("Expected error:", expected.err, " but got ", err)
@@ -331,12 +346,98 @@ func TestUpdateSliceHealth(t *testing.T) {
 		mock.IsType(&appsv1.DeploymentList{}),
 		mock.IsType([]k8sclient.ListOption{}),
 	).Return(nil)
+	client.On("Get",
+		mock.IsType(ctx),
+		mock.IsType(types.NamespacedName{}),
+		mock.IsType(&kubeslicev1beta1.Slice{}),
+	).Return(nil)
 	controllerSlice.Status.SliceHealth = &workerv1alpha1.SliceHealth{}
 	err := reconciler.updateSliceHealth(expected.ctx, controllerSlice)
 	if expected.err != err {
 		t.Error("Expected error:", expected.err, " but got ", err)
 	}
 }
+func TestGetBandwidthStatuses(t *testing.T) {
+	client := NewClient()
+	mf, _ := metrics.NewMetricsFactory(prometheus.NewRegistry(), metrics.MetricsFactoryOptions{})
+	eventRecorder := mevents.NewEventRecorder(client, &runtime.Scheme{}, ossEvents.EventsMap, mevents.EventRecorderOptions{
+		Cluster: clusterName,
+	})
+	reconciler := NewSliceReconciler(client, client, &eventRecorder, mf)
+
+	newGw := func(remoteCluster string, usage *kubeslicev1beta1.BandwidthUsage) kubeslicev1beta1.SliceGateway {
+		gw := kubeslicev1beta1.SliceGateway{}
+		gw.Status.Config.SliceGatewayRemoteClusterID = remoteCluster
+		gw.Status.BandwidthUsage = usage
+		return gw
+	}
+	client.On("List",
+		mock.IsType(context.Background()),
+		mock.IsType(&kubeslicev1beta1.SliceGatewayList{}),
+		mock.IsType([]k8sclient.ListOption{}),
+	).Return(nil).Run(func(args mock.Arguments) {
+		list := args.Get(1).(*kubeslicev1beta1.SliceGatewayList)
+		list.Items = []kubeslicev1beta1.SliceGateway{
+			newGw("cluster-2", &kubeslicev1beta1.BandwidthUsage{Samples: 60, CeilingHits: 2}),
+			newGw("cluster-3", &kubeslicev1beta1.BandwidthUsage{Samples: 60, CeilingHits: 30}),
+			newGw("cluster-4", nil),
+		}
+	})
+
+	statuses, err := reconciler.getBandwidthStatuses(context.Background(), "test-slice")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	expected := []workerv1alpha1.ComponentStatus{
+		{Component: "bandwidth-cluster-2", ComponentHealthStatus: workerv1alpha1.ComponentHealthStatusNormal},
+		{Component: "bandwidth-cluster-3", ComponentHealthStatus: workerv1alpha1.ComponentHealthStatusWarning},
+	}
+	if len(statuses) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, statuses)
+	}
+	for i := range expected {
+		if statuses[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], statuses[i])
+		}
+	}
+}
+
+func TestGetSubnetOverlapStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		overlaps []kubeslicev1beta1.SubnetOverlap
+		expected *workerv1alpha1.ComponentStatus
+	}{
+		{"no overlaps", nil, nil},
+		{"slice subnet overlaps", []kubeslicev1beta1.SubnetOverlap{
+			{Name: "sliceSubnet", Subnet: "10.96.0.0/16", ClusterCIDR: "10.96.0.0/12"},
+		}, &workerv1alpha1.ComponentStatus{
+			Component:             "subnet-overlap",
+			ComponentHealthStatus: workerv1alpha1.ComponentHealthStatusError,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient()
+			reconciler := &SliceReconciler{Client: client, MeshClient: client}
+			client.On("Get",
+				mock.IsType(context.Background()),
+				mock.IsType(types.NamespacedName{}),
+				mock.IsType(&kubeslicev1beta1.Slice{}),
+			).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(2).(*kubeslicev1beta1.Slice).Status.SubnetOverlaps = tt.overlaps
+			})
+			status, err := reconciler.getSubnetOverlapStatus(context.Background(), "test-slice")
+			if err != nil {
+				t.Fatal("unexpected error: ", err)
+			}
+			if (status == nil) != (tt.expected == nil) || (status != nil && *status != *tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, status)
+			}
+		})
+	}
+}
+
 func TestUpdateSliceConfigByModyfingSubnetOfControllerSlice(t *testing.T) {
 	expected := struct {
 		ctx context.Context
@@ -376,6 +477,16 @@ func TestUpdateSliceConfigByModyfingSubnetOfControllerSlice(t *testing.T) {
 	).Return(nil)
 	controllerSlice.Spec.SliceSubnet = "10.0.0.2/16"
 	workerslice.Status = kubeslicev1beta1.SliceStatus{}
+	client.On("List",
+		mock.IsType(ctx),
+		mock.IsType(&kubeslicev1beta1.SliceGatewayList{}),
+		mock.IsType([]k8sclient.ListOption{}),
+	).Return(nil)
+	client.On("Get",
+		mock.IsType(ctx),
+		mock.IsType(types.NamespacedName{}),
+		mock.IsType(&corev1.ConfigMap{}),
+	).Return(nil)
 	err := reconciler.updateSliceConfig(expected.ctx, workerslice, controllerSlice)
 	if expected.err != err {
 		t.Error("Expected error:", expected.err, " but got ", err)
@@ -31,6 +31,7 @@ import (
 	"github.com/kubeslice/worker-operator/controllers"
 	ossEvents "github.com/kubeslice/worker-operator/events"
 	hubutils "github.com/kubeslice/worker-operator/pkg/hub"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
 	"github.com/kubeslice/worker-operator/pkg/logger"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
@@ -48,6 +49,8 @@ type SliceGwReconciler struct {
 	MeshClient    client.Client
 	EventRecorder *events.EventRecorder
 	ClusterName   string
+	// Project maps the names of the slices and gateways in the hub project to the worker cluster
+	Project hub.Project
 }
 
 var sliceGWController = "workersliceGWController"
@@ -85,7 +88,7 @@ func (r *SliceGwReconciler) Reconcile(ctx context.Context, req reconcile.Request
 		return result, err
 	}
 
-	sliceGwName := sliceGw.Name
+	sliceGwName := r.Project.LocalName(sliceGw.Name)
 	meshSliceGw := &kubeslicev1beta1.SliceGateway{}
 
 	err = r.createSliceGwOnSpoke(ctx, sliceGw, meshSliceGw)
@@ -148,7 +151,7 @@ func (r *SliceGwReconciler) Reconcile(ctx context.Context, req reconcile.Request
 				return err
 			}
 			meshSliceGw.Status.Config = kubeslicev1beta1.SliceGatewayConfig{
-				SliceName:                           sliceGw.Spec.SliceName,
+				SliceName:                           r.Project.LocalName(sliceGw.Spec.SliceName),
 				SliceGatewayID:                      sliceGw.Spec.LocalGatewayConfig.GatewayName,
 				SliceGatewaySubnet:                  sliceGw.Spec.LocalGatewayConfig.GatewaySubnet,
 				SliceGatewayRemoteSubnet:            sliceGw.Spec.RemoteGatewayConfig.GatewaySubnet,
@@ -199,7 +202,7 @@ func (r *SliceGwReconciler) createSliceGwCerts(ctx context.Context, sliceGw *spo
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	meshSecretName := sliceGw.Name + "-" + strconv.Itoa(vpnKeyRotation.Spec.RotationCount) //get vpn key rotation count
+	meshSecretName := r.Project.LocalName(sliceGw.Name) + "-" + strconv.Itoa(vpnKeyRotation.Spec.RotationCount) //get vpn key rotation count
 	err = r.MeshClient.Get(ctx, types.NamespacedName{
 		Name:      meshSecretName,
 		Namespace: ControlPlaneNamespace,
@@ -219,7 +222,7 @@ func (r *SliceGwReconciler) createSliceGwCerts(ctx context.Context, sliceGw *spo
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      meshSecretName,
 					Namespace: ControlPlaneNamespace,
-					Labels:    map[string]string{"kubeslice.io/slice-gw": sliceGw.Name},
+					Labels:    map[string]string{"kubeslice.io/slice-gw": r.Project.LocalName(sliceGw.Name)},
 				},
 				Data: sliceGwCerts.Data,
 			}
@@ -241,7 +244,7 @@ func (r *SliceGwReconciler) deleteSliceGwResourceOnSpoke(ctx context.Context, sl
 	log := logger.FromContext(ctx)
 	sliceGwOnSpoke := &kubeslicev1beta1.SliceGateway{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      sliceGw.Name,
+			Name:      r.Project.LocalName(sliceGw.Name),
 			Namespace: ControlPlaneNamespace,
 		},
 	}
@@ -301,8 +304,8 @@ func (r *SliceGwReconciler) handleSliceGWDeletion(sliceGw *spokev1alpha1.WorkerS
 
 func (r *SliceGwReconciler) createSliceGwOnSpoke(ctx context.Context, sliceGw *spokev1alpha1.WorkerSliceGateway, meshSliceGw *kubeslicev1beta1.SliceGateway) error {
 	log := logger.FromContext(ctx)
-	sliceGwName := sliceGw.Name
-	sliceName := sliceGw.Spec.SliceName
+	sliceGwName := r.Project.LocalName(sliceGw.Name)
+	sliceName := r.Project.LocalName(sliceGw.Spec.SliceName)
 	sliceGwRef := client.ObjectKey{
 		Name:      sliceGwName,
 		Namespace: ControlPlaneNamespace,
@@ -322,8 +325,9 @@ func (r *SliceGwReconciler) createSliceGwOnSpoke(ctx context.Context, sliceGw *s
 					SliceName: sliceName,
 				},
 			}
+			r.Project.SetProjectAnnotations(meshSliceGw, sliceGw.Name)
 			//get the slice object and set it as ownerReference
-			sliceKey := types.NamespacedName{Namespace: ControlPlaneNamespace, Name: sliceGw.Spec.SliceName}
+			sliceKey := types.NamespacedName{Namespace: ControlPlaneNamespace, Name: sliceName}
 			sliceOnSpoke := &kubeslicev1beta1.Slice{}
 			if err := r.MeshClient.Get(ctx, sliceKey, sliceOnSpoke); err != nil {
 				log.Error(err, "Failed to get Slice CR")
@@ -344,6 +348,12 @@ func (r *SliceGwReconciler) createSliceGwOnSpoke(ctx context.Context, sliceGw *s
 			log.Error(err, "unable to fetch sliceGw in spoke cluster", "sliceGw", sliceGwName)
 			return err
 		}
+	} else if r.Project.SetProjectAnnotations(meshSliceGw, sliceGw.Name) {
+		// slice gateways created before their project was recorded get the annotations here
+		if err := r.MeshClient.Update(ctx, meshSliceGw); err != nil {
+			log.Error(err, "unable to annotate sliceGw in spoke cluster", "sliceGw", sliceGwName)
+			return err
+		}
 	}
 	return nil
 }
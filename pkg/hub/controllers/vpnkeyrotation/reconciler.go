@@ -118,7 +118,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (ctrl
 			// fetch the slicegateway to check if it client or server
 			sliceGw := &kubeslicev1beta1.SliceGateway{}
 			err = r.WorkerClient.Get(ctx, types.NamespacedName{
-				Name:      selectedGw,
+				Name:      r.Project.LocalName(selectedGw),
 				Namespace: ControlPlaneNamespace,
 			}, sliceGw)
 
@@ -214,7 +214,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (ctrl
 				// if client then check for server
 				sliceGw := &kubeslicev1beta1.SliceGateway{}
 				err = r.WorkerClient.Get(ctx, types.NamespacedName{
-					Name:      selectedGw,
+					Name:      r.Project.LocalName(selectedGw),
 					Namespace: ControlPlaneNamespace,
 				}, sliceGw)
 				if err != nil {
@@ -395,7 +395,7 @@ func (r *Reconciler) syncCurrentRotationState(ctx context.Context,
 			if ok {
 				syncedRotationState[gw] = obj
 			} else if !ok {
-				if !r.areGatewayPodsReady(ctx, gw) {
+				if !r.areGatewayPodsReady(ctx, r.Project.LocalName(gw)) {
 					requeue = true
 					return errors.New("gateway pods are not ready")
 				}
@@ -446,7 +446,7 @@ func isServer(sliceGw *kubeslicev1beta1.SliceGateway) bool {
 func (r *Reconciler) updateCertificates(ctx context.Context, rotationVersion int, sliceGwName string,
 	req reconcile.Request) (ctrl.Result, bool, error) {
 	log := logger.FromContext(ctx)
-	currentSecretName := sliceGwName + "-" + strconv.Itoa(rotationVersion)
+	currentSecretName := r.Project.LocalName(sliceGwName) + "-" + strconv.Itoa(rotationVersion)
 	meshSliceGwCerts := &corev1.Secret{}
 	err := r.WorkerClient.Get(ctx, types.NamespacedName{
 		Name:      currentSecretName,
@@ -469,7 +469,7 @@ func (r *Reconciler) updateCertificates(ctx context.Context, rotationVersion int
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      currentSecretName,
 					Namespace: ControlPlaneNamespace,
-					Labels:    map[string]string{"kubeslice.io/slice-gw": sliceGwName},
+					Labels:    map[string]string{"kubeslice.io/slice-gw": r.Project.LocalName(sliceGwName)},
 				},
 				Data: sliceGwCerts.Data,
 			}
@@ -511,7 +511,7 @@ func (r *Reconciler) areGatewayPodsReady(ctx context.Context, sliceGWName string
 
 func (r *Reconciler) removeOldSecrets(ctx context.Context, rotationVersion int, sliceGwName string) error {
 	log := logger.FromContext(ctx)
-	currentSecretName := sliceGwName + "-" + strconv.Itoa(rotationVersion-1) // old secret
+	currentSecretName := r.Project.LocalName(sliceGwName) + "-" + strconv.Itoa(rotationVersion-1) // old secret
 	log.Info("deleting previous certificates", "secretName", currentSecretName)
 	sliceGwCerts := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -3,6 +3,7 @@ package vpnkeyrotation
 import (
 	"github.com/kubeslice/kubeslice-monitoring/pkg/events"
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -18,4 +19,6 @@ type Reconciler struct {
 	ControllerClient     client.Client
 	EventRecorder        *events.EventRecorder
 	WorkerRecyclerClient WorkerRecyclerClientProvider
+	// Project maps the names of the gateways in the hub project to the worker cluster
+	Project hub.Project
 }
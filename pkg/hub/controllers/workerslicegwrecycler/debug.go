@@ -0,0 +1,75 @@
+package workerslicegwrecycler
+
+import (
+	"sort"
+
+	spokev1alpha1 "github.com/kubeslice/apis/pkg/worker/v1alpha1"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/debug"
+	"github.com/looplab/fsm"
+)
+
+// metadata keys of the FSMs, they tell the debug endpoint which recycler and gateway an FSM belongs to
+const (
+	metadataRecycler = "recycler"
+	metadataSlice    = "slice"
+	metadataGateway  = "gateway"
+	metadataRole     = "role"
+	metadataRequest  = "request"
+	metadataResponse = "response"
+)
+
+// recyclerState is the state of the FSM of a recycler
+type recyclerState struct {
+	Identifier           string   `json:"identifier"`
+	Recycler             string   `json:"recycler"`
+	Role                 string   `json:"role"`
+	Current              string   `json:"current"`
+	AvailableTransitions []string `json:"availableTransitions"`
+	Request              string   `json:"request,omitempty"`
+	Response             string   `json:"response,omitempty"`
+}
+
+func setDebugMetadata(f *fsm.FSM, recycler *spokev1alpha1.WorkerSliceGwRecycler, slicegw *kubeslicev1beta1.SliceGateway) {
+	f.SetMetadata(metadataRecycler, recycler.Name)
+	f.SetMetadata(metadataSlice, slicegw.Spec.SliceName)
+	f.SetMetadata(metadataGateway, slicegw.Name)
+	f.SetMetadata(metadataRole, slicegw.Status.Config.SliceGatewayHostType)
+	f.SetMetadata(metadataRequest, recycler.Spec.Request)
+	f.SetMetadata(metadataResponse, recycler.Status.Client.Response)
+}
+
+func metadataString(f *fsm.FSM, key string) string {
+	v, ok := f.Metadata(key)
+	if !ok {
+		return ""
+	}
+	s, _ := v.(string)
+	return s
+}
+
+// DebugState returns the state of the FSMs of the recyclers
+func (r *Reconciler) DebugState() []debug.Entry {
+	r.fsmMu.Lock()
+	defer r.fsmMu.Unlock()
+	var entries []debug.Entry
+	for id, f := range r.FSM {
+		transitions := f.AvailableTransitions()
+		sort.Strings(transitions)
+		entries = append(entries, debug.Entry{
+			Slice:   metadataString(f, metadataSlice),
+			Gateway: metadataString(f, metadataGateway),
+			Kind:    "recyclers",
+			State: recyclerState{
+				Identifier:           id,
+				Recycler:             metadataString(f, metadataRecycler),
+				Role:                 metadataString(f, metadataRole),
+				Current:              f.Current(),
+				AvailableTransitions: transitions,
+				Request:              metadataString(f, metadataRequest),
+				Response:             metadataString(f, metadataResponse),
+			},
+		})
+	}
+	return entries
+}
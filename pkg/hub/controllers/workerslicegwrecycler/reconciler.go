@@ -10,9 +10,13 @@ import (
 	ossEvents "github.com/kubeslice/worker-operator/events"
 
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
 
 	retry "github.com/avast/retry-go"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
 	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 	"github.com/looplab/fsm"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -20,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
 )
 
 // FSM State names
@@ -77,6 +82,10 @@ type Reconciler struct {
 	WorkerRouterClient    WorkerRouterClientProvider
 	EventRecorder         *events.EventRecorder
 	FSM                   map[string]*fsm.FSM
+	// fsmMu guards FSM, which is also read by the debug endpoint
+	fsmMu sync.Mutex
+	// Project maps the names of the gateways in the hub project to the worker cluster
+	Project hub.Project
 }
 
 func getUniqueIdentifier(req ctrl.Request) string {
@@ -115,7 +124,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			// Return and don't requeue
 			log.Info("workerslicegwrecycler resource not found. Ignoring since object must be deleted")
 			// delete the FSM from map
+			r.fsmMu.Lock()
 			delete(r.FSM, crIdentifier)
+			r.fsmMu.Unlock()
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -125,9 +136,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	slicegw := kubeslicev1beta1.SliceGateway{}
 
-	if err := r.MeshClient.Get(ctx, types.NamespacedName{Namespace: "kubeslice-system", Name: workerslicegwrecycler.Spec.SliceGwServer}, &slicegw); err != nil {
+	if err := r.MeshClient.Get(ctx, types.NamespacedName{Namespace: "kubeslice-system", Name: r.Project.LocalName(workerslicegwrecycler.Spec.SliceGwServer)}, &slicegw); err != nil {
 		if errors.IsNotFound(err) {
-			if err := r.MeshClient.Get(ctx, types.NamespacedName{Namespace: "kubeslice-system", Name: workerslicegwrecycler.Spec.SliceGwClient}, &slicegw); err != nil {
+			if err := r.MeshClient.Get(ctx, types.NamespacedName{Namespace: "kubeslice-system", Name: r.Project.LocalName(workerslicegwrecycler.Spec.SliceGwClient)}, &slicegw); err != nil {
 				// workergwrecycler not meant for this cluster, return and dont requeue
 				log.Error(err, "workergwrecycler not meant for this cluster, return and dont requeue")
 				return ctrl.Result{}, nil
@@ -135,7 +146,21 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			}
 		}
 	}
+	// the recycling resumes from its current state once the maintenance of the slice ends
+	slice, err := controllers.GetSlice(ctx, r.MeshClient, slicegw.Spec.SliceName)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get Slice", "slice", slicegw.Spec.SliceName)
+		return ctrl.Result{}, err
+	}
+	if controllers.SliceInMaintenance(slice) {
+		log.Info("Slice is in maintenance, pausing gateway recycling", "slice", slicegw.Spec.SliceName)
+		return ctrl.Result{
+			RequeueAfter: operatorconfig.ReconcileInterval(controllers.ReconcileInterval),
+		}, nil
+	}
+
 	// Retrieve or create the FSM for the current CR
+	r.fsmMu.Lock()
 	f, exists := r.FSM[crIdentifier]
 	if !exists {
 		// Create a new FSM for the CR
@@ -152,6 +177,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		)
 		r.FSM[crIdentifier] = f
 	}
+	r.fsmMu.Unlock()
 
 	log.Info("reconciling workerslicegwrecycler ", "workerslicegwrecycler", workerslicegwrecycler.Name, "identifier", crIdentifier)
 	log.Info("current state", "FSM", f.Current())
@@ -159,6 +185,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	*r.EventRecorder = (*r.EventRecorder).WithSlice(slicegw.Spec.SliceName)
 	isClient := slicegw.Status.Config.SliceGatewayHostType == "Client"
 	isServer := slicegw.Status.Config.SliceGatewayHostType == "Server"
+	setDebugMetadata(f, workerslicegwrecycler, &slicegw)
 
 	// To handle operator restart. The server must pick up from where it had left in the previous iteration.
 	if isServer {
@@ -543,5 +570,5 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.FSM = make(map[string]*fsm.FSM) // Initialize the map of FSMs
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&spokev1alpha1.WorkerSliceGwRecycler{}).
-		Complete(r)
+		Complete(tracing.NewReconciler("workerslicegwrecycler", r))
 }
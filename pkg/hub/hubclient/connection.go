@@ -0,0 +1,424 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package hub
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	hubv1alpha1 "github.com/kubeslice/apis/pkg/controller/v1alpha1"
+	"github.com/kubeslice/kubeslice-monitoring/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConnectionState is the state of the connection to the hub cluster
+type ConnectionState string
+
+const (
+	// HubConnectionUnknown is the state until the hub is probed for the first time
+	HubConnectionUnknown ConnectionState = "Unknown"
+	// HubConnected indicates that the hub api server is reachable with the current credentials
+	HubConnected ConnectionState = "Connected"
+	// HubDisconnected indicates that the hub api server cannot be reached
+	HubDisconnected ConnectionState = "Disconnected"
+	// HubUnauthorized indicates that the hub rejects the credentials of the worker,
+	// usually while the token is being rotated
+	HubUnauthorized ConnectionState = "Unauthorized"
+)
+
+var (
+	// HubProbeInterval is the interval between probes while the hub is connected
+	HubProbeInterval = 30 * time.Second
+	// hubReconnectBackoff is used between probes while the hub is not connected
+	hubReconnectBackoff = wait.Backoff{
+		Duration: 5 * time.Second,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    100,
+		Cap:      5 * time.Minute,
+	}
+)
+
+// NewHubRestConfig returns the rest config used to connect to the hub cluster.
+// client-go re-reads the bearer token from HubTokenFile periodically, and the CA bundle is re-read from
+// HubCAFile whenever the file changes, so rotated credentials are picked up without a restart.
+func NewHubRestConfig() *rest.Config {
+	return NewProjectRestConfig(DefaultProject())
+}
+
+// NewProjectRestConfig returns the rest config used to connect to the hub cluster of the given project
+func NewProjectRestConfig(p Project) *rest.Config {
+	ca := &caBundle{file: p.CAFile, host: endpointHost(p.Endpoint)}
+	return &rest.Config{
+		Host:            p.Endpoint,
+		BearerTokenFile: p.TokenFile,
+		Transport: utilnet.SetTransportDefaults(&http.Transport{
+			TLSClientConfig: &tls.Config{
+				// the server certificate is verified in VerifyConnection against the current CA bundle
+				InsecureSkipVerify: true,
+				VerifyConnection:   ca.verifyConnection,
+				MinVersion:         tls.VersionTLS12,
+			},
+		}),
+	}
+}
+
+// endpointHost returns the host name or IP address of the hub endpoint, the hub certificate must be
+// valid for it
+func endpointHost(endpoint string) string {
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	if host, _, err := net.SplitHostPort(endpoint); err == nil {
+		return host
+	}
+	return endpoint
+}
+
+// caBundle holds the CA bundle of the hub and reloads it when the file is rotated
+type caBundle struct {
+	file string
+	// host is the host name or IP address the hub certificate is verified against
+	host string
+	mu   sync.Mutex
+	data []byte
+	pool *x509.CertPool
+}
+
+func (b *caBundle) certPool() (*x509.CertPool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, err := os.ReadFile(b.file)
+	if err != nil {
+		if b.pool != nil {
+			// keep using the last bundle while the file is being replaced
+			return b.pool, nil
+		}
+		return nil, fmt.Errorf("failed to read hub CA file %s: %v", b.file, err)
+	}
+	if b.pool != nil && bytes.Equal(data, b.data) {
+		return b.pool, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		if b.pool != nil {
+			return b.pool, nil
+		}
+		return nil, fmt.Errorf("no certificates found in hub CA file %s", b.file)
+	}
+	if b.pool != nil {
+		log.Info("reloaded hub CA bundle", "file", b.file)
+	}
+	b.data, b.pool = data, pool
+	return pool, nil
+}
+
+func (b *caBundle) verifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("hub did not present a certificate")
+	}
+	if b.host == "" {
+		return errors.New("hub endpoint has no host to verify the hub certificate against")
+	}
+	pool, err := b.certPool()
+	if err != nil {
+		return err
+	}
+	// the server name of the connection is empty for an IP endpoint, the IP SANs of the certificate
+	// are checked against the endpoint host instead
+	opts := x509.VerifyOptions{
+		DNSName:       b.host,
+		Roots:         pool,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err = cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// IsHubUnavailable returns true if the error means the hub could not be reached or refused the credentials
+// of the worker, as opposed to an error returned by a reachable hub for the request itself.
+func IsHubUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if apierrors.IsUnauthorized(err) || apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) || apierrors.IsTooManyRequests(err) {
+		return true
+	}
+	var status apierrors.APIStatus
+	// every other api error was returned by a reachable hub
+	return !errors.As(err, &status)
+}
+
+// ConnectionMonitor tracks the state of the connection to the hub. It probes the hub periodically, with
+// a backoff while the hub is not connected, and observes the result of the calls made by the hub client.
+// It also keeps the last known desired state read from the hub and the writes to the hub that were
+// deferred during an outage, so that the worker keeps running its slices until the hub is back.
+type ConnectionMonitor struct {
+	client client.Client
+	// project and namespace of the hub project the client is connected to
+	project   string
+	namespace string
+
+	mu             sync.RWMutex
+	state          ConnectionState
+	lastTransition time.Time
+	lastError      error
+	// last known desired state read from the hub, by key
+	desiredState map[string]interface{}
+	// writes to replay once the hub is connected again, by key. Only the latest write for a key is kept.
+	deferred map[string]func(context.Context) error
+
+	gaugeConnected *prometheus.GaugeVec
+}
+
+var (
+	connectedGaugesMu sync.Mutex
+	connectedGauges   = map[metrics.MetricsFactory]*prometheus.GaugeVec{}
+)
+
+// connectedGauge returns the hub_connected gauge of the metrics factory. The gauge is shared by the
+// monitors of every hub project, since it can be registered only once.
+func connectedGauge(mf metrics.MetricsFactory) *prometheus.GaugeVec {
+	connectedGaugesMu.Lock()
+	defer connectedGaugesMu.Unlock()
+	if g, ok := connectedGauges[mf]; ok {
+		return g
+	}
+	g := mf.NewGauge("hub_connected", "Whether the hub cluster is reachable by the worker (1) or not (0)", []string{"hub_project"})
+	connectedGauges[mf] = g
+	return g
+}
+
+// NewConnectionMonitor creates a monitor for the connection of the given hub client
+func NewConnectionMonitor(c client.Client, mf metrics.MetricsFactory) *ConnectionMonitor {
+	m := &ConnectionMonitor{
+		client:         c,
+		namespace:      ProjectNamespace,
+		state:          HubConnectionUnknown,
+		lastTransition: time.Now(),
+		desiredState:   map[string]interface{}{},
+		deferred:       map[string]func(context.Context) error{},
+	}
+	if mf != nil {
+		m.gaugeConnected = connectedGauge(mf)
+	}
+	return m
+}
+
+// State returns the current state of the connection to the hub
+func (m *ConnectionMonitor) State() ConnectionState {
+	if m == nil {
+		return HubConnectionUnknown
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Status returns the current state, the time of the last transition and the last error
+func (m *ConnectionMonitor) Status() (ConnectionState, time.Time, error) {
+	if m == nil {
+		return HubConnectionUnknown, time.Time{}, nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state, m.lastTransition, m.lastError
+}
+
+// IsConnected returns true if the last call to the hub succeeded
+func (m *ConnectionMonitor) IsConnected() bool {
+	return m.State() == HubConnected
+}
+
+// Observe updates the state of the connection with the result of a call to the hub
+func (m *ConnectionMonitor) Observe(err error) {
+	if m == nil {
+		return
+	}
+	switch {
+	case err == nil || !IsHubUnavailable(err):
+		m.setState(HubConnected, nil)
+	case apierrors.IsUnauthorized(err):
+		m.setState(HubUnauthorized, err)
+	default:
+		m.setState(HubDisconnected, err)
+	}
+}
+
+func (m *ConnectionMonitor) setState(state ConnectionState, err error) {
+	m.mu.Lock()
+	previous := m.state
+	m.lastError = err
+	if previous != state {
+		m.state = state
+		m.lastTransition = time.Now()
+	}
+	m.mu.Unlock()
+
+	if previous == state {
+		return
+	}
+	if state == HubConnected {
+		log.Info("hub connection state changed", "from", previous, "to", state)
+	} else {
+		log.Error(err, "hub connection state changed", "from", previous, "to", state)
+	}
+	if m.gaugeConnected != nil {
+		connected := 0.0
+		if state == HubConnected {
+			connected = 1
+		}
+		m.gaugeConnected.WithLabelValues(m.project).Set(connected)
+	}
+	if state == HubConnected {
+		// replay outside of the call that observed the connection, with its own deadline
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			m.replayDeferred(ctx)
+		}()
+	}
+}
+
+// Probe checks the connection to the hub by reading the cluster object of the worker
+func (m *ConnectionMonitor) Probe(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err := m.client.Get(ctx, types.NamespacedName{Name: ClusterName, Namespace: m.namespace}, &hubv1alpha1.Cluster{})
+	m.Observe(err)
+	if IsHubUnavailable(err) {
+		return err
+	}
+	return nil
+}
+
+// Start probes the hub until the context is cancelled. It implements manager.Runnable.
+func (m *ConnectionMonitor) Start(ctx context.Context) error {
+	backoff := hubReconnectBackoff
+	for {
+		delay := HubProbeInterval
+		if err := m.Probe(ctx); err != nil {
+			delay = backoff.Step()
+		} else {
+			backoff = hubReconnectBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// NeedLeaderElection returns false, the connection is monitored on every replica of the operator
+func (m *ConnectionMonitor) NeedLeaderElection() bool {
+	return false
+}
+
+// WaitForConnection blocks until the hub is reachable, probing it with a backoff
+func (m *ConnectionMonitor) WaitForConnection(ctx context.Context) error {
+	if m == nil {
+		return nil
+	}
+	backoff := hubReconnectBackoff
+	for {
+		err := m.Probe(ctx)
+		if err == nil {
+			return nil
+		}
+		delay := backoff.Step()
+		log.Info("waiting for hub connection", "state", m.State(), "retryAfter", delay.String())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// remember stores the desired state read from the hub under key
+func (m *ConnectionMonitor) remember(key string, value interface{}) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.desiredState[key] = value
+}
+
+// lastKnown returns the last desired state stored under key
+func (m *ConnectionMonitor) lastKnown(key string) (interface{}, bool) {
+	if m == nil {
+		return nil, false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.desiredState[key]
+	return value, ok
+}
+
+// deferWrite keeps a write to the hub to replay once the hub is connected again.
+// It returns false if writes cannot be deferred and the caller should handle the error.
+func (m *ConnectionMonitor) deferWrite(key string, write func(context.Context) error) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deferred[key] = write
+	return true
+}
+
+func (m *ConnectionMonitor) replayDeferred(ctx context.Context) {
+	m.mu.Lock()
+	deferred := m.deferred
+	m.deferred = map[string]func(context.Context) error{}
+	m.mu.Unlock()
+
+	for key, write := range deferred {
+		if err := write(ctx); err != nil {
+			log.Error(err, "failed to replay deferred write to hub", "key", key)
+			if IsHubUnavailable(err) {
+				m.deferWrite(key, write)
+			}
+			continue
+		}
+		log.Info("replayed deferred write to hub", "key", key)
+	}
+}
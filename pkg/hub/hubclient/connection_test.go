@@ -0,0 +1,286 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package hub
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	hubv1alpha1 "github.com/kubeslice/apis/pkg/controller/v1alpha1"
+	spokev1alpha1 "github.com/kubeslice/apis/pkg/worker/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var errConnRefused = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: connection refused")}
+
+// newUnreliableHubClient returns a hub client whose calls fail with a connection error while *down is true
+func newUnreliableHubClient(down *bool, objs ...client.Object) *HubClientConfig {
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithStatusSubresource(&spokev1alpha1.WorkerSliceConfig{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if *down {
+					return errConnRefused
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				if *down {
+					return errConnRefused
+				}
+				return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			},
+		}).Build()
+	return &HubClientConfig{Client: c, Connection: NewConnectionMonitor(c, nil)}
+}
+
+func TestIsHubUnavailable(t *testing.T) {
+	gr := schema.GroupResource{Group: "controller.kubeslice.io", Resource: "clusters"}
+	cases := []struct {
+		desc     string
+		err      error
+		expected bool
+	}{
+		{"no error", nil, false},
+		{"not found", apierrors.NewNotFound(gr, "cluster-1"), false},
+		{"conflict", apierrors.NewConflict(gr, "cluster-1", errors.New("conflict")), false},
+		{"unauthorized", apierrors.NewUnauthorized("token expired"), true},
+		{"service unavailable", apierrors.NewServiceUnavailable("down"), true},
+		{"connection refused", errConnRefused, true},
+	}
+	for _, tc := range cases {
+		if got := IsHubUnavailable(tc.err); got != tc.expected {
+			t.Errorf("%s: expected %t, got %t", tc.desc, tc.expected, got)
+		}
+	}
+}
+
+func TestConnectionMonitorStateTransitions(t *testing.T) {
+	m := NewConnectionMonitor(nil, nil)
+	if m.State() != HubConnectionUnknown {
+		t.Fatalf("expected initial state %s, got %s", HubConnectionUnknown, m.State())
+	}
+	m.Observe(errConnRefused)
+	if m.State() != HubDisconnected {
+		t.Fatalf("expected state %s, got %s", HubDisconnected, m.State())
+	}
+	m.Observe(apierrors.NewUnauthorized("token expired"))
+	if m.State() != HubUnauthorized {
+		t.Fatalf("expected state %s, got %s", HubUnauthorized, m.State())
+	}
+	m.Observe(apierrors.NewNotFound(schema.GroupResource{}, "x"))
+	if !m.IsConnected() {
+		t.Fatalf("expected state %s, got %s", HubConnected, m.State())
+	}
+	var nilMonitor *ConnectionMonitor
+	if nilMonitor.State() != HubConnectionUnknown || nilMonitor.WaitForConnection(context.Background()) != nil {
+		t.Fatalf("expected nil monitor to be usable")
+	}
+}
+
+func TestGetClusterNamespaceConfigUsesLastKnownConfig(t *testing.T) {
+	down := false
+	cluster := &hubv1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{
+		Name:        "cluster-1",
+		Namespace:   ProjectNamespace,
+		Labels:      map[string]string{"team": "a", "kubeslice-internal": "x"},
+		Annotations: map[string]string{"owner": "b"},
+	}}
+	hubClient := newUnreliableHubClient(&down, cluster)
+	ctx := context.Background()
+
+	// nothing is cached before the first successful read
+	down = true
+	if _, _, err := hubClient.GetClusterNamespaceConfig(ctx, "cluster-1"); err == nil {
+		t.Fatalf("expected error while the hub is down and nothing is cached")
+	}
+
+	down = false
+	labels, annotations, err := hubClient.GetClusterNamespaceConfig(ctx, "cluster-1")
+	if err != nil || labels["team"] != "a" || annotations["owner"] != "b" {
+		t.Fatalf("unexpected namespace config: %v %v %v", labels, annotations, err)
+	}
+
+	down = true
+	labels, annotations, err = hubClient.GetClusterNamespaceConfig(ctx, "cluster-1")
+	if err != nil || labels["team"] != "a" || annotations["owner"] != "b" {
+		t.Fatalf("expected last known namespace config while the hub is down: %v %v %v", labels, annotations, err)
+	}
+	if hubClient.Connection.State() != HubDisconnected {
+		t.Fatalf("expected state %s, got %s", HubDisconnected, hubClient.Connection.State())
+	}
+}
+
+func TestUpdateAppNamespacesDeferredWhileHubDown(t *testing.T) {
+	down := true
+	sliceConfig := &spokev1alpha1.WorkerSliceConfig{ObjectMeta: metav1.ObjectMeta{
+		Name:      "red-cluster-1",
+		Namespace: ProjectNamespace,
+	}}
+	hubClient := newUnreliableHubClient(&down, sliceConfig)
+	ctx := context.Background()
+
+	if err := hubClient.UpdateAppNamespaces(ctx, "red-cluster-1", []string{"iperf"}); err != nil {
+		t.Fatalf("expected write to be deferred, got %v", err)
+	}
+
+	// the deferred write is replayed once the hub is reachable again
+	down = false
+	hubClient.Connection.Observe(nil)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got := &spokev1alpha1.WorkerSliceConfig{}
+		if err := hubClient.Get(ctx, types.NamespacedName{Name: "red-cluster-1", Namespace: ProjectNamespace}, got); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got.Status.OnboardedAppNamespaces) == 1 && got.Status.OnboardedAppNamespaces[0].Name == "iperf" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("deferred write was not replayed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCABundleReload(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	bundle := &caBundle{file: caFile}
+	if _, err := bundle.certPool(); err == nil {
+		t.Fatalf("expected error for missing CA file")
+	}
+
+	if err := os.WriteFile(caFile, newTestCA(t), 0600); err != nil {
+		t.Fatal(err)
+	}
+	pool, err := bundle.certPool()
+	if err != nil || pool == nil {
+		t.Fatalf("expected CA to be loaded: %v", err)
+	}
+
+	// a partially written file keeps the last valid bundle
+	if err := os.WriteFile(caFile, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := bundle.certPool(); err != nil || p != pool {
+		t.Fatalf("expected last valid CA bundle to be kept: %v", err)
+	}
+
+	if err := os.WriteFile(caFile, newTestCA(t), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := bundle.certPool(); err != nil || p == pool {
+		t.Fatalf("expected rotated CA bundle to be loaded: %v", err)
+	}
+}
+
+func TestCABundleVerifiesEndpointHost(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	caCert, caKey := newTestCAKey(t)
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "hub"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"hub.example.com"},
+		IPAddresses:  []net.IP{net.ParseIP("10.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		endpoint  string
+		expectErr bool
+	}{
+		{"https://10.0.0.1:6443", false},
+		{"https://hub.example.com", false},
+		{"https://10.0.0.2:6443", true},
+		{"https://other.example.com:6443", true},
+	}
+	for _, tc := range cases {
+		bundle := &caBundle{file: caFile, host: endpointHost(tc.endpoint)}
+		err := bundle.verifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}})
+		if (err != nil) != tc.expectErr {
+			t.Errorf("%s: expected error %t, got %v", tc.endpoint, tc.expectErr, err)
+		}
+	}
+}
+
+func newTestCA(t *testing.T) []byte {
+	cert, _ := newTestCAKey(t)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+func newTestCAKey(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "hub-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
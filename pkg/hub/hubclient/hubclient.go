@@ -31,16 +31,20 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	hubv1alpha1 "github.com/kubeslice/apis/pkg/controller/v1alpha1"
 	spokev1alpha1 "github.com/kubeslice/apis/pkg/worker/v1alpha1"
+	"github.com/kubeslice/kubeslice-monitoring/pkg/metrics"
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
 	hubutils "github.com/kubeslice/worker-operator/pkg/hub"
 	"github.com/kubeslice/worker-operator/pkg/logger"
 	"github.com/kubeslice/worker-operator/pkg/monitoring"
+	"github.com/kubeslice/worker-operator/pkg/mtu"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -63,6 +67,10 @@ func init() {
 type HubClientConfig struct {
 	client.Client
 	eventRecorder *monitoring.EventRecorder
+	// Connection tracks the reachability of the hub. It is nil when the client is not created by NewHubClientConfig.
+	Connection *ConnectionMonitor
+	// Project is the hub project of the client. The zero value is the project configured by HUB_PROJECT_NAMESPACE.
+	Project Project
 }
 
 type HubClientRpc interface {
@@ -76,29 +84,52 @@ type HubClientRpc interface {
 	UpdateLBIPsForSliceGwServer(ctx context.Context, lbIP []string, sliceGwName string) error
 }
 
-func NewHubClientConfig(er *monitoring.EventRecorder) (*HubClientConfig, error) {
-	hubClient, err := client.New(&rest.Config{
-		Host:            os.Getenv("HUB_HOST_ENDPOINT"),
-		BearerTokenFile: HubTokenFile,
-		TLSClientConfig: rest.TLSClientConfig{
-			CAFile: HubCAFile,
-		}},
+func NewHubClientConfig(er *monitoring.EventRecorder, mf metrics.MetricsFactory) (*HubClientConfig, error) {
+	return NewProjectHubClientConfig(DefaultProject(), er, mf)
+}
+
+// NewProjectHubClientConfig creates a client for the hub cluster of the given project
+func NewProjectHubClientConfig(p Project, er *monitoring.EventRecorder, mf metrics.MetricsFactory) (*HubClientConfig, error) {
+	hubClient, err := client.New(NewProjectRestConfig(p),
 		client.Options{
 			Scheme: scheme,
 		},
 	)
+	if err != nil {
+		return nil, err
+	}
 
+	connection := NewConnectionMonitor(hubClient, mf)
+	connection.project = p.Name
+	connection.namespace = p.Namespace
 	return &HubClientConfig{
 		Client:        hubClient,
 		eventRecorder: er,
-	}, err
+		Connection:    connection,
+		Project:       p,
+	}, nil
 }
 
-func (hubClient *HubClientConfig) CreateWorkerSliceGwRecycler(ctx context.Context, gwRecyclerName, clientID, serverID, sliceGwServer, sliceGwClient, slice string) error {
+// namespace returns the namespace of the project on the hub cluster
+func (hubClient *HubClientConfig) namespace() string {
+	if hubClient.Project.Namespace != "" {
+		return hubClient.Project.Namespace
+	}
+	return ProjectNamespace
+}
+
+// startSpan starts the span of a call to the hub of the project
+func (hubClient *HubClientConfig) startSpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.StartSpan(ctx, "hub."+method, append(attrs, attribute.String("hub.project", hubClient.Project.Name))...)
+}
+
+func (hubClient *HubClientConfig) CreateWorkerSliceGwRecycler(ctx context.Context, gwRecyclerName, clientID, serverID, sliceGwServer, sliceGwClient, slice string) (err error) {
+	ctx, span := hubClient.startSpan(ctx, "CreateWorkerSliceGwRecycler", attribute.String("slice", slice), attribute.String("name", gwRecyclerName))
+	defer func() { tracing.EndSpan(span, err) }()
 	var workerslicegwrecycler spokev1alpha1.WorkerSliceGwRecycler
-	err := hubClient.Get(ctx, types.NamespacedName{
+	err = hubClient.Get(ctx, types.NamespacedName{
 		Name:      gwRecyclerName,
-		Namespace: ProjectNamespace,
+		Namespace: hubClient.namespace(),
 	}, &workerslicegwrecycler)
 	if err == nil {
 		// The object is already created. Return from here
@@ -108,7 +139,7 @@ func (hubClient *HubClientConfig) CreateWorkerSliceGwRecycler(ctx context.Contex
 	workerslicegwrecycler = spokev1alpha1.WorkerSliceGwRecycler{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      gwRecyclerName,
-			Namespace: ProjectNamespace,
+			Namespace: hubClient.namespace(),
 			Labels: map[string]string{
 				"slice_name":   slice,
 				"slicegw_name": sliceGwServer,
@@ -129,11 +160,13 @@ func (hubClient *HubClientConfig) CreateWorkerSliceGwRecycler(ctx context.Contex
 	return hubClient.Create(ctx, &workerslicegwrecycler)
 }
 
-func (hubClient *HubClientConfig) DeleteWorkerSliceGwRecycler(ctx context.Context, gwRecyclerName string) error {
+func (hubClient *HubClientConfig) DeleteWorkerSliceGwRecycler(ctx context.Context, gwRecyclerName string) (err error) {
+	ctx, span := hubClient.startSpan(ctx, "DeleteWorkerSliceGwRecycler", attribute.String("name", gwRecyclerName))
+	defer func() { tracing.EndSpan(span, err) }()
 	var workerslicegwrecycler spokev1alpha1.WorkerSliceGwRecycler
-	err := hubClient.Get(ctx, types.NamespacedName{
+	err = hubClient.Get(ctx, types.NamespacedName{
 		Name:      gwRecyclerName,
-		Namespace: ProjectNamespace,
+		Namespace: hubClient.namespace(),
 	}, &workerslicegwrecycler)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -146,25 +179,29 @@ func (hubClient *HubClientConfig) DeleteWorkerSliceGwRecycler(ctx context.Contex
 	return hubClient.Delete(ctx, &workerslicegwrecycler)
 }
 
-func (hubClient *HubClientConfig) ListWorkerSliceGwRecycler(ctx context.Context, sliceGWName string) ([]spokev1alpha1.WorkerSliceGwRecycler, error) {
+func (hubClient *HubClientConfig) ListWorkerSliceGwRecycler(ctx context.Context, sliceGWName string) (recyclers []spokev1alpha1.WorkerSliceGwRecycler, err error) {
+	ctx, span := hubClient.startSpan(ctx, "ListWorkerSliceGwRecycler", attribute.String("slicegateway", sliceGWName))
+	defer func() { tracing.EndSpan(span, err) }()
 	workerslicegwrecycler := spokev1alpha1.WorkerSliceGwRecyclerList{}
 	labels := map[string]string{"slicegw_name": sliceGWName}
 	listOpts := []client.ListOption{
 		client.MatchingLabels(labels),
-		client.InNamespace(ProjectNamespace),
+		client.InNamespace(hubClient.namespace()),
 	}
-	err := hubClient.List(ctx, &workerslicegwrecycler, listOpts...)
+	err = hubClient.List(ctx, &workerslicegwrecycler, listOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return workerslicegwrecycler.Items, nil
 }
 
-func (hubClient *HubClientConfig) UpdateNodePortForSliceGwServer(ctx context.Context, sliceGwNodePorts []int, sliceGwName string) error {
+func (hubClient *HubClientConfig) UpdateNodePortForSliceGwServer(ctx context.Context, sliceGwNodePorts []int, sliceGwName string) (err error) {
+	ctx, span := hubClient.startSpan(ctx, "UpdateNodePortForSliceGwServer", attribute.String("slicegateway", sliceGwName))
+	defer func() { tracing.EndSpan(span, err) }()
 	sliceGw := &spokev1alpha1.WorkerSliceGateway{}
-	err := hubClient.Get(ctx, types.NamespacedName{
+	err = hubClient.Get(ctx, types.NamespacedName{
 		Name:      sliceGwName,
-		Namespace: ProjectNamespace,
+		Namespace: hubClient.namespace(),
 	}, sliceGw)
 	if err != nil {
 		return err
@@ -199,11 +236,13 @@ func contains(i []string, o string) bool {
 	return false
 }
 
-func (hubClient *HubClientConfig) UpdateLBIPsForSliceGwServer(ctx context.Context, lbIPs []string, sliceGwName string) error {
+func (hubClient *HubClientConfig) UpdateLBIPsForSliceGwServer(ctx context.Context, lbIPs []string, sliceGwName string) (err error) {
+	ctx, span := hubClient.startSpan(ctx, "UpdateLBIPsForSliceGwServer", attribute.String("slicegateway", sliceGwName))
+	defer func() { tracing.EndSpan(span, err) }()
 	sliceGw := &spokev1alpha1.WorkerSliceGateway{}
-	err := hubClient.Get(ctx, types.NamespacedName{
+	err = hubClient.Get(ctx, types.NamespacedName{
 		Name:      sliceGwName,
-		Namespace: ProjectNamespace,
+		Namespace: hubClient.namespace(),
 	}, sliceGw)
 	if err != nil {
 		return err
@@ -229,9 +268,11 @@ func (hubClient *HubClientConfig) UpdateLBIPsForSliceGwServer(ctx context.Contex
 	return nil
 }
 
-func (hubClient *HubClientConfig) GetClusterNodeIP(ctx context.Context, clusterName, namespace string) ([]string, error) {
+func (hubClient *HubClientConfig) GetClusterNodeIP(ctx context.Context, clusterName, namespace string) (nodeIPs []string, err error) {
+	ctx, span := hubClient.startSpan(ctx, "GetClusterNodeIP", attribute.String("cluster", clusterName))
+	defer func() { tracing.EndSpan(span, err) }()
 	cluster := &hubv1alpha1.Cluster{}
-	err := hubClient.Get(ctx, types.NamespacedName{
+	err = hubClient.Get(ctx, types.NamespacedName{
 		Name:      clusterName,
 		Namespace: namespace,
 	}, cluster)
@@ -241,24 +282,61 @@ func (hubClient *HubClientConfig) GetClusterNodeIP(ctx context.Context, clusterN
 	return cluster.Status.NodeIPs, nil
 }
 
-func (hubClient *HubClientConfig) GetVPNKeyRotation(ctx context.Context, rotationName string) (*hubv1alpha1.VpnKeyRotation, error) {
+// GetClusterUnderlayMTU returns the underlay MTU that the worker of the cluster published on its cluster
+// object, or 0 if it is not published yet
+func (hubClient *HubClientConfig) GetClusterUnderlayMTU(ctx context.Context, clusterName string) (underlay int, err error) {
+	ctx, span := hubClient.startSpan(ctx, "GetClusterUnderlayMTU", attribute.String("cluster", clusterName))
+	defer func() { tracing.EndSpan(span, err) }()
+	cluster := &hubv1alpha1.Cluster{}
+	err = hubClient.Get(ctx, types.NamespacedName{
+		Name:      clusterName,
+		Namespace: hubClient.namespace(),
+	}, cluster)
+	if err != nil {
+		return 0, err
+	}
+	return mtu.ParseUnderlay(cluster.Annotations), nil
+}
+
+func (hubClient *HubClientConfig) GetVPNKeyRotation(ctx context.Context, rotationName string) (rotation *hubv1alpha1.VpnKeyRotation, err error) {
+	ctx, span := hubClient.startSpan(ctx, "GetVPNKeyRotation", attribute.String("name", rotationName))
+	defer func() { tracing.EndSpan(span, err) }()
 	vpnKeyRotation := &hubv1alpha1.VpnKeyRotation{}
-	err := hubClient.Get(ctx, types.NamespacedName{
+	err = hubClient.Get(ctx, types.NamespacedName{
 		Name:      rotationName,
-		Namespace: ProjectNamespace,
+		Namespace: hubClient.namespace(),
 	}, vpnKeyRotation)
+	hubClient.Connection.Observe(err)
+	cacheKey := "vpnkeyrotation/" + rotationName
 	if err != nil {
+		// keep the gateways running with the last known rotation while the hub is unavailable
+		if cached, ok := hubClient.Connection.lastKnown(cacheKey); ok && IsHubUnavailable(err) {
+			log.Info("hub unavailable, using last known vpnkeyrotation", "name", rotationName)
+			return cached.(*hubv1alpha1.VpnKeyRotation).DeepCopy(), nil
+		}
 		return nil, err
 	}
+	hubClient.Connection.remember(cacheKey, vpnKeyRotation.DeepCopy())
 	return vpnKeyRotation, nil
 }
 
 func UpdateNamespaceInfoToHub(ctx context.Context, hubClient client.Client, onboardNamespace, sliceName string) error {
+	return updateNamespaceInfoToHub(ctx, hubClient, os.Getenv("HUB_PROJECT_NAMESPACE"), onboardNamespace, sliceName)
+}
+
+// UpdateNamespaceInfo records the slice of the onboarded namespace in the cluster object of the project
+func (hubClient *HubClientConfig) UpdateNamespaceInfo(ctx context.Context, onboardNamespace, sliceName string) (err error) {
+	ctx, span := hubClient.startSpan(ctx, "UpdateNamespaceInfo", attribute.String("slice", sliceName), attribute.String("namespace", onboardNamespace))
+	defer func() { tracing.EndSpan(span, err) }()
+	return updateNamespaceInfoToHub(ctx, hubClient, hubClient.namespace(), onboardNamespace, sliceName)
+}
+
+func updateNamespaceInfoToHub(ctx context.Context, hubClient client.Client, projectNamespace, onboardNamespace, sliceName string) error {
 	hubCluster := &hubv1alpha1.Cluster{}
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		err := hubClient.Get(ctx, types.NamespacedName{
 			Name:      os.Getenv("CLUSTER_NAME"),
-			Namespace: os.Getenv("HUB_PROJECT_NAMESPACE"),
+			Namespace: projectNamespace,
 		}, hubCluster)
 		if err != nil {
 			return err
@@ -298,11 +376,22 @@ func indexOf(onboardNamespace string, ns []hubv1alpha1.NamespacesConfig) int {
 }
 
 func DeleteNamespaceInfoFromHub(ctx context.Context, hubClient client.Client, onboardNamespace string) error {
+	return deleteNamespaceInfoFromHub(ctx, hubClient, os.Getenv("HUB_PROJECT_NAMESPACE"), onboardNamespace)
+}
+
+// DeleteNamespaceInfo removes the namespace from the cluster object of the project
+func (hubClient *HubClientConfig) DeleteNamespaceInfo(ctx context.Context, onboardNamespace string) (err error) {
+	ctx, span := hubClient.startSpan(ctx, "DeleteNamespaceInfo", attribute.String("namespace", onboardNamespace))
+	defer func() { tracing.EndSpan(span, err) }()
+	return deleteNamespaceInfoFromHub(ctx, hubClient, hubClient.namespace(), onboardNamespace)
+}
+
+func deleteNamespaceInfoFromHub(ctx context.Context, hubClient client.Client, projectNamespace, onboardNamespace string) error {
 	hubCluster := &hubv1alpha1.Cluster{}
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		err := hubClient.Get(ctx, types.NamespacedName{
 			Name:      os.Getenv("CLUSTER_NAME"),
-			Namespace: os.Getenv("HUB_PROJECT_NAMESPACE"),
+			Namespace: projectNamespace,
 		}, hubCluster)
 		if err != nil {
 			return err
@@ -372,11 +461,11 @@ func getHubServiceExportObjName(serviceexport *kubeslicev1beta1.ServiceExport) s
 	return serviceexport.Name + "-" + serviceexport.ObjectMeta.Namespace + "-" + ClusterName
 }
 
-func getHubServiceExportObj(serviceexport *kubeslicev1beta1.ServiceExport) *hubv1alpha1.ServiceExportConfig {
+func getHubServiceExportObj(serviceexport *kubeslicev1beta1.ServiceExport, namespace string) *hubv1alpha1.ServiceExportConfig {
 	return &hubv1alpha1.ServiceExportConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      getHubServiceExportObjName(serviceexport),
-			Namespace: ProjectNamespace,
+			Namespace: namespace,
 		},
 		Spec: hubv1alpha1.ServiceExportConfigSpec{
 			ServiceName:               serviceexport.Name,
@@ -401,18 +490,20 @@ func getHubServiceDiscoveryEpForIngressGw(ep *kubeslicev1beta1.ServicePod) hubv1
 }
 
 func (hubClient *HubClientConfig) UpdateServiceExportEndpointForIngressGw(ctx context.Context, serviceexport *kubeslicev1beta1.ServiceExport,
-	ep *kubeslicev1beta1.ServicePod) error {
+	ep *kubeslicev1beta1.ServicePod) (err error) {
+	ctx, span := hubClient.startSpan(ctx, "UpdateServiceExportEndpointForIngressGw", attribute.String("slice", serviceexport.Spec.Slice), attribute.String("serviceexport", serviceexport.Name))
+	defer func() { tracing.EndSpan(span, err) }()
 	hubSvcEx := &hubv1alpha1.ServiceExportConfig{}
-	err := hubClient.Get(ctx, types.NamespacedName{
+	err = hubClient.Get(ctx, types.NamespacedName{
 		Name:      getHubServiceExportObjName(serviceexport),
-		Namespace: ProjectNamespace,
+		Namespace: hubClient.namespace(),
 	}, hubSvcEx)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			hubSvcExObj := &hubv1alpha1.ServiceExportConfig{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getHubServiceExportObjName(serviceexport),
-					Namespace: ProjectNamespace,
+					Namespace: hubClient.namespace(),
 				},
 				Spec: hubv1alpha1.ServiceExportConfigSpec{
 					ServiceName:               serviceexport.Name,
@@ -445,15 +536,17 @@ func (hubClient *HubClientConfig) UpdateServiceExportEndpointForIngressGw(ctx co
 	return nil
 }
 
-func (hubClient *HubClientConfig) UpdateServiceExport(ctx context.Context, serviceexport *kubeslicev1beta1.ServiceExport) error {
+func (hubClient *HubClientConfig) UpdateServiceExport(ctx context.Context, serviceexport *kubeslicev1beta1.ServiceExport) (err error) {
+	ctx, span := hubClient.startSpan(ctx, "UpdateServiceExport", attribute.String("slice", serviceexport.Spec.Slice), attribute.String("serviceexport", serviceexport.Name))
+	defer func() { tracing.EndSpan(span, err) }()
 	hubSvcEx := &hubv1alpha1.ServiceExportConfig{}
-	err := hubClient.Get(ctx, types.NamespacedName{
+	err = hubClient.Get(ctx, types.NamespacedName{
 		Name:      getHubServiceExportObjName(serviceexport),
-		Namespace: ProjectNamespace,
+		Namespace: hubClient.namespace(),
 	}, hubSvcEx)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			err = hubClient.Create(ctx, getHubServiceExportObj(serviceexport))
+			err = hubClient.Create(ctx, getHubServiceExportObj(serviceexport, hubClient.namespace()))
 			if err != nil {
 				return err
 			}
@@ -462,7 +555,7 @@ func (hubClient *HubClientConfig) UpdateServiceExport(ctx context.Context, servi
 		return err
 	}
 
-	hubSvcEx.Spec = getHubServiceExportObj(serviceexport).Spec
+	hubSvcEx.Spec = getHubServiceExportObj(serviceexport, hubClient.namespace()).Spec
 
 	log.WithValues("serviceexport", serviceexport.Name).Info("Updated serviceexport on hub", "spec", hubSvcEx.Spec)
 
@@ -474,11 +567,13 @@ func (hubClient *HubClientConfig) UpdateServiceExport(ctx context.Context, servi
 	return nil
 }
 
-func (hubClient *HubClientConfig) DeleteServiceExport(ctx context.Context, serviceexport *kubeslicev1beta1.ServiceExport) error {
+func (hubClient *HubClientConfig) DeleteServiceExport(ctx context.Context, serviceexport *kubeslicev1beta1.ServiceExport) (err error) {
+	ctx, span := hubClient.startSpan(ctx, "DeleteServiceExport", attribute.String("slice", serviceexport.Spec.Slice), attribute.String("serviceexport", serviceexport.Name))
+	defer func() { tracing.EndSpan(span, err) }()
 	hubSvcEx := &hubv1alpha1.ServiceExportConfig{}
-	err := hubClient.Get(ctx, types.NamespacedName{
+	err = hubClient.Get(ctx, types.NamespacedName{
 		Name:      getHubServiceExportObjName(serviceexport),
-		Namespace: ProjectNamespace,
+		Namespace: hubClient.namespace(),
 	}, hubSvcEx)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -515,20 +610,47 @@ func filterLabelsAndAnnotations(data map[string]string) map[string]string {
 	return filtered
 }
 
-func (hubclient *HubClientConfig) GetClusterNamespaceConfig(ctx context.Context, clusterName string) (map[string]string, map[string]string, error) {
+// namespaceConfig is the namespace labels and annotations configured on the cluster object in the hub
+type namespaceConfig struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+func (hubclient *HubClientConfig) GetClusterNamespaceConfig(ctx context.Context, clusterName string) (labels map[string]string, annotations map[string]string, err error) {
+	ctx, span := hubclient.startSpan(ctx, "GetClusterNamespaceConfig", attribute.String("cluster", clusterName))
+	defer func() { tracing.EndSpan(span, err) }()
 	cluster := &hubv1alpha1.Cluster{}
-	err := hubclient.Get(ctx, types.NamespacedName{Name: clusterName, Namespace: ProjectNamespace}, cluster)
+	err = hubclient.Get(ctx, types.NamespacedName{Name: clusterName, Namespace: hubclient.namespace()}, cluster)
+	hubclient.Connection.Observe(err)
+	cacheKey := "namespaceconfig/" + clusterName
 	if err != nil {
+		// keep the app namespaces labelled with the last known config while the hub is unavailable
+		if cached, ok := hubclient.Connection.lastKnown(cacheKey); ok && IsHubUnavailable(err) {
+			log.Info("hub unavailable, using last known cluster namespace config", "cluster", clusterName)
+			cfg := cached.(namespaceConfig)
+			return cfg.labels, cfg.annotations, nil
+		}
 		return nil, nil, err
 	}
-	return filterLabelsAndAnnotations(cluster.GetLabels()), filterLabelsAndAnnotations(cluster.GetAnnotations()), nil
+	labels, annotations = filterLabelsAndAnnotations(cluster.GetLabels()), filterLabelsAndAnnotations(cluster.GetAnnotations())
+	hubclient.Connection.remember(cacheKey, namespaceConfig{labels: labels, annotations: annotations})
+	return labels, annotations, nil
 }
 
-func (hubClient *HubClientConfig) UpdateAppPodsList(ctx context.Context, sliceConfigName string, appPods []kubeslicev1beta1.AppPod) error {
+func (hubClient *HubClientConfig) UpdateAppPodsList(ctx context.Context, sliceConfigName string, appPods []kubeslicev1beta1.AppPod) (err error) {
+	ctx, span := hubClient.startSpan(ctx, "UpdateAppPodsList", attribute.String("sliceconfig", sliceConfigName))
+	defer func() { tracing.EndSpan(span, err) }()
+	err = hubClient.updateAppPodsList(ctx, sliceConfigName, appPods)
+	return hubClient.deferIfUnavailable(err, "apppods/"+sliceConfigName, func(ctx context.Context) error {
+		return hubClient.updateAppPodsList(ctx, sliceConfigName, appPods)
+	})
+}
+
+func (hubClient *HubClientConfig) updateAppPodsList(ctx context.Context, sliceConfigName string, appPods []kubeslicev1beta1.AppPod) error {
 	sliceConfig := &spokev1alpha1.WorkerSliceConfig{}
 	err := hubClient.Get(ctx, types.NamespacedName{
 		Name:      sliceConfigName,
-		Namespace: ProjectNamespace,
+		Namespace: hubClient.namespace(),
 	}, sliceConfig)
 	if err != nil {
 		return err
@@ -548,13 +670,22 @@ func (hubClient *HubClientConfig) UpdateAppPodsList(ctx context.Context, sliceCo
 
 	return hubClient.Status().Update(ctx, sliceConfig)
 }
-func (hubClient *HubClientConfig) UpdateAppNamespaces(ctx context.Context, sliceConfigName string, onboardedNamespaces []string) error {
+func (hubClient *HubClientConfig) UpdateAppNamespaces(ctx context.Context, sliceConfigName string, onboardedNamespaces []string) (err error) {
+	ctx, span := hubClient.startSpan(ctx, "UpdateAppNamespaces", attribute.String("sliceconfig", sliceConfigName))
+	defer func() { tracing.EndSpan(span, err) }()
+	err = hubClient.updateAppNamespaces(ctx, sliceConfigName, onboardedNamespaces)
+	return hubClient.deferIfUnavailable(err, "appnamespaces/"+sliceConfigName, func(ctx context.Context) error {
+		return hubClient.updateAppNamespaces(ctx, sliceConfigName, onboardedNamespaces)
+	})
+}
+
+func (hubClient *HubClientConfig) updateAppNamespaces(ctx context.Context, sliceConfigName string, onboardedNamespaces []string) error {
 	log.Info("updating onboardedNamespaces to workersliceconfig", "onboardedNamespaces", onboardedNamespaces)
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		workerSliceConfig := &spokev1alpha1.WorkerSliceConfig{}
 		err := hubClient.Get(ctx, types.NamespacedName{
 			Name:      sliceConfigName,
-			Namespace: ProjectNamespace,
+			Namespace: hubClient.namespace(),
 		}, workerSliceConfig)
 		if err != nil {
 			return err
@@ -569,3 +700,18 @@ func (hubClient *HubClientConfig) UpdateAppNamespaces(ctx context.Context, slice
 	})
 	return err
 }
+
+// deferIfUnavailable observes the result of a write to the hub. If the hub is unavailable, the write is
+// kept to be replayed once the hub is connected again and no error is returned, so that the reconcilers
+// keep running the slice with its local state during the outage.
+func (hubClient *HubClientConfig) deferIfUnavailable(err error, key string, write func(context.Context) error) error {
+	hubClient.Connection.Observe(err)
+	if !IsHubUnavailable(err) {
+		return err
+	}
+	if !hubClient.Connection.deferWrite(key, write) {
+		return err
+	}
+	log.Info("hub unavailable, deferring write to hub", "key", key, "error", err.Error())
+	return nil
+}
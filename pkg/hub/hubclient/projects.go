@@ -0,0 +1,170 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package hub
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// ProjectAnnotation records the project of the slices, slice gateways and serviceexports on the worker cluster
+	ProjectAnnotation = "kubeslice.io/hub-project"
+	// HubNameAnnotation records the name in the project of the slices and slice gateways on the worker cluster
+	HubNameAnnotation = "kubeslice.io/hub-name"
+	// maxLocalNameLength leaves room for the prefixes and suffixes the worker adds to the names of the slices
+	// and slice gateways, such as svc-<slice>-gw-edge, within the 63 characters of a DNS label
+	maxLocalNameLength = 51
+)
+
+// Project is the registration of the worker cluster in a project of a hub cluster.
+// The first registration is the primary project: its slices keep their names on the worker cluster.
+// The names of the slices and slice gateways of every other project are prefixed with the name of
+// the project on the worker cluster, so that slices with the same name in two projects do not collide.
+// The project and the name in the project are recorded in annotations of the objects, see SetProjectAnnotations.
+type Project struct {
+	// Name of the project, used as the prefix of its objects on the worker cluster
+	Name string `yaml:"name"`
+	// Endpoint of the hub cluster
+	Endpoint string `yaml:"endpoint"`
+	// Namespace of the project on the hub cluster
+	Namespace string `yaml:"namespace"`
+	// TokenFile is the service account token of the worker in the project
+	TokenFile string `yaml:"tokenFile,omitempty"`
+	// CAFile is the CA bundle of the hub cluster
+	CAFile string `yaml:"caFile,omitempty"`
+
+	primary bool
+}
+
+// DefaultProject returns the registration configured through the HUB_* environment variables
+func DefaultProject() Project {
+	return Project{
+		Name:      strings.TrimPrefix(ProjectNamespace, "kubeslice-"),
+		Endpoint:  HubEndpoint,
+		Namespace: ProjectNamespace,
+		TokenFile: HubTokenFile,
+		CAFile:    HubCAFile,
+		primary:   true,
+	}
+}
+
+// LoadProjects returns the project registrations of the worker. The registrations are read from
+// HubProjectsFile if it is set, otherwise the worker is registered in the default project only.
+func LoadProjects() ([]Project, error) {
+	if HubProjectsFile == "" {
+		return []Project{DefaultProject()}, nil
+	}
+	data, err := os.ReadFile(HubProjectsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hub projects file %s: %v", HubProjectsFile, err)
+	}
+	return ParseProjects(data)
+}
+
+// ParseProjects parses and validates a YAML or JSON list of project registrations
+func ParseProjects(data []byte) ([]Project, error) {
+	var projects []Project
+	if err := yaml.Unmarshal(data, &projects); err != nil {
+		return nil, fmt.Errorf("invalid hub projects: %v", err)
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no hub projects registered")
+	}
+	names := map[string]bool{}
+	keys := map[string]bool{}
+	for i := range projects {
+		p := &projects[i]
+		if errs := validation.IsDNS1123Label(p.Name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid name of hub project %q: %s", p.Name, strings.Join(errs, ", "))
+		}
+		if p.Endpoint == "" || p.Namespace == "" {
+			return nil, fmt.Errorf("hub project %s: endpoint and namespace are required", p.Name)
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("hub project %s is registered more than once", p.Name)
+		}
+		names[p.Name] = true
+		key := p.Endpoint + "/" + p.Namespace
+		if keys[key] {
+			return nil, fmt.Errorf("hub project %s: namespace %s of %s is registered more than once", p.Name, p.Namespace, p.Endpoint)
+		}
+		keys[key] = true
+		if p.TokenFile == "" {
+			p.TokenFile = HubTokenFile
+		}
+		if p.CAFile == "" {
+			p.CAFile = HubCAFile
+		}
+	}
+	projects[0].primary = true
+	return projects, nil
+}
+
+// IsPrimary returns true for the primary project of the worker
+func (p Project) IsPrimary() bool {
+	return p.primary || p.Name == ""
+}
+
+// LocalName returns the name on the worker cluster of an object named hubName in the project. The names
+// that would not leave room for the names the worker derives from them are shortened with a hash.
+func (p Project) LocalName(hubName string) string {
+	if p.IsPrimary() {
+		return hubName
+	}
+	name := p.Name + "-" + hubName
+	if len(name) <= maxLocalNameLength {
+		return name
+	}
+	hash := sha256.Sum256([]byte(name))
+	return name[:maxLocalNameLength-9] + "-" + hex.EncodeToString(hash[:])[:8]
+}
+
+// SetProjectAnnotations records the project and the name in the project of an object the worker
+// creates for it. It returns true if the annotations were changed.
+func (p Project) SetProjectAnnotations(obj metav1.Object, hubName string) bool {
+	annotations := obj.GetAnnotations()
+	if annotations[ProjectAnnotation] == p.Name && annotations[HubNameAnnotation] == hubName {
+		return false
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ProjectAnnotation] = p.Name
+	annotations[HubNameAnnotation] = hubName
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// BelongsTo returns true if the object was created for the project. Objects created before the
+// project annotations were recorded belong to the primary project.
+func (p Project) BelongsTo(obj metav1.Object) bool {
+	project, ok := obj.GetAnnotations()[ProjectAnnotation]
+	if !ok {
+		return p.IsPrimary()
+	}
+	return project == p.Name
+}
@@ -0,0 +1,225 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package hub
+
+import (
+	"context"
+	"testing"
+
+	hubv1alpha1 "github.com/kubeslice/apis/pkg/controller/v1alpha1"
+	spokev1alpha1 "github.com/kubeslice/apis/pkg/worker/v1alpha1"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseProjects(t *testing.T) {
+	projects, err := ParseProjects([]byte(`
+- name: red
+  endpoint: https://hub-1:6443
+  namespace: kubeslice-red
+- name: blue
+  endpoint: https://hub-2:6443
+  namespace: kubeslice-blue
+  tokenFile: /var/run/secrets/blue/token
+  caFile: /var/run/secrets/blue/ca.crt
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %d", len(projects))
+	}
+	if !projects[0].IsPrimary() || projects[1].IsPrimary() {
+		t.Errorf("expected only the first project to be primary")
+	}
+	if projects[0].TokenFile != HubTokenFile || projects[0].CAFile != HubCAFile {
+		t.Errorf("expected the default credentials for red, got %s %s", projects[0].TokenFile, projects[0].CAFile)
+	}
+	if projects[1].TokenFile != "/var/run/secrets/blue/token" || projects[1].CAFile != "/var/run/secrets/blue/ca.crt" {
+		t.Errorf("unexpected credentials for blue: %s %s", projects[1].TokenFile, projects[1].CAFile)
+	}
+
+	invalid := map[string]string{
+		"empty":             `[]`,
+		"invalid name":      `[{name: Red, endpoint: https://hub, namespace: kubeslice-red}]`,
+		"missing endpoint":  `[{name: red, namespace: kubeslice-red}]`,
+		"missing namespace": `[{name: red, endpoint: https://hub}]`,
+		"duplicate name":    `[{name: red, endpoint: https://hub, namespace: kubeslice-red}, {name: red, endpoint: https://hub-2, namespace: kubeslice-red}]`,
+		"duplicate project": `[{name: red, endpoint: https://hub, namespace: kubeslice-red}, {name: blue, endpoint: https://hub, namespace: kubeslice-red}]`,
+	}
+	for desc, data := range invalid {
+		if _, err := ParseProjects([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", desc)
+		}
+	}
+}
+
+func TestProjectNames(t *testing.T) {
+	primary := Project{Name: "red", primary: true}
+	if got := primary.LocalName("slice-1"); got != "slice-1" {
+		t.Errorf("expected the primary project to keep the name, got %s", got)
+	}
+	secondary := Project{Name: "blue"}
+	if got := secondary.LocalName("slice-1"); got != "blue-slice-1" {
+		t.Errorf("expected blue-slice-1, got %s", got)
+	}
+	long := "slice-1-worker-cluster-east-1-worker-cluster-west-1"
+	name := secondary.LocalName(long)
+	if len(name) != maxLocalNameLength || name == (Project{Name: "green"}).LocalName(long) {
+		t.Errorf("expected a unique name of %d characters, got %s", maxLocalNameLength, name)
+	}
+	if name != secondary.LocalName(long) {
+		t.Errorf("expected the shortened name to be stable")
+	}
+}
+
+func TestProjectAnnotations(t *testing.T) {
+	primary := Project{Name: "red", primary: true}
+	secondary := Project{Name: "blue"}
+	slice := &kubeslicev1beta1.Slice{ObjectMeta: metav1.ObjectMeta{Name: "blue-x"}}
+	if !primary.BelongsTo(slice) || secondary.BelongsTo(slice) {
+		t.Errorf("expected a slice without annotations to belong to the primary project")
+	}
+	if !secondary.SetProjectAnnotations(slice, "x") {
+		t.Errorf("expected the annotations to be set")
+	}
+	if secondary.SetProjectAnnotations(slice, "x") {
+		t.Errorf("expected the annotations to be unchanged")
+	}
+	if primary.BelongsTo(slice) || !secondary.BelongsTo(slice) {
+		t.Errorf("expected the slice to belong to blue, got %v", slice.Annotations)
+	}
+}
+
+func newProjectHubClient(p Project, objs ...client.Object) *HubClientConfig {
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithStatusSubresource(&spokev1alpha1.WorkerSliceConfig{}).Build()
+	return &HubClientConfig{Client: c, Connection: NewConnectionMonitor(c, nil), Project: p}
+}
+
+// newWorkerSlice returns a slice on the worker cluster created for the project
+func newWorkerSlice(p Project, hubName string) *kubeslicev1beta1.Slice {
+	slice := &kubeslicev1beta1.Slice{ObjectMeta: metav1.ObjectMeta{Name: p.LocalName(hubName), Namespace: controlPlaneNamespace}}
+	p.SetProjectAnnotations(slice, hubName)
+	return slice
+}
+
+func TestProjectHubClientsForSlice(t *testing.T) {
+	red := newProjectHubClient(Project{Name: "red", Namespace: "kubeslice-red", primary: true})
+	blue := newProjectHubClient(Project{Name: "blue", Namespace: "kubeslice-blue"})
+	blueGreen := newProjectHubClient(Project{Name: "blue-green", Namespace: "kubeslice-blue-green"})
+	worker := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newWorkerSlice(red.Project, "blue-x"),
+		newWorkerSlice(blue.Project, "slice-1"),
+		newWorkerSlice(blueGreen.Project, "slice-1"),
+		// created before the project annotations were recorded
+		&kubeslicev1beta1.Slice{ObjectMeta: metav1.ObjectMeta{Name: "slice-2", Namespace: controlPlaneNamespace}},
+	).Build()
+	p := &ProjectHubClients{clients: []*HubClientConfig{red, blue, blueGreen}, workerClient: worker}
+
+	cases := []struct {
+		localName string
+		client    *HubClientConfig
+		hubName   string
+	}{
+		{"blue-x", red, "blue-x"},
+		{"slice-2", red, "slice-2"},
+		{"blue-slice-1", blue, "slice-1"},
+		{"blue-green-slice-1", blueGreen, "slice-1"},
+	}
+	for _, c := range cases {
+		client, name, err := p.forSlice(context.Background(), c.localName)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.localName, err)
+		}
+		if client != c.client || name != c.hubName {
+			t.Errorf("%s: expected %s in %s, got %s in %s", c.localName, c.hubName, c.client.Project.Name, name, client.Project.Name)
+		}
+	}
+	if _, _, err := p.forSlice(context.Background(), "slice-3"); err == nil {
+		t.Errorf("expected an error for a slice that is not on the worker cluster")
+	}
+}
+
+func TestProjectHubClientsRouteWrites(t *testing.T) {
+	sliceConfig := func(ns string) *spokev1alpha1.WorkerSliceConfig {
+		return &spokev1alpha1.WorkerSliceConfig{ObjectMeta: metav1.ObjectMeta{Name: "slice-1-cluster-1", Namespace: ns}}
+	}
+	red := newProjectHubClient(Project{Name: "red", Namespace: "kubeslice-red", primary: true}, sliceConfig("kubeslice-red"))
+	blue := newProjectHubClient(Project{Name: "blue", Namespace: "kubeslice-blue"}, sliceConfig("kubeslice-blue"))
+	worker := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newWorkerSlice(blue.Project, "slice-1")).Build()
+	p := &ProjectHubClients{clients: []*HubClientConfig{red, blue}, workerClient: worker}
+	ctx := context.Background()
+	clusterName := ClusterName
+	ClusterName = "cluster-1"
+	defer func() { ClusterName = clusterName }()
+
+	pods := []kubeslicev1beta1.AppPod{{PodName: "pod-1", PodNamespace: "app"}}
+	if err := p.UpdateAppPodsList(ctx, "blue-slice-1-cluster-1", pods); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	updated := &spokev1alpha1.WorkerSliceConfig{}
+	if err := blue.Get(ctx, types.NamespacedName{Name: "slice-1-cluster-1", Namespace: "kubeslice-blue"}, updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updated.Status.ConnectedAppPods) != 1 {
+		t.Errorf("expected the app pods to be written to the blue project, got %v", updated.Status.ConnectedAppPods)
+	}
+	if err := red.Get(ctx, types.NamespacedName{Name: "slice-1-cluster-1", Namespace: "kubeslice-red"}, updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updated.Status.ConnectedAppPods) != 0 {
+		t.Errorf("expected the red project to be left untouched, got %v", updated.Status.ConnectedAppPods)
+	}
+
+	svcex := &kubeslicev1beta1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "iperf", Namespace: "app"},
+		Spec: kubeslicev1beta1.ServiceExportSpec{
+			Slice: "blue-slice-1",
+			Ports: []kubeslicev1beta1.ServicePort{{Name: "tcp", ContainerPort: 5201, Protocol: "TCP"}},
+		},
+		Status: kubeslicev1beta1.ServiceExportStatus{
+			Pods: []kubeslicev1beta1.ServicePod{{Name: "iperf-0", NsmIP: "10.1.1.1", DNSName: "iperf-0.iperf.app.svc.slice.local"}},
+		},
+	}
+	if err := p.UpdateServiceExport(ctx, svcex); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hubSvcex := &hubv1alpha1.ServiceExportConfig{}
+	if err := blue.Get(ctx, types.NamespacedName{Name: getHubServiceExportObjName(svcex), Namespace: "kubeslice-blue"}, hubSvcex); err != nil {
+		t.Fatalf("expected the serviceexport in the blue project: %v", err)
+	}
+	if hubSvcex.Spec.SliceName != "slice-1" {
+		t.Errorf("expected slice-1 on the hub, got %s", hubSvcex.Spec.SliceName)
+	}
+	if svcex.Spec.Slice != "blue-slice-1" {
+		t.Errorf("expected the local serviceexport not to be modified, got %s", svcex.Spec.Slice)
+	}
+
+	svcex.Annotations = map[string]string{ProjectAnnotation: "blue"}
+	if err := p.DeleteServiceExport(ctx, svcex); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := blue.Get(ctx, types.NamespacedName{Name: getHubServiceExportObjName(svcex), Namespace: "kubeslice-blue"}, hubSvcex); err == nil {
+		t.Errorf("expected the serviceexport to be deleted from the blue project")
+	}
+}
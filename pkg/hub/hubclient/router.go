@@ -0,0 +1,287 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package hub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	hubv1alpha1 "github.com/kubeslice/apis/pkg/controller/v1alpha1"
+	spokev1alpha1 "github.com/kubeslice/apis/pkg/worker/v1alpha1"
+	"github.com/kubeslice/kubeslice-monitoring/pkg/metrics"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/monitoring"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// controlPlaneNamespace is the namespace of the slices and slice gateways on the worker cluster
+const controlPlaneNamespace = "kubeslice-system"
+
+// ProjectHubClients routes the calls of the worker controllers to the hub client of the project the
+// object belongs to. The project and the name of the object in the project are read from the annotations
+// of the slice or slice gateway on the worker cluster.
+type ProjectHubClients struct {
+	// clients of every project, the primary project first
+	clients []*HubClientConfig
+	// workerClient reads the slices and slice gateways on the worker cluster
+	workerClient client.Reader
+}
+
+// NewProjectHubClients creates a hub client for each of the projects
+func NewProjectHubClients(projects []Project, workerClient client.Reader, er *monitoring.EventRecorder, mf metrics.MetricsFactory) (*ProjectHubClients, error) {
+	if len(projects) == 0 {
+		return nil, errors.New("no hub projects registered")
+	}
+	p := &ProjectHubClients{workerClient: workerClient}
+	for _, project := range projects {
+		c, err := NewProjectHubClientConfig(project, er, mf)
+		if err != nil {
+			return nil, err
+		}
+		p.clients = append(p.clients, c)
+	}
+	return p, nil
+}
+
+// Primary returns the client of the primary project
+func (p *ProjectHubClients) Primary() *HubClientConfig {
+	return p.clients[0]
+}
+
+// Clients returns the clients of every project, the primary project first
+func (p *ProjectHubClients) Clients() []*HubClientConfig {
+	return p.clients
+}
+
+// ForObject returns the client of the project of an object of the worker cluster
+func (p *ProjectHubClients) ForObject(obj metav1.Object) *HubClientConfig {
+	for _, c := range p.clients {
+		if c.Project.BelongsTo(obj) {
+			return c
+		}
+	}
+	return p.Primary()
+}
+
+// forSlice returns the client of the project of the slice and the name of the slice in the project
+func (p *ProjectHubClients) forSlice(ctx context.Context, sliceName string) (*HubClientConfig, string, error) {
+	return p.forWorkerObject(ctx, &kubeslicev1beta1.Slice{}, sliceName)
+}
+
+// forSliceGateway returns the client of the project of the slice gateway and the name of the gateway in the project
+func (p *ProjectHubClients) forSliceGateway(ctx context.Context, sliceGwName string) (*HubClientConfig, string, error) {
+	return p.forWorkerObject(ctx, &kubeslicev1beta1.SliceGateway{}, sliceGwName)
+}
+
+func (p *ProjectHubClients) forWorkerObject(ctx context.Context, obj client.Object, name string) (*HubClientConfig, string, error) {
+	// a worker registered in a single project does not need to look up its objects
+	if len(p.clients) == 1 {
+		return p.Primary(), name, nil
+	}
+	if err := p.workerClient.Get(ctx, types.NamespacedName{Name: name, Namespace: controlPlaneNamespace}, obj); err != nil {
+		return nil, "", err
+	}
+	c := p.ForObject(obj)
+	if hubName, ok := obj.GetAnnotations()[HubNameAnnotation]; ok {
+		return c, hubName, nil
+	}
+	return c, name, nil
+}
+
+// Status returns the state of the connection to the first project that is not connected, or the
+// state of the connection to the primary project if all of them are connected
+func (p *ProjectHubClients) Status() (ConnectionState, time.Time, error) {
+	for _, c := range p.clients {
+		if state, lastTransition, err := c.Connection.Status(); state != HubConnected {
+			return state, lastTransition, err
+		}
+	}
+	return p.Primary().Connection.Status()
+}
+
+// UpdateAppPodsList writes the app pods to the slice config of the worker, named <slice>-<cluster> in the project
+func (p *ProjectHubClients) UpdateAppPodsList(ctx context.Context, sliceConfigName string, appPods []kubeslicev1beta1.AppPod) error {
+	c, name, err := p.forSliceConfig(ctx, sliceConfigName)
+	if err != nil {
+		return err
+	}
+	return c.UpdateAppPodsList(ctx, name, appPods)
+}
+
+func (p *ProjectHubClients) UpdateAppNamespaces(ctx context.Context, sliceConfigName string, onboardedNamespaces []string) error {
+	c, name, err := p.forSliceConfig(ctx, sliceConfigName)
+	if err != nil {
+		return err
+	}
+	return c.UpdateAppNamespaces(ctx, name, onboardedNamespaces)
+}
+
+// forSliceConfig returns the client of the project of the slice config and the name of the slice config in the project
+func (p *ProjectHubClients) forSliceConfig(ctx context.Context, sliceConfigName string) (*HubClientConfig, string, error) {
+	suffix := "-" + ClusterName
+	c, slice, err := p.forSlice(ctx, strings.TrimSuffix(sliceConfigName, suffix))
+	if err != nil {
+		return nil, "", err
+	}
+	return c, slice + suffix, nil
+}
+
+// GetClusterNamespaceConfig returns the namespace labels and annotations of the cluster. They are
+// configured for the whole worker cluster in the primary project.
+func (p *ProjectHubClients) GetClusterNamespaceConfig(ctx context.Context, clusterName string) (map[string]string, map[string]string, error) {
+	return p.Primary().GetClusterNamespaceConfig(ctx, clusterName)
+}
+
+func (p *ProjectHubClients) UpdateNamespaceInfo(ctx context.Context, onboardNamespace, sliceName string) error {
+	c, name, err := p.forSlice(ctx, sliceName)
+	if err != nil {
+		return err
+	}
+	return c.UpdateNamespaceInfo(ctx, onboardNamespace, name)
+}
+
+// DeleteNamespaceInfo removes the namespace from the cluster object of every project
+func (p *ProjectHubClients) DeleteNamespaceInfo(ctx context.Context, onboardNamespace string) error {
+	var errs []error
+	for _, c := range p.clients {
+		if err := c.DeleteNamespaceInfo(ctx, onboardNamespace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *ProjectHubClients) UpdateNodePortForSliceGwServer(ctx context.Context, sliceGwNodePorts []int, sliceGwName string) error {
+	c, name, err := p.forSliceGateway(ctx, sliceGwName)
+	if err != nil {
+		return err
+	}
+	return c.UpdateNodePortForSliceGwServer(ctx, sliceGwNodePorts, name)
+}
+
+func (p *ProjectHubClients) UpdateLBIPsForSliceGwServer(ctx context.Context, lbIPs []string, sliceGwName string) error {
+	c, name, err := p.forSliceGateway(ctx, sliceGwName)
+	if err != nil {
+		return err
+	}
+	return c.UpdateLBIPsForSliceGwServer(ctx, lbIPs, name)
+}
+
+// GetClusterNodeIP returns the node IPs of the cluster in the project with the given namespace
+func (p *ProjectHubClients) GetClusterNodeIP(ctx context.Context, clusterName, namespace string) ([]string, error) {
+	for _, c := range p.clients {
+		if c.namespace() == namespace {
+			return c.GetClusterNodeIP(ctx, clusterName, namespace)
+		}
+	}
+	return p.Primary().GetClusterNodeIP(ctx, clusterName, namespace)
+}
+
+// GetClusterUnderlayMTU returns the underlay MTU of a cluster in the project of the slice gateway
+func (p *ProjectHubClients) GetClusterUnderlayMTU(ctx context.Context, sliceGwName, clusterName string) (int, error) {
+	c, _, err := p.forSliceGateway(ctx, sliceGwName)
+	if err != nil {
+		return 0, err
+	}
+	return c.GetClusterUnderlayMTU(ctx, clusterName)
+}
+
+// GetVPNKeyRotation returns the vpn key rotation of the slice, which is named after the slice in the project
+func (p *ProjectHubClients) GetVPNKeyRotation(ctx context.Context, rotationName string) (*hubv1alpha1.VpnKeyRotation, error) {
+	c, name, err := p.forSlice(ctx, rotationName)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetVPNKeyRotation(ctx, name)
+}
+
+func (p *ProjectHubClients) CreateWorkerSliceGwRecycler(ctx context.Context, gwRecyclerName, clientID, serverID, sliceGwServer, sliceGwClient, slice string) error {
+	c, slice, err := p.forSlice(ctx, slice)
+	if err != nil {
+		return err
+	}
+	// the client gateway is the remote gateway, which is already named as in the project
+	_, sliceGwServer, err = p.forSliceGateway(ctx, sliceGwServer)
+	if err != nil {
+		return err
+	}
+	return c.CreateWorkerSliceGwRecycler(ctx, gwRecyclerName, clientID, serverID, sliceGwServer, sliceGwClient, slice)
+}
+
+func (p *ProjectHubClients) ListWorkerSliceGwRecycler(ctx context.Context, sliceGWName string) ([]spokev1alpha1.WorkerSliceGwRecycler, error) {
+	c, name, err := p.forSliceGateway(ctx, sliceGWName)
+	if err != nil {
+		return nil, err
+	}
+	return c.ListWorkerSliceGwRecycler(ctx, name)
+}
+
+// DeleteWorkerSliceGwRecycler deletes the recycler from the project that has it. The recyclers are named
+// after the gateway pods, which do not record their project.
+func (p *ProjectHubClients) DeleteWorkerSliceGwRecycler(ctx context.Context, gwRecyclerName string) error {
+	var errs []error
+	for _, c := range p.clients {
+		if err := c.DeleteWorkerSliceGwRecycler(ctx, gwRecyclerName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *ProjectHubClients) UpdateServiceExport(ctx context.Context, serviceexport *kubeslicev1beta1.ServiceExport) error {
+	c, svcex, err := p.forServiceExport(ctx, serviceexport)
+	if err != nil {
+		return err
+	}
+	return c.UpdateServiceExport(ctx, svcex)
+}
+
+func (p *ProjectHubClients) UpdateServiceExportEndpointForIngressGw(ctx context.Context, serviceexport *kubeslicev1beta1.ServiceExport,
+	ep *kubeslicev1beta1.ServicePod) error {
+	c, svcex, err := p.forServiceExport(ctx, serviceexport)
+	if err != nil {
+		return err
+	}
+	return c.UpdateServiceExportEndpointForIngressGw(ctx, svcex, ep)
+}
+
+// DeleteServiceExport deletes the serviceexport from the project recorded in its annotations, the slice
+// of the serviceexport may already be gone
+func (p *ProjectHubClients) DeleteServiceExport(ctx context.Context, serviceexport *kubeslicev1beta1.ServiceExport) error {
+	return p.ForObject(serviceexport).DeleteServiceExport(ctx, serviceexport)
+}
+
+// forServiceExport returns the client of the project of the slice of the serviceexport, and a copy of the
+// serviceexport with the name of the slice in the project
+func (p *ProjectHubClients) forServiceExport(ctx context.Context, serviceexport *kubeslicev1beta1.ServiceExport) (*HubClientConfig, *kubeslicev1beta1.ServiceExport, error) {
+	c, slice, err := p.forSlice(ctx, serviceexport.Spec.Slice)
+	if err != nil {
+		return nil, nil, err
+	}
+	if slice == serviceexport.Spec.Slice {
+		return c, serviceexport, nil
+	}
+	svcex := serviceexport.DeepCopy()
+	svcex.Spec.Slice = slice
+	return c, svcex, nil
+}
@@ -25,11 +25,13 @@ import (
 )
 
 var (
-	ProjectNamespace     = os.Getenv("HUB_PROJECT_NAMESPACE")
-	HubEndpoint          = os.Getenv("HUB_HOST_ENDPOINT")
-	ClusterName          = os.Getenv("CLUSTER_NAME")
-	HubTokenFile         = utils.GetEnvOrDefault("HUB_TOKEN_FILE", "/var/run/secrets/kubernetes.io/hub-serviceaccount/token")
-	HubCAFile            = utils.GetEnvOrDefault("HUB_CA_FILE", "/var/run/secrets/kubernetes.io/hub-serviceaccount/ca.crt")
+	ProjectNamespace = os.Getenv("HUB_PROJECT_NAMESPACE")
+	HubEndpoint      = os.Getenv("HUB_HOST_ENDPOINT")
+	ClusterName      = os.Getenv("CLUSTER_NAME")
+	HubTokenFile     = utils.GetEnvOrDefault("HUB_TOKEN_FILE", "/var/run/secrets/kubernetes.io/hub-serviceaccount/token")
+	HubCAFile        = utils.GetEnvOrDefault("HUB_CA_FILE", "/var/run/secrets/kubernetes.io/hub-serviceaccount/ca.crt")
+	// HubProjectsFile lists the hub projects the worker is registered in, see LoadProjects
+	HubProjectsFile      = os.Getenv("HUB_PROJECTS_FILE")
 	KubeSliceDashboardSA = "kubeslice-kubernetes-dashboard"
 	HubSecretSuffix      = "-kubernetes-dashboard"
 )
@@ -23,10 +23,10 @@ import (
 	"os"
 	"strings"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -48,10 +48,13 @@ import (
 	hubCluster "github.com/kubeslice/worker-operator/pkg/hub/controllers/cluster"
 	"github.com/kubeslice/worker-operator/pkg/hub/controllers/vpnkeyrotation"
 
+	"github.com/kubeslice/worker-operator/pkg/debug"
 	"github.com/kubeslice/worker-operator/pkg/hub/controllers/workerslicegwrecycler"
+	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
 	"github.com/kubeslice/worker-operator/pkg/logger"
 	"github.com/kubeslice/worker-operator/pkg/router"
 	"github.com/kubeslice/worker-operator/pkg/slicegwrecycler"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -66,27 +69,59 @@ func init() {
 	utilruntime.Must(hubv1alpha1.AddToScheme(scheme))
 }
 
-func Start(meshClient client.Client, hubClient client.Client, ctx context.Context) {
-	config := &rest.Config{
-		Host:            os.Getenv("HUB_HOST_ENDPOINT"),
-		BearerTokenFile: HubTokenFile,
-		TLSClientConfig: rest.TLSClientConfig{
-			CAFile: HubCAFile,
-		},
+// Runnable runs the hub manager of a project within the manager of the worker controllers. The hub
+// controllers need leader election, so only the elected replica of the operator reconciles the objects
+// of the hub and a standby replica takes over when the leader goes away.
+type Runnable struct {
+	meshClient client.Client
+	hubClient  *hub.HubClientConfig
+	hubClients *hub.ProjectHubClients
+}
+
+// NewRunnable returns the runnable of the hub manager of the project of hubClient
+func NewRunnable(meshClient client.Client, hubClient *hub.HubClientConfig, hubClients *hub.ProjectHubClients) *Runnable {
+	return &Runnable{
+		meshClient: meshClient,
+		hubClient:  hubClient,
+		hubClients: hubClients,
 	}
+}
 
-	var log = log.Log.WithName("hub")
+// Start runs the hub manager until the context is cancelled. It implements manager.Runnable.
+func (r *Runnable) Start(ctx context.Context) error {
+	Start(r.meshClient, r.hubClient, r.hubClients, ctx)
+	return nil
+}
 
-	log.Info("Connecting to hub cluster", "endpoint", HubEndpoint, "ns", ProjectNamespace)
+// NeedLeaderElection returns true, the hub controllers run on the elected replica only
+func (r *Runnable) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the controllers of the hub project of hubClient until the context is cancelled.
+// hubClients routes the writes made for the gateways of the worker to the project they belong to.
+func Start(meshClient client.Client, hubClient *hub.HubClientConfig, hubClients *hub.ProjectHubClients, ctx context.Context) {
+	project := hubClient.Project
+	config := hub.NewProjectRestConfig(project)
+
+	var log = log.Log.WithName("hub").WithValues("project", project.Name)
+
+	log.Info("Connecting to hub cluster", "endpoint", project.Endpoint, "ns", project.Namespace)
+	// the caches of the hub manager cannot sync while the hub is unreachable, wait for it instead of
+	// failing so that the worker controllers keep running the existing slices during a hub outage
+	if err := hubClient.Connection.WaitForConnection(ctx); err != nil {
+		log.Error(err, "stopped waiting for hub connection")
+		return
+	}
 
 	webhookServer := webhook.NewServer(webhook.Options{
-		Host: HubEndpoint,
+		Host: project.Endpoint,
 		Port: 9443,
 	})
 
 	cacheOptions := cache.Options{
 		DefaultNamespaces: map[string]cache.Config{
-			ProjectNamespace: {},
+			project.Namespace: {},
 		},
 	}
 
@@ -105,10 +140,11 @@ func Start(meshClient client.Client, hubClient client.Client, ctx context.Contex
 		os.Exit(1)
 	}
 
+	// the controllers of every project register the same metrics, tell them apart by the project label
 	mf, _ := metrics.NewMetricsFactory(
-		ctrlmetrics.Registry,
+		prometheus.WrapRegistererWith(prometheus.Labels{"hub_project": project.Name}, ctrlmetrics.Registry),
 		metrics.MetricsFactoryOptions{
-			Project:             strings.TrimPrefix(ProjectNamespace, "kubeslice_"),
+			Project:             strings.TrimPrefix(project.Namespace, "kubeslice_"),
 			Cluster:             ClusterName,
 			ReportingController: "workerOperator",
 		},
@@ -117,7 +153,7 @@ func Start(meshClient client.Client, hubClient client.Client, ctx context.Contex
 	// create slice-controller recorder
 	workerSliceEventRecorder := mevents.NewEventRecorder(meshClient, mgr.GetScheme(), ossEvents.EventsMap, mevents.EventRecorderOptions{
 		Cluster:   ClusterName,
-		Project:   ProjectNamespace,
+		Project:   project.Namespace,
 		Component: "workerSliceController",
 		Namespace: controllers.ControlPlaneNamespace,
 		Version:   utils.EventsVersion,
@@ -130,13 +166,14 @@ func Start(meshClient client.Client, hubClient client.Client, ctx context.Contex
 		&workerSliceEventRecorder,
 		mf,
 	)
+	sliceReconciler.Project = project
 	err = builder.
 		ControllerManagedBy(mgr).
 		For(&workerv1alpha1.WorkerSliceConfig{}).
 		WithEventFilter(predicate.NewPredicateFuncs(func(object client.Object) bool {
 			return object.GetLabels()["worker-cluster"] == ClusterName
 		})).
-		Complete(sliceReconciler)
+		Complete(tracing.NewReconciler("workersliceconfig", sliceReconciler))
 	if err != nil {
 		log.Error(err, "could not create controller")
 		os.Exit(1)
@@ -147,6 +184,7 @@ func Start(meshClient client.Client, hubClient client.Client, ctx context.Contex
 		MeshClient:    meshClient,
 		EventRecorder: &workerSliceEventRecorder,
 		ClusterName:   ClusterName,
+		Project:       project,
 	}
 	err = builder.
 		ControllerManagedBy(mgr).
@@ -154,7 +192,7 @@ func Start(meshClient client.Client, hubClient client.Client, ctx context.Contex
 		WithEventFilter(predicate.NewPredicateFuncs(func(object client.Object) bool {
 			return object.GetLabels()["worker-cluster"] == ClusterName
 		})).
-		Complete(sliceGwReconciler)
+		Complete(tracing.NewReconciler("workerslicegateway", sliceGwReconciler))
 	if err != nil {
 		log.Error(err, "could not create controller")
 		os.Exit(1)
@@ -164,6 +202,7 @@ func Start(meshClient client.Client, hubClient client.Client, ctx context.Contex
 		Client:        mgr.GetClient(),
 		MeshClient:    meshClient,
 		EventRecorder: &workerSliceEventRecorder,
+		Project:       project,
 	}
 	err = builder.
 		ControllerManagedBy(mgr).
@@ -171,7 +210,7 @@ func Start(meshClient client.Client, hubClient client.Client, ctx context.Contex
 		WithEventFilter(predicate.NewPredicateFuncs(func(object client.Object) bool {
 			return object.GetLabels()["worker-cluster"] == ClusterName
 		})).
-		Complete(serviceImportReconciler)
+		Complete(tracing.NewReconciler("workerserviceimport", serviceImportReconciler))
 	if err != nil {
 		log.Error(err, "could not create controller")
 		os.Exit(1)
@@ -188,7 +227,7 @@ func Start(meshClient client.Client, hubClient client.Client, ctx context.Contex
 		os.Exit(1)
 	}
 
-	if err := (&workerslicegwrecycler.Reconciler{
+	recyclerReconciler := &workerslicegwrecycler.Reconciler{
 		MeshClient:            meshClient,
 		Log:                   ctrl.Log.WithName("controllers").WithName("workerslicegwrecycler"),
 		Scheme:                mgr.GetScheme(),
@@ -196,10 +235,15 @@ func Start(meshClient client.Client, hubClient client.Client, ctx context.Contex
 		WorkerGWSidecarClient: workerGWClient,
 		WorkerRouterClient:    workerRouterClient,
 		EventRecorder:         &workerSliceEventRecorder,
-	}).SetupWithManager(mgr); err != nil {
+		Project:               project,
+	}
+	if err := recyclerReconciler.SetupWithManager(mgr); err != nil {
 		log.Error(err, "could not create controller")
 		os.Exit(1)
 	}
+	// the recyclers of every project are dumped by the debug endpoint
+	debug.Register("workerslicegwrecycler/"+project.Name, recyclerReconciler.DebugState)
+	defer debug.Unregister("workerslicegwrecycler/" + project.Name)
 
 	clusterReconciler := hubCluster.NewReconciler(
 		mgr.GetClient(),
@@ -207,20 +251,21 @@ func Start(meshClient client.Client, hubClient client.Client, ctx context.Contex
 		&workerSliceEventRecorder,
 		mf,
 	)
+	clusterReconciler.Project = project
 	err = builder.
 		ControllerManagedBy(mgr).
 		For(&hubv1alpha1.Cluster{}).
 		WithEventFilter(predicate.NewPredicateFuncs(func(object client.Object) bool {
 			return object.GetName() == ClusterName
 		})).
-		Complete(clusterReconciler)
+		Complete(tracing.NewReconciler("cluster", clusterReconciler))
 	if err != nil {
 		log.Error(err, "could not create cluster controller")
 		os.Exit(1)
 	}
 
 	workerRecyclerClient, err := slicegwrecycler.NewRecyclerClient(
-		ctx, meshClient, hubClient, &workerSliceEventRecorder, mgr.GetScheme(),
+		ctx, meshClient, hubClients, &workerSliceEventRecorder, mgr.GetScheme(),
 	)
 	if err != nil {
 		os.Exit(1)
@@ -232,6 +277,7 @@ func Start(meshClient client.Client, hubClient client.Client, ctx context.Contex
 		mf,
 		workerRecyclerClient,
 	)
+	vpnKeyRotationReconciler.Project = project
 	err = builder.
 		ControllerManagedBy(mgr).
 		For(&hubv1alpha1.VpnKeyRotation{}).
@@ -239,7 +285,7 @@ func Start(meshClient client.Client, hubClient client.Client, ctx context.Contex
 		WithEventFilter(predicate.NewPredicateFuncs(func(object client.Object) bool {
 			return shouldProcessVpnKeyRotation(object)
 		})).
-		Complete(vpnKeyRotationReconciler)
+		Complete(tracing.NewReconciler("vpnkeyrotation", vpnKeyRotationReconciler))
 	if err != nil {
 		log.Error(err, "could not create vpn key rotation controller")
 		os.Exit(1)
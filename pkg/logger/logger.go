@@ -21,6 +21,7 @@ package logger
 import (
 	"context"
 	"os"
+	"sync/atomic"
 
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
@@ -29,7 +30,8 @@ import (
 )
 
 var (
-	logLevel string
+	// logLevel holds the current log level, it can be changed at runtime with SetLogLevel
+	logLevel atomic.Value
 	// ControlPlaneNamespace is the namespace where slice operator is running
 	ControlPlaneNamespace = "kubeslice-system"
 	// Name of the cluster
@@ -46,10 +48,24 @@ var logLevelSeverity = map[string]zapcore.Level{
 type loggerKey struct{}
 
 func init() {
-	logLevel = os.Getenv("LOG_LEVEL")
-	if logLevel == "" {
-		logLevel = "INFO"
+	SetLogLevel("")
+}
+
+// SetLogLevel changes the level of all the loggers created by this package.
+// An empty or unknown level resets it to the LOG_LEVEL env, or INFO when that is not set.
+func SetLogLevel(level string) {
+	if _, ok := logLevelSeverity[level]; !ok {
+		level = os.Getenv("LOG_LEVEL")
+	}
+	if level == "" {
+		level = "INFO"
 	}
+	logLevel.Store(level)
+}
+
+// GetLogLevel returns the current log level
+func GetLogLevel() string {
+	return logLevel.Load().(string)
 }
 
 // WithLogger takes in a context and returns a context with key as loggerKey{} and value as loggger(of type logr.Logger) passed
@@ -81,7 +97,7 @@ func NewLogger() *uzap.SugaredLogger {
 
 	// info and debug level enabler
 	debugInfoLevel := uzap.LevelEnablerFunc(func(level zapcore.Level) bool {
-		return level >= logLevelSeverity[logLevel] && level < zapcore.ErrorLevel
+		return level >= logLevelSeverity[GetLogLevel()] && level < zapcore.ErrorLevel
 	})
 
 	// error and fatal level enabler
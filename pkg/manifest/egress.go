@@ -20,7 +20,6 @@ package manifest
 
 import (
 	"context"
-	"os"
 
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
 	istiov1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
@@ -44,10 +43,7 @@ import (
 //	gateway
 func InstallEgress(ctx context.Context, c client.Client, slice *kubeslicev1beta1.Slice) error {
 	sliceName := slice.Name
-	istioProxyImage := os.Getenv("AVESHA_ISTIO_PROXY_IMAGE")
-	if istioProxyImage == "" {
-		istioProxyImage = ISTIO_PROXY_DEFAULT_IMAGE
-	}
+	istioProxyImage := getIstioProxyImage()
 
 	templates := map[string]string{"SLICE": sliceName, "ProxyImgRef": istioProxyImage}
 
@@ -102,8 +98,13 @@ func InstallEgress(ctx context.Context, c client.Client, slice *kubeslicev1beta1
 		ctrl.SetControllerReference(slice, o, c.Scheme())
 
 		if err := c.Create(ctx, o); err != nil {
-			// Ignore if already exists
+			// Ignore if already exists, but roll out a changed istio proxy image
 			if errors.IsAlreadyExists(err) {
+				if o == deploy {
+					if err := updateIstioProxyImage(ctx, c, deploy, istioProxyImage); err != nil {
+						return err
+					}
+				}
 				continue
 			}
 			return err
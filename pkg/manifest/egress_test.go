@@ -0,0 +1,60 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+package manifest_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/manifest"
+	istiov1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Istio egress gateway", func() {
+
+	It("Should update the istio proxy image of an existing deployment", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(kubeslicev1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(istiov1beta1.AddToScheme(scheme)).To(Succeed())
+
+		slice := &kubeslicev1beta1.Slice{
+			ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: "kubeslice-system", UID: "slice-uid"},
+		}
+		deploy := &appsv1.Deployment{}
+		Expect(manifest.NewManifest("egress-deploy", map[string]string{"SLICE": "green", "ProxyImgRef": "istio/proxyv2:old"}).Parse(deploy)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(slice, deploy).Build()
+
+		Expect(manifest.InstallEgress(context.Background(), c, slice)).To(Succeed())
+
+		updated := &appsv1.Deployment{}
+		Expect(c.Get(context.Background(), types.NamespacedName{Name: "green-istio-egressgateway", Namespace: "kubeslice-system"}, updated)).To(Succeed())
+		Expect(updated.Spec.Template.Spec.Containers[0].Name).To(Equal("istio-proxy"))
+		Expect(updated.Spec.Template.Spec.Containers[0].Image).To(Equal(manifest.ISTIO_PROXY_DEFAULT_IMAGE))
+	})
+
+})
@@ -20,9 +20,9 @@ package manifest
 
 import (
 	"context"
-	"os"
 
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
 	istiov1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -35,6 +35,33 @@ import (
 
 const ISTIO_PROXY_DEFAULT_IMAGE = "aveshasystems/istio/proxyv2:1.16.0"
 
+func getIstioProxyImage() string {
+	istioProxyImage := operatorconfig.Image(operatorconfig.IstioProxyImageEnv)
+	if istioProxyImage == "" {
+		istioProxyImage = ISTIO_PROXY_DEFAULT_IMAGE
+	}
+	return istioProxyImage
+}
+
+// updateIstioProxyImage updates the istio-proxy container of an existing istio gw deployment
+// if its image is not the configured one
+func updateIstioProxyImage(ctx context.Context, c client.Client, deploy *appsv1.Deployment, image string) error {
+	existing := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(deploy), existing); err != nil {
+		return err
+	}
+	for i := range existing.Spec.Template.Spec.Containers {
+		container := &existing.Spec.Template.Spec.Containers[i]
+		if container.Name != "istio-proxy" || container.Image == image {
+			continue
+		}
+		log.Info("updating istio gw Deployment", "Name", existing.Name, "image", image)
+		container.Image = image
+		return c.Update(ctx, existing)
+	}
+	return nil
+}
+
 // Install istio ingress gw resources on the given cluster in a slice
 // Resources:
 //
@@ -45,10 +72,7 @@ const ISTIO_PROXY_DEFAULT_IMAGE = "aveshasystems/istio/proxyv2:1.16.0"
 //	service (type clusterip)
 func InstallIngress(ctx context.Context, c client.Client, slice *kubeslicev1beta1.Slice) error {
 	sliceName := slice.Name
-	istioProxyImage := os.Getenv("AVESHA_ISTIO_PROXY_IMAGE")
-	if istioProxyImage == "" {
-		istioProxyImage = ISTIO_PROXY_DEFAULT_IMAGE
-	}
+	istioProxyImage := getIstioProxyImage()
 
 	templates := map[string]string{"SLICE": sliceName, "ProxyImgRef": istioProxyImage}
 
@@ -102,8 +126,13 @@ func InstallIngress(ctx context.Context, c client.Client, slice *kubeslicev1beta
 		ctrl.SetControllerReference(slice, o, c.Scheme())
 
 		if err := c.Create(ctx, o); err != nil {
-			// Ignore if already exists
+			// Ignore if already exists, but roll out a changed istio proxy image
 			if errors.IsAlreadyExists(err) {
+				if o == deploy {
+					if err := updateIstioProxyImage(ctx, c, deploy, istioProxyImage); err != nil {
+						return err
+					}
+				}
 				continue
 			}
 			return err
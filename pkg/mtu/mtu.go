@@ -0,0 +1,180 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package mtu computes the MTU of the slice overlay from the MTU of the underlay network between
+// the clusters, so that the packets encapsulated by the gateways are not fragmented.
+package mtu
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubeslice/worker-operator/controllers"
+	"github.com/kubeslice/worker-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// DefaultUnderlayMTU is the MTU of the underlay network for gateway nodes without the underlay mtu annotation
+	DefaultUnderlayMTU = utils.GetEnvOrDefault("UNDERLAY_MTU", "1500")
+)
+
+const (
+	// UnderlayAnnotation on a gateway node sets the MTU of the underlay network reachable from the node.
+	// The worker publishes the underlay MTU of its gateway nodes with the same annotation on its cluster
+	// object on the hub, for the remote clusters to size the tunnels to the smaller MTU of both ends.
+	UnderlayAnnotation = "kubeslice.io/underlay-mtu"
+	// TunnelAnnotation on the gateway pods records the MTU written to the openvpn server config, so that
+	// the pods restart when it changes
+	TunnelAnnotation = "kubeslice.io/tunnel-mtu"
+
+	defaultUnderlayMTU = 1500
+	// MinOverlayMTU is the smallest overlay MTU, every IPv4 host has to accept packets of this size
+	MinOverlayMTU = 576
+
+	ipv4Header = 20
+	udpHeader  = 8
+	// tcp header with the timestamp option and the length prefix of the openvpn packets over tcp
+	tcpHeader = 32 + 2
+	// openvpn opcode and peer id, packet id, IV, HMAC and padding to the cipher block size
+	openVPNOverhead = 80
+)
+
+// MTU is the MTU of a gateway tunnel
+type MTU struct {
+	// Underlay is the MTU of the network between the gateway nodes of the clusters
+	Underlay int
+	// Overlay is the MTU of the tunnel interface of the gateways and of the slice interfaces
+	Overlay int
+	// MSSFix is the largest size of the encapsulated packets sent by the gateways, passed as --mssfix
+	MSSFix int
+}
+
+// Compute returns the MTU of a tunnel of the gateway protocol, TCP or UDP, over an underlay network
+func Compute(underlay int, protocol string) MTU {
+	transport := udpHeader
+	if strings.EqualFold(protocol, "tcp") {
+		transport = tcpHeader
+	}
+	m := MTU{
+		Underlay: underlay,
+		Overlay:  underlay - ipv4Header - transport - openVPNOverhead,
+		MSSFix:   underlay - ipv4Header - transport,
+	}
+	if m.Overlay < MinOverlayMTU {
+		m.Overlay = MinOverlayMTU
+	}
+	if m.MSSFix < m.Overlay {
+		m.MSSFix = m.Overlay
+	}
+	return m
+}
+
+// Underlay returns the MTU of the underlay network, the smallest MTU annotated on the gateway nodes.
+// Nodes without the annotation have the default underlay MTU.
+func Underlay(ctx context.Context, c client.Client) (int, error) {
+	def, err := strconv.Atoi(DefaultUnderlayMTU)
+	if err != nil || def <= 0 {
+		def = defaultUnderlayMTU
+	}
+	nodes := &corev1.NodeList{}
+	if err := c.List(ctx, nodes, client.MatchingLabels{controllers.NodeTypeSelectorLabelKey: "gateway"}); err != nil {
+		return 0, err
+	}
+	underlay := 0
+	for _, node := range nodes.Items {
+		m := def
+		if n := ParseUnderlay(node.Annotations); n > 0 {
+			m = n
+		}
+		if underlay == 0 || m < underlay {
+			underlay = m
+		}
+	}
+	if underlay == 0 {
+		underlay = def
+	}
+	return underlay, nil
+}
+
+// Tunnel returns the MTU of a tunnel of the gateway protocol between two clusters, over the smaller
+// underlay MTU of both ends. A remote underlay MTU of 0 is unknown and ignored.
+func Tunnel(localUnderlay, remoteUnderlay int, protocol string) MTU {
+	underlay := localUnderlay
+	if remoteUnderlay > 0 && remoteUnderlay < underlay {
+		underlay = remoteUnderlay
+	}
+	return Compute(underlay, protocol)
+}
+
+// ParseUnderlay returns the underlay MTU annotated on an object, 0 if it is not annotated
+func ParseUnderlay(annotations map[string]string) int {
+	n, err := strconv.Atoi(annotations[UnderlayAnnotation])
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n
+}
+
+// OpenVPNArgs returns the args of the openvpn client with the --tun-mtu and --mssfix options set to the
+// MTU, replacing the values already present. The openvpn client is run with its args as is.
+func OpenVPNArgs(args []string, m MTU) []string {
+	return append(withoutOpenVPNArgs(args),
+		"--tun-mtu", strconv.Itoa(m.Overlay),
+		"--mssfix", strconv.Itoa(m.MSSFix),
+	)
+}
+
+// withoutOpenVPNArgs returns args without the --tun-mtu and --mssfix options
+func withoutOpenVPNArgs(args []string) []string {
+	updated := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--tun-mtu" || args[i] == "--mssfix" {
+			i++
+			continue
+		}
+		updated = append(updated, args[i])
+	}
+	return updated
+}
+
+// OpenVPNServerArgs returns the args of the openvpn server without the MTU options, the server is
+// started by a script of the image and reads the MTU from its config, see OpenVPNConfig
+func OpenVPNServerArgs(args []string) []string {
+	return withoutOpenVPNArgs(args)
+}
+
+// OpenVPNConfig returns the openvpn config with the tun-mtu and mssfix directives set to the MTU,
+// replacing the directives already present
+func OpenVPNConfig(config string, m MTU) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(config, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && (fields[0] == "tun-mtu" || fields[0] == "mssfix") {
+			continue
+		}
+		b.WriteString(line)
+	}
+	if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "tun-mtu %d\nmssfix %d\n", m.Overlay, m.MSSFix)
+	return b.String()
+}
@@ -0,0 +1,169 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package mtu
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func gatewayNode(name, underlay string) *corev1.Node {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{"kubeslice.io/node-type": "gateway"},
+	}}
+	if underlay != "" {
+		node.Annotations = map[string]string{UnderlayAnnotation: underlay}
+	}
+	return node
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		underlay int
+		protocol string
+		expected MTU
+	}{
+		{1500, "UDP", MTU{Underlay: 1500, Overlay: 1392, MSSFix: 1472}},
+		{1460, "UDP", MTU{Underlay: 1460, Overlay: 1352, MSSFix: 1432}},
+		{1500, "TCP", MTU{Underlay: 1500, Overlay: 1366, MSSFix: 1446}},
+		{1460, "", MTU{Underlay: 1460, Overlay: 1352, MSSFix: 1432}},
+		{600, "UDP", MTU{Underlay: 600, Overlay: MinOverlayMTU, MSSFix: MinOverlayMTU}},
+	}
+	for _, tt := range tests {
+		if got := Compute(tt.underlay, tt.protocol); got != tt.expected {
+			t.Errorf("Compute(%d, %q) = %+v, expected %+v", tt.underlay, tt.protocol, got, tt.expected)
+		}
+	}
+}
+
+func TestUnderlay(t *testing.T) {
+	tests := []struct {
+		name     string
+		objs     []runtime.Object
+		expected int
+	}{
+		{"no gateway nodes", nil, 1500},
+		{"nodes without annotation", []runtime.Object{gatewayNode("node-1", "")}, 1500},
+		{"smallest annotated mtu", []runtime.Object{
+			gatewayNode("node-1", "1500"), gatewayNode("node-2", "1460"), gatewayNode("node-3", "invalid"),
+		}, 1460},
+		{"other nodes are ignored", []runtime.Object{
+			gatewayNode("node-1", "1460"),
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+				Name:        "node-2",
+				Annotations: map[string]string{UnderlayAnnotation: "1280"},
+			}},
+		}, 1460},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithRuntimeObjects(tt.objs...).Build()
+			got, err := Underlay(context.Background(), c)
+			if err != nil {
+				t.Fatal("unexpected error: ", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTunnel(t *testing.T) {
+	tests := []struct {
+		local, remote int
+		expected      int
+	}{
+		{1500, 1460, 1460},
+		{1460, 1500, 1460},
+		{1500, 0, 1500},
+	}
+	for _, tt := range tests {
+		if got := Tunnel(tt.local, tt.remote, "UDP"); got != Compute(tt.expected, "UDP") {
+			t.Errorf("Tunnel(%d, %d) = %+v, expected the mtu of a %d bytes underlay", tt.local, tt.remote, got, tt.expected)
+		}
+	}
+}
+
+func TestParseUnderlay(t *testing.T) {
+	tests := []struct {
+		annotations map[string]string
+		expected    int
+	}{
+		{nil, 0},
+		{map[string]string{UnderlayAnnotation: "1460"}, 1460},
+		{map[string]string{UnderlayAnnotation: "invalid"}, 0},
+		{map[string]string{UnderlayAnnotation: "-1"}, 0},
+	}
+	for _, tt := range tests {
+		if got := ParseUnderlay(tt.annotations); got != tt.expected {
+			t.Errorf("ParseUnderlay(%v) = %d, expected %d", tt.annotations, got, tt.expected)
+		}
+	}
+}
+
+func TestOpenVPNArgs(t *testing.T) {
+	m := MTU{Underlay: 1460, Overlay: 1352, MSSFix: 1432}
+	args := OpenVPNArgs([]string{"/vpnclient/openvpn.conf", "90", "openvpn", "--config", "/vpnclient/openvpn.conf"}, m)
+	expected := []string{"/vpnclient/openvpn.conf", "90", "openvpn", "--config", "/vpnclient/openvpn.conf", "--tun-mtu", "1352", "--mssfix", "1432"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+	m.Overlay = 1392
+	m.MSSFix = 1472
+	args = OpenVPNArgs(args, m)
+	expected = []string{"/vpnclient/openvpn.conf", "90", "openvpn", "--config", "/vpnclient/openvpn.conf", "--tun-mtu", "1392", "--mssfix", "1472"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected the mtu options to be replaced, got %v", args)
+	}
+}
+
+func TestOpenVPNServerArgs(t *testing.T) {
+	args := OpenVPNServerArgs([]string{"/etc/openvpn/openvpn.conf", "90", "ovpn_run", "--tun-mtu", "1352", "--mssfix", "1432"})
+	expected := []string{"/etc/openvpn/openvpn.conf", "90", "ovpn_run"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected the mtu options to be removed, got %v", args)
+	}
+}
+
+func TestOpenVPNConfig(t *testing.T) {
+	m := MTU{Underlay: 1460, Overlay: 1352, MSSFix: 1432}
+	config := OpenVPNConfig("dev tun\nproto udp", m)
+	expected := "dev tun\nproto udp\ntun-mtu 1352\nmssfix 1432\n"
+	if config != expected {
+		t.Errorf("expected %q, got %q", expected, config)
+	}
+	m.Overlay = 1392
+	m.MSSFix = 1472
+	config = OpenVPNConfig(config, m)
+	expected = "dev tun\nproto udp\ntun-mtu 1392\nmssfix 1472\n"
+	if config != expected {
+		t.Errorf("expected the mtu directives to be replaced, got %q", config)
+	}
+	if OpenVPNConfig(config, m) != config {
+		t.Error("expected no change for the same mtu")
+	}
+}
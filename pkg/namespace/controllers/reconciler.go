@@ -19,7 +19,6 @@ package namespace
 
 import (
 	"context"
-	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/kubeslice/kubeslice-monitoring/pkg/events"
@@ -27,8 +26,9 @@ import (
 
 	"github.com/kubeslice/worker-operator/controllers"
 
-	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
 	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -41,12 +41,18 @@ import (
 
 //+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;
 
+// HubClientProvider records the namespaces of the worker in the cluster object on the hub
+type HubClientProvider interface {
+	UpdateNamespaceInfo(ctx context.Context, onboardNamespace, sliceName string) error
+	DeleteNamespaceInfo(ctx context.Context, onboardNamespace string) error
+}
+
 type Reconciler struct {
 	client.Client
 	EventRecorder *events.EventRecorder
 	Scheme        *runtime.Scheme
 	Log           logr.Logger
-	Hubclient     *hub.HubClientConfig
+	Hubclient     HubClientProvider
 }
 
 var excludedNs []string
@@ -69,8 +75,7 @@ func (c *Reconciler) getSliceNameFromNs(ns string) (string, error) {
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 
-	excludedNsEnv := utils.GetEnvOrDefault("EXCLUDED_NS", utils.DefaultExcludedNS)
-	excludedNs = strings.Split(excludedNsEnv, ",")
+	excludedNs = operatorconfig.ExcludedNamespaces()
 	for _, v := range excludedNs {
 		if v == req.Name {
 			return ctrl.Result{}, nil
@@ -83,7 +88,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err != nil {
 		if errors.IsNotFound(err) {
 			log.Info("Namespace deleted on worker cluster, updating the cluster CR on conrtoller cluster")
-			err := hub.DeleteNamespaceInfoFromHub(ctx, r.Hubclient, req.Name)
+			err := r.Hubclient.DeleteNamespaceInfo(ctx, req.Name)
 			if err != nil {
 				utils.RecordEvent(ctx, r.EventRecorder, &namespace, nil, ossEvents.EventDeleteNamespaceInfoToHubFailed, controllerName)
 				log.Error(err, "Failed to delete namespace on controller cluster")
@@ -104,7 +109,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 	*r.EventRecorder = (*r.EventRecorder).WithSlice(sliceName)
-	err = hub.UpdateNamespaceInfoToHub(ctx, r.Hubclient, namespace.Name, sliceName)
+	err = r.Hubclient.UpdateNamespaceInfo(ctx, namespace.Name, sliceName)
 	if err != nil {
 		utils.RecordEvent(ctx, r.EventRecorder, &namespace, nil, ossEvents.EventUpdateNamespaceInfoToHubFailed, controllerName)
 		log.Error(err, "Failed to post namespace on controller cluster")
@@ -118,5 +123,5 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Namespace{}).
-		Complete(r)
+		Complete(tracing.NewReconciler("namespace", r))
 }
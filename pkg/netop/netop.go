@@ -24,6 +24,8 @@ import (
 
 	sidecar "github.com/kubeslice/netops/pkg/proto"
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/qos"
+	"github.com/kubeslice/worker-operator/pkg/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -45,7 +47,12 @@ func NewWorkerNetOpClientProvider() (*netopSidecarClient, error) {
 }
 
 func (spoke netopSidecarClient) UpdateSliceQosProfile(ctx context.Context, addr string, slice *kubeslicev1beta1.Slice) error {
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// the profile is validated before dialing, an invalid profile fails on every node alike
+	discipline, err := qos.Resolve(slice.Status.SliceConfig.QosProfileDetails)
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()), tracing.DialOption())
 	if err != nil {
 		return err
 	}
@@ -53,15 +60,12 @@ func (spoke netopSidecarClient) UpdateSliceQosProfile(ctx context.Context, addr
 
 	client := sidecar.NewNetOpsServiceClient(conn)
 
-	// TODO change later if we add more TC types
-	tcType := sidecar.TcType_BANDWIDTH_CONTROL
-
 	qop := &sidecar.SliceQosProfile{
 		SliceName:      slice.Name,
 		SliceId:        slice.Name,
 		QosProfileName: slice.Name,
-		TcType:         tcType,
-		ClassType:      sidecar.ClassType_HTB,
+		TcType:         sidecar.TcType(discipline.TcTypeValue),
+		ClassType:      sidecar.ClassType(discipline.ClassTypeValue),
 		BwCeiling:      uint32(slice.Status.SliceConfig.QosProfileDetails.BandwidthCeilingKbps),
 		BwGuaranteed:   uint32(slice.Status.SliceConfig.QosProfileDetails.BandwidthGuaranteedKbps),
 		Priority:       uint32(slice.Status.SliceConfig.QosProfileDetails.Priority),
@@ -73,7 +77,7 @@ func (spoke netopSidecarClient) UpdateSliceQosProfile(ctx context.Context, addr
 }
 
 func (spoke netopSidecarClient) SendSliceLifeCycleEventToNetOp(ctx context.Context, addr string, sliceName string, eventType EventType) error {
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()), tracing.DialOption())
 	if err != nil {
 		return err
 	}
@@ -99,7 +103,7 @@ func (spoke netopSidecarClient) SendSliceLifeCycleEventToNetOp(ctx context.Conte
 
 // SendConnectionContext sends sonnectioncontext to netop sidecar
 func (spoke netopSidecarClient) SendConnectionContext(ctx context.Context, serverAddr string, gw *kubeslicev1beta1.SliceGateway, sliceGwNodePorts []int) error {
-	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), tracing.DialOption())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,66 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TypeNetworkPolicy installs one networking.k8s.io/v1 NetworkPolicy per application namespace
+	TypeNetworkPolicy = "NetworkPolicy"
+	// TypeAdminNetworkPolicy installs one policy.networking.k8s.io AdminNetworkPolicy per slice
+	TypeAdminNetworkPolicy = "AdminNetworkPolicy"
+	// TypeCiliumClusterwideNetworkPolicy installs one cilium.io CiliumClusterwideNetworkPolicy per slice
+	TypeCiliumClusterwideNetworkPolicy = "CiliumClusterwideNetworkPolicy"
+	// TypeCalicoGlobalNetworkPolicy installs one projectcalico.org GlobalNetworkPolicy per slice
+	TypeCalicoGlobalNetworkPolicy = "CalicoGlobalNetworkPolicy"
+)
+
+// Backend enforces namespace isolation for a slice on the worker cluster
+type Backend interface {
+	// Type returns the name of the backend
+	Type() string
+	// Install applies the isolation policy of the slice to the given application namespaces
+	Install(ctx context.Context, slice *kubeslicev1beta1.Slice, appNamespaces []string) error
+	// UnbindNamespace removes the isolation policy of the slice from a namespace that left the slice
+	UnbindNamespace(ctx context.Context, slice *kubeslicev1beta1.Slice, appNs string) error
+	// Uninstall removes every isolation policy installed for the slice
+	Uninstall(ctx context.Context, slice *kubeslicev1beta1.Slice) error
+}
+
+// New returns the backend for the given type. Cluster scoped backends require the
+// corresponding CRDs to be installed by the CNI.
+func New(backendType string, c client.Client) (Backend, error) {
+	switch backendType {
+	case "", TypeNetworkPolicy:
+		return NewNetworkPolicyBackend(c), nil
+	case TypeAdminNetworkPolicy:
+		return &clusterScopedBackend{Client: c, backendType: backendType, gvk: adminNetworkPolicyGVK, build: buildAdminNetworkPolicySpec}, nil
+	case TypeCiliumClusterwideNetworkPolicy:
+		return &clusterScopedBackend{Client: c, backendType: backendType, gvk: ciliumClusterwideNetworkPolicyGVK, build: buildCiliumClusterwideNetworkPolicySpec}, nil
+	case TypeCalicoGlobalNetworkPolicy:
+		return &clusterScopedBackend{Client: c, backendType: backendType, gvk: calicoGlobalNetworkPolicyGVK, build: buildCalicoGlobalNetworkPolicySpec}, nil
+	}
+	return nil, fmt.Errorf("unsupported network policy backend: %s", backendType)
+}
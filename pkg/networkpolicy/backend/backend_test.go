@@ -0,0 +1,178 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"testing"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestSlice() *kubeslicev1beta1.Slice {
+	return &kubeslicev1beta1.Slice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "green",
+			Namespace: "kubeslice-system",
+		},
+		Status: kubeslicev1beta1.SliceStatus{
+			SliceConfig: &kubeslicev1beta1.SliceConfig{
+				NamespaceIsolationProfile: &kubeslicev1beta1.NamespaceIsolationProfile{
+					IsolationEnabled:      true,
+					ApplicationNamespaces: []string{"iperf"},
+					AllowedNamespaces:     []string{"monitoring"},
+				},
+			},
+			ApplicationNamespaces: []string{"iperf"},
+		},
+	}
+}
+
+func newTestClient() client.Client {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range []struct {
+		gvk   schema.GroupVersionKind
+		scope meta.RESTScope
+	}{
+		{adminNetworkPolicyGVK, meta.RESTScopeRoot},
+		{ciliumClusterwideNetworkPolicyGVK, meta.RESTScopeRoot},
+		{calicoGlobalNetworkPolicyGVK, meta.RESTScopeRoot},
+		{networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy"), meta.RESTScopeNamespace},
+	} {
+		mapper.Add(gvk.gvk, gvk.scope)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).Build()
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		backendType string
+		expected    string
+		expectErr   bool
+	}{
+		{"", TypeNetworkPolicy, false},
+		{TypeNetworkPolicy, TypeNetworkPolicy, false},
+		{TypeAdminNetworkPolicy, TypeAdminNetworkPolicy, false},
+		{TypeCiliumClusterwideNetworkPolicy, TypeCiliumClusterwideNetworkPolicy, false},
+		{TypeCalicoGlobalNetworkPolicy, TypeCalicoGlobalNetworkPolicy, false},
+		{"Antrea", "", true},
+	}
+	for _, tc := range cases {
+		b, err := New(tc.backendType, newTestClient())
+		if tc.expectErr {
+			if err == nil {
+				t.Fatalf("expected error for backend %q", tc.backendType)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error for backend %q: %v", tc.backendType, err)
+		}
+		if b.Type() != tc.expected {
+			t.Fatalf("expected backend %s got %s", tc.expected, b.Type())
+		}
+	}
+}
+
+func TestNetworkPolicyBackend(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient()
+	slice := newTestSlice()
+	b := NewNetworkPolicyBackend(c)
+
+	if err := b.Install(ctx, slice, []string{"iperf"}); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	netpol := &networkingv1.NetworkPolicy{}
+	if err := c.Get(ctx, types.NamespacedName{Name: "green-iperf", Namespace: "iperf"}, netpol); err != nil {
+		t.Fatalf("expected netpol to be created: %v", err)
+	}
+	// installing again must update the existing policy
+	if err := b.Install(ctx, slice, []string{"iperf"}); err != nil {
+		t.Fatalf("reinstall failed: %v", err)
+	}
+	if err := b.Uninstall(ctx, slice); err != nil {
+		t.Fatalf("uninstall failed: %v", err)
+	}
+	err := c.Get(ctx, types.NamespacedName{Name: "green-iperf", Namespace: "iperf"}, netpol)
+	if !errors.IsNotFound(err) {
+		t.Fatalf("expected netpol to be deleted, got %v", err)
+	}
+}
+
+func TestClusterScopedBackends(t *testing.T) {
+	cases := []struct {
+		backendType string
+		gvk         schema.GroupVersionKind
+		specKey     string
+	}{
+		{TypeAdminNetworkPolicy, adminNetworkPolicyGVK, "subject"},
+		{TypeCiliumClusterwideNetworkPolicy, ciliumClusterwideNetworkPolicyGVK, "endpointSelector"},
+		{TypeCalicoGlobalNetworkPolicy, calicoGlobalNetworkPolicyGVK, "namespaceSelector"},
+	}
+	for _, tc := range cases {
+		ctx := context.Background()
+		c := newTestClient()
+		slice := newTestSlice()
+		b, err := New(tc.backendType, c)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.backendType, err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := b.Install(ctx, slice, []string{"iperf"}); err != nil {
+				t.Fatalf("%s: install failed: %v", tc.backendType, err)
+			}
+		}
+		policy := &unstructured.Unstructured{}
+		policy.SetGroupVersionKind(tc.gvk)
+		if err := c.Get(ctx, types.NamespacedName{Name: PolicyName(slice.Name)}, policy); err != nil {
+			t.Fatalf("%s: expected policy to be created: %v", tc.backendType, err)
+		}
+		if _, found, _ := unstructured.NestedFieldNoCopy(policy.Object, "spec", tc.specKey); !found {
+			t.Fatalf("%s: expected spec.%s to be set", tc.backendType, tc.specKey)
+		}
+		if err := b.UnbindNamespace(ctx, slice, "iperf"); err != nil {
+			t.Fatalf("%s: unbind failed: %v", tc.backendType, err)
+		}
+		if err := b.Uninstall(ctx, slice); err != nil {
+			t.Fatalf("%s: uninstall failed: %v", tc.backendType, err)
+		}
+		err = c.Get(ctx, types.NamespacedName{Name: PolicyName(slice.Name)}, policy)
+		if !errors.IsNotFound(err) {
+			t.Fatalf("%s: expected policy to be deleted, got %v", tc.backendType, err)
+		}
+		// uninstalling an absent policy is not an error
+		if err := b.Uninstall(ctx, slice); err != nil {
+			t.Fatalf("%s: second uninstall failed: %v", tc.backendType, err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The CNI specific policy types are handled as unstructured objects so that the operator
+// does not depend on the API modules of every CNI.
+var (
+	adminNetworkPolicyGVK             = schema.GroupVersionKind{Group: "policy.networking.k8s.io", Version: "v1alpha1", Kind: "AdminNetworkPolicy"}
+	ciliumClusterwideNetworkPolicyGVK = schema.GroupVersionKind{Group: "cilium.io", Version: "v2", Kind: "CiliumClusterwideNetworkPolicy"}
+	calicoGlobalNetworkPolicyGVK      = schema.GroupVersionKind{Group: "projectcalico.org", Version: "v3", Kind: "GlobalNetworkPolicy"}
+)
+
+const (
+	// AdminNetworkPolicy priority used for slice policies. Lower value has higher precedence.
+	adminNetworkPolicyPriority = 50
+	// Calico policy order used for slice policies. Lower value has higher precedence.
+	calicoPolicyOrder = 100
+	// prefix used by cilium to expose namespace labels on endpoints
+	ciliumNamespaceLabelPrefix = "k8s:io.cilium.k8s.namespace.labels."
+)
+
+// clusterScopedBackend installs a single cluster scoped policy per slice. The policy selects
+// application namespaces by the slice label, so namespaces are added and removed from the
+// policy by the labelling done during namespace onboarding and offboarding.
+type clusterScopedBackend struct {
+	client.Client
+	backendType string
+	gvk         schema.GroupVersionKind
+	build       func(slice *kubeslicev1beta1.Slice) map[string]interface{}
+}
+
+func (b *clusterScopedBackend) Type() string {
+	return b.backendType
+}
+
+// PolicyName returns the name of the cluster scoped policy installed for the slice
+func PolicyName(sliceName string) string {
+	return "kubeslice-" + sliceName
+}
+
+func (b *clusterScopedBackend) Install(ctx context.Context, slice *kubeslicev1beta1.Slice, appNamespaces []string) error {
+	log := logger.FromContext(ctx).WithValues("type", "networkPolicy", "backend", b.backendType)
+	policy := &unstructured.Unstructured{}
+	policy.SetGroupVersionKind(b.gvk)
+	err := b.Get(ctx, types.NamespacedName{Name: PolicyName(slice.Name)}, policy)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		policy = &unstructured.Unstructured{}
+		policy.SetGroupVersionKind(b.gvk)
+		policy.SetName(PolicyName(slice.Name))
+		policy.SetLabels(map[string]string{controllers.ApplicationNamespaceSelectorLabelKey: slice.Name})
+		policy.Object["spec"] = b.build(slice)
+		log.Info("Creating slice network policy", "name", policy.GetName(), "namespaces", appNamespaces)
+		return b.Create(ctx, policy)
+	}
+	policy.Object["spec"] = b.build(slice)
+	log.Info("Updated slice network policy", "name", policy.GetName(), "namespaces", appNamespaces)
+	return b.Update(ctx, policy)
+}
+
+// UnbindNamespace is a no-op since the policy stops selecting the namespace once the slice label is removed from it
+func (b *clusterScopedBackend) UnbindNamespace(ctx context.Context, slice *kubeslicev1beta1.Slice, appNs string) error {
+	return nil
+}
+
+func (b *clusterScopedBackend) Uninstall(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
+	policy := &unstructured.Unstructured{}
+	policy.SetGroupVersionKind(b.gvk)
+	policy.SetName(PolicyName(slice.Name))
+	err := b.Delete(ctx, policy)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func sliceLabels(slice *kubeslicev1beta1.Slice) map[string]interface{} {
+	return map[string]interface{}{controllers.ApplicationNamespaceSelectorLabelKey: slice.Name}
+}
+
+func allowedNsLabels(allowedNs string) map[string]interface{} {
+	return map[string]interface{}{controllers.AllowedNamespaceSelectorLabelKey: allowedNs}
+}
+
+// buildAdminNetworkPolicySpec allows traffic between the application namespaces of the slice and
+// the allowed namespaces, and denies traffic to and from every other namespace. A
+// BaselineAdminNetworkPolicy is a cluster singleton owned by the cluster admin, so it is not used here.
+func buildAdminNetworkPolicySpec(slice *kubeslicev1beta1.Slice) map[string]interface{} {
+	peers := []interface{}{
+		map[string]interface{}{"namespaces": map[string]interface{}{"matchLabels": sliceLabels(slice)}},
+	}
+	for _, allowedNs := range controllers.GetSliceAllowedNamespaces(slice) {
+		peers = append(peers, map[string]interface{}{"namespaces": map[string]interface{}{"matchLabels": allowedNsLabels(allowedNs)}})
+	}
+	denyAll := []interface{}{
+		map[string]interface{}{"namespaces": map[string]interface{}{}},
+	}
+	return map[string]interface{}{
+		"priority": int64(adminNetworkPolicyPriority),
+		"subject": map[string]interface{}{
+			"namespaces": map[string]interface{}{"matchLabels": sliceLabels(slice)},
+		},
+		"ingress": []interface{}{
+			map[string]interface{}{"name": "allow-slice", "action": "Allow", "from": peers},
+			map[string]interface{}{"name": "deny-others", "action": "Deny", "from": denyAll},
+		},
+		"egress": []interface{}{
+			map[string]interface{}{"name": "allow-slice", "action": "Allow", "to": peers},
+			map[string]interface{}{"name": "deny-others", "action": "Deny", "to": denyAll},
+		},
+	}
+}
+
+// buildCiliumClusterwideNetworkPolicySpec selects the endpoints of the application namespaces. Cilium
+// denies any traffic not matched by a rule once an endpoint is selected by an ingress or egress policy.
+func buildCiliumClusterwideNetworkPolicySpec(slice *kubeslicev1beta1.Slice) map[string]interface{} {
+	sliceSelector := map[string]interface{}{
+		"matchLabels": map[string]interface{}{
+			ciliumNamespaceLabelPrefix + controllers.ApplicationNamespaceSelectorLabelKey: slice.Name,
+		},
+	}
+	endpoints := []interface{}{sliceSelector}
+	for _, allowedNs := range controllers.GetSliceAllowedNamespaces(slice) {
+		endpoints = append(endpoints, map[string]interface{}{
+			"matchLabels": map[string]interface{}{
+				ciliumNamespaceLabelPrefix + controllers.AllowedNamespaceSelectorLabelKey: allowedNs,
+			},
+		})
+	}
+	return map[string]interface{}{
+		"endpointSelector": sliceSelector,
+		"ingress": []interface{}{
+			map[string]interface{}{"fromEndpoints": endpoints},
+		},
+		"egress": []interface{}{
+			map[string]interface{}{"toEndpoints": endpoints},
+		},
+	}
+}
+
+// buildCalicoGlobalNetworkPolicySpec selects the application namespaces of the slice. Calico denies
+// any traffic not allowed by a rule once an endpoint is selected by a policy.
+func buildCalicoGlobalNetworkPolicySpec(slice *kubeslicev1beta1.Slice) map[string]interface{} {
+	sliceSelector := fmt.Sprintf("%s == '%s'", controllers.ApplicationNamespaceSelectorLabelKey, slice.Name)
+	allowed := []string{}
+	for _, allowedNs := range controllers.GetSliceAllowedNamespaces(slice) {
+		allowed = append(allowed, "'"+allowedNs+"'")
+	}
+	allowedSelector := fmt.Sprintf("%s in {%s}", controllers.AllowedNamespaceSelectorLabelKey, strings.Join(allowed, ", "))
+	return map[string]interface{}{
+		"order":             int64(calicoPolicyOrder),
+		"namespaceSelector": sliceSelector,
+		"types":             []interface{}{"Ingress", "Egress"},
+		"ingress": []interface{}{
+			map[string]interface{}{"action": "Allow", "source": map[string]interface{}{"namespaceSelector": sliceSelector}},
+			map[string]interface{}{"action": "Allow", "source": map[string]interface{}{"namespaceSelector": allowedSelector}},
+		},
+		"egress": []interface{}{
+			map[string]interface{}{"action": "Allow", "destination": map[string]interface{}{"namespaceSelector": sliceSelector}},
+			map[string]interface{}{"action": "Allow", "destination": map[string]interface{}{"namespaceSelector": allowedSelector}},
+		},
+	}
+}
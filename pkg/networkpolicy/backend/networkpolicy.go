@@ -0,0 +1,99 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// networkPolicyBackend installs a namespaced NetworkPolicy in every application namespace of the slice
+type networkPolicyBackend struct {
+	client.Client
+}
+
+// NewNetworkPolicyBackend returns the default backend which uses namespaced NetworkPolicies
+func NewNetworkPolicyBackend(c client.Client) Backend {
+	return &networkPolicyBackend{Client: c}
+}
+
+func (b *networkPolicyBackend) Type() string {
+	return TypeNetworkPolicy
+}
+
+func (b *networkPolicyBackend) Install(ctx context.Context, slice *kubeslicev1beta1.Slice, appNamespaces []string) error {
+	log := logger.FromContext(ctx).WithValues("type", "networkPolicy")
+	for _, appNs := range appNamespaces {
+		netPolicy := controllers.ContructNetworkPolicyObject(ctx, slice, appNs)
+		existing := &networkingv1.NetworkPolicy{}
+		err := b.Get(ctx, types.NamespacedName{Name: netPolicy.Name, Namespace: netPolicy.Namespace}, existing)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			if err := b.Create(ctx, netPolicy); err != nil {
+				return err
+			}
+			continue
+		}
+		existing.Spec = netPolicy.Spec
+		// policies installed by older versions are not labelled with their slice
+		if existing.Labels == nil {
+			existing.Labels = map[string]string{}
+		}
+		existing.Labels[controllers.ApplicationNamespaceSelectorLabelKey] = slice.Name
+		if err := b.Update(ctx, existing); err != nil {
+			return err
+		}
+		log.Info("Updated network policy", "namespace", appNs)
+	}
+	return nil
+}
+
+func (b *networkPolicyBackend) UnbindNamespace(ctx context.Context, slice *kubeslicev1beta1.Slice, appNs string) error {
+	netPolicy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      slice.Name + "-" + appNs,
+			Namespace: appNs,
+		},
+	}
+	err := b.Delete(ctx, netPolicy)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (b *networkPolicyBackend) Uninstall(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
+	log := logger.FromContext(ctx).WithValues("type", "networkPolicy")
+	for _, ns := range slice.Status.ApplicationNamespaces {
+		if err := b.UnbindNamespace(ctx, slice, ns); err != nil {
+			log.Error(err, "NS unbind: Failed to remove slice netpol", "namespace", ns)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package operatorconfig holds the configuration applied from the WorkerOperatorConfig resource.
+// Every getter falls back to the environment of the operator when the resource does not set a value,
+// so the operator behaves as before when no WorkerOperatorConfig exists.
+package operatorconfig
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConfigName is the name of the WorkerOperatorConfig applied by the operator
+const ConfigName = "default"
+
+// Environment variables of the images that can be overridden by the WorkerOperatorConfig
+const (
+	IstioProxyImageEnv         = "AVESHA_ISTIO_PROXY_IMAGE"
+	SliceRouterImageEnv        = "AVESHA_VL3_ROUTER_IMAGE"
+	SliceRouterSidecarImageEnv = "AVESHA_VL3_SIDECAR_IMAGE"
+	GatewaySidecarImageEnv     = "AVESHA_GW_SIDECAR_IMAGE"
+	OpenVPNServerImageEnv      = "AVESHA_OPENVPN_SERVER_IMAGE"
+	OpenVPNClientImageEnv      = "AVESHA_OPENVPN_CLIENT_IMAGE"
+	SliceGatewayEdgeImageEnv   = "AVESHA_SLICE_GW_EDGE_IMAGE"
+	ProbeImageEnv              = "AVESHA_PROBE_IMAGE"
+	CaptureImageEnv            = "AVESHA_CAPTURE_IMAGE"
+)
+
+var imageOverrides = map[string]func(*kubeslicev1beta1.WorkerOperatorImages) string{
+	IstioProxyImageEnv:         func(i *kubeslicev1beta1.WorkerOperatorImages) string { return i.IstioProxy },
+	SliceRouterImageEnv:        func(i *kubeslicev1beta1.WorkerOperatorImages) string { return i.SliceRouter },
+	SliceRouterSidecarImageEnv: func(i *kubeslicev1beta1.WorkerOperatorImages) string { return i.SliceRouterSidecar },
+	GatewaySidecarImageEnv:     func(i *kubeslicev1beta1.WorkerOperatorImages) string { return i.GatewaySidecar },
+	OpenVPNServerImageEnv:      func(i *kubeslicev1beta1.WorkerOperatorImages) string { return i.OpenVPNServer },
+	OpenVPNClientImageEnv:      func(i *kubeslicev1beta1.WorkerOperatorImages) string { return i.OpenVPNClient },
+	SliceGatewayEdgeImageEnv:   func(i *kubeslicev1beta1.WorkerOperatorImages) string { return i.SliceGatewayEdge },
+	ProbeImageEnv:              func(i *kubeslicev1beta1.WorkerOperatorImages) string { return i.Probe },
+	CaptureImageEnv:            func(i *kubeslicev1beta1.WorkerOperatorImages) string { return i.Capture },
+}
+
+var (
+	mu      sync.RWMutex
+	current *kubeslicev1beta1.WorkerOperatorConfigSpec
+)
+
+// Set replaces the applied configuration. A nil spec resets every setting to the environment.
+func Set(spec *kubeslicev1beta1.WorkerOperatorConfigSpec) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = spec.DeepCopy()
+}
+
+func get() *kubeslicev1beta1.WorkerOperatorConfigSpec {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// ReconcileInterval returns the requeue interval of the slice and serviceexport reconcilers
+func ReconcileInterval(def time.Duration) time.Duration {
+	if spec := get(); spec != nil && spec.ReconcileInterval != nil && spec.ReconcileInterval.Duration > 0 {
+		return spec.ReconcileInterval.Duration
+	}
+	return def
+}
+
+// SliceGatewayReconcileInterval returns the requeue interval of the slicegateway reconciler
+func SliceGatewayReconcileInterval(def time.Duration) time.Duration {
+	if spec := get(); spec != nil && spec.SliceGatewayReconcileInterval != nil && spec.SliceGatewayReconcileInterval.Duration > 0 {
+		return spec.SliceGatewayReconcileInterval.Duration
+	}
+	return def
+}
+
+// ExcludedNamespaces returns the namespaces ignored by the namespace reconciler
+func ExcludedNamespaces() []string {
+	if spec := get(); spec != nil && len(spec.ExcludedNamespaces) > 0 {
+		return append([]string{}, spec.ExcludedNamespaces...)
+	}
+	return strings.Split(utils.GetEnvOrDefault("EXCLUDED_NS", utils.DefaultExcludedNS), ",")
+}
+
+// Image returns the image overridden for the given environment variable, or the value of the variable
+func Image(env string) string {
+	if spec := get(); spec != nil {
+		if override, ok := imageOverrides[env]; ok {
+			if img := override(&spec.Images); img != "" {
+				return img
+			}
+		}
+	}
+	return os.Getenv(env)
+}
+
+// FeatureFlag returns the value of a feature flag and whether it is set in the configuration
+func FeatureFlag(feature string) (bool, bool) {
+	spec := get()
+	if spec == nil {
+		return false, false
+	}
+	for name, enabled := range spec.FeatureFlags {
+		if strings.EqualFold(name, feature) {
+			return enabled, true
+		}
+	}
+	return false, false
+}
+
+// LogLevel returns the log level set in the configuration, empty if it is not set
+func LogLevel() string {
+	if spec := get(); spec != nil {
+		return spec.LogLevel
+	}
+	return ""
+}
+
+// Effective returns the configuration in use, resolving every unset field from the environment.
+// The reconcile intervals are resolved against the given defaults.
+func Effective(reconcileInterval, sliceGatewayReconcileInterval time.Duration, logLevel string) kubeslicev1beta1.WorkerOperatorConfigSpec {
+	effective := kubeslicev1beta1.WorkerOperatorConfigSpec{
+		ReconcileInterval:             &metav1.Duration{Duration: ReconcileInterval(reconcileInterval)},
+		SliceGatewayReconcileInterval: &metav1.Duration{Duration: SliceGatewayReconcileInterval(sliceGatewayReconcileInterval)},
+		ExcludedNamespaces:            ExcludedNamespaces(),
+		LogLevel:                      logLevel,
+		Images: kubeslicev1beta1.WorkerOperatorImages{
+			IstioProxy:         Image(IstioProxyImageEnv),
+			SliceRouter:        Image(SliceRouterImageEnv),
+			SliceRouterSidecar: Image(SliceRouterSidecarImageEnv),
+			GatewaySidecar:     Image(GatewaySidecarImageEnv),
+			OpenVPNServer:      Image(OpenVPNServerImageEnv),
+			OpenVPNClient:      Image(OpenVPNClientImageEnv),
+			SliceGatewayEdge:   Image(SliceGatewayEdgeImageEnv),
+			Probe:              Image(ProbeImageEnv),
+			Capture:            Image(CaptureImageEnv),
+		},
+	}
+	flags := map[string]bool{}
+	for _, env := range os.Environ() {
+		kv := strings.SplitN(env, "=", 2)
+		if name, ok := strings.CutPrefix(kv[0], "FEATURE_"); ok && len(kv) == 2 {
+			flags[strings.ToLower(name)] = strings.ToLower(kv[1]) == "true"
+		}
+	}
+	if spec := get(); spec != nil {
+		for name, enabled := range spec.FeatureFlags {
+			flags[strings.ToLower(name)] = enabled
+		}
+	}
+	if len(flags) > 0 {
+		effective.FeatureFlags = flags
+	}
+	return effective
+}
@@ -0,0 +1,94 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package operatorconfig_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/pkg/operatorconfig"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOperatorConfigFallsBackToEnv(t *testing.T) {
+	operatorconfig.Set(nil)
+	os.Setenv("EXCLUDED_NS", "a,b")
+	os.Setenv(operatorconfig.IstioProxyImageEnv, "istio/proxyv2:env")
+	defer os.Unsetenv("EXCLUDED_NS")
+	defer os.Unsetenv(operatorconfig.IstioProxyImageEnv)
+
+	if got := operatorconfig.ReconcileInterval(10 * time.Second); got != 10*time.Second {
+		t.Fatalf("expected default reconcile interval, got %s", got)
+	}
+	if got := operatorconfig.ExcludedNamespaces(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("expected excluded namespaces from env, got %v", got)
+	}
+	if got := operatorconfig.Image(operatorconfig.IstioProxyImageEnv); got != "istio/proxyv2:env" {
+		t.Fatalf("expected image from env, got %s", got)
+	}
+	if _, ok := operatorconfig.FeatureFlag("abc"); ok {
+		t.Fatalf("expected feature flag to be unset")
+	}
+}
+
+func TestOperatorConfigOverridesEnv(t *testing.T) {
+	os.Setenv(operatorconfig.IstioProxyImageEnv, "istio/proxyv2:env")
+	os.Setenv("FEATURE_XYZ", "true")
+	defer os.Unsetenv(operatorconfig.IstioProxyImageEnv)
+	defer os.Unsetenv("FEATURE_XYZ")
+
+	operatorconfig.Set(&kubeslicev1beta1.WorkerOperatorConfigSpec{
+		ReconcileInterval:  &metav1.Duration{Duration: 30 * time.Second},
+		ExcludedNamespaces: []string{"c"},
+		Images:             kubeslicev1beta1.WorkerOperatorImages{IstioProxy: "istio/proxyv2:cr"},
+		FeatureFlags:       map[string]bool{"ABC": true},
+		LogLevel:           "DEBUG",
+	})
+	defer operatorconfig.Set(nil)
+
+	if got := operatorconfig.ReconcileInterval(10 * time.Second); got != 30*time.Second {
+		t.Fatalf("expected reconcile interval from config, got %s", got)
+	}
+	if got := operatorconfig.SliceGatewayReconcileInterval(2 * time.Minute); got != 2*time.Minute {
+		t.Fatalf("expected default slicegateway reconcile interval, got %s", got)
+	}
+	if got := operatorconfig.ExcludedNamespaces(); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Fatalf("expected excluded namespaces from config, got %v", got)
+	}
+	if got := operatorconfig.Image(operatorconfig.IstioProxyImageEnv); got != "istio/proxyv2:cr" {
+		t.Fatalf("expected image from config, got %s", got)
+	}
+	if enabled, ok := operatorconfig.FeatureFlag("abc"); !ok || !enabled {
+		t.Fatalf("expected feature flag abc to be enabled by config")
+	}
+
+	effective := operatorconfig.Effective(10*time.Second, 2*time.Minute, "DEBUG")
+	if effective.ReconcileInterval.Duration != 30*time.Second || effective.SliceGatewayReconcileInterval.Duration != 2*time.Minute {
+		t.Fatalf("unexpected effective intervals: %v %v", effective.ReconcileInterval, effective.SliceGatewayReconcileInterval)
+	}
+	if !effective.FeatureFlags["abc"] || !effective.FeatureFlags["xyz"] {
+		t.Fatalf("expected effective feature flags from config and env, got %v", effective.FeatureFlags)
+	}
+	if effective.Images.IstioProxy != "istio/proxyv2:cr" || effective.LogLevel != "DEBUG" {
+		t.Fatalf("unexpected effective config: %+v", effective)
+	}
+}
@@ -0,0 +1,209 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package probe
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kubeslice/worker-operator/pkg/logger"
+)
+
+const (
+	// AgentPort is the port the probe pods listen on
+	AgentPort = 8090
+	// agentPath is the path of the probe requests
+	agentPath = "/probe"
+	// maxTargets bounds the targets of a request
+	maxTargets = 256
+	// TokenEnv is the environment variable of the probe pod with the token of the operator requests
+	TokenEnv = "PROBE_AGENT_TOKEN"
+	// TokenKey is the key of the token in the probe secret of a slice
+	TokenKey = "token"
+)
+
+var log = logger.NewWrappedLogger().WithName("probe")
+
+// Agent serves the probe requests of the operator in the probe pod. The requests must carry the token
+// of the slice, and the agent only probes targets in the subnet of the slice.
+type Agent struct {
+	// Timeout of the probe of a target
+	Timeout time.Duration
+	// Token authenticates the requests of the operator
+	Token string
+	// Subnet is the subnet of the slice
+	Subnet *net.IPNet
+
+	probe func(ctx context.Context, t Target, timeout time.Duration) Result
+}
+
+// ServeHTTP probes the targets of the request concurrently and returns their results in order
+func (a *Agent) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != agentPath {
+		http.NotFound(w, req)
+		return
+	}
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.authorized(req) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var targets []Target
+	if err := json.NewDecoder(io.LimitReader(req.Body, 1<<20)).Decode(&targets); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(targets) > maxTargets {
+		http.Error(w, fmt.Sprintf("too many targets, at most %d are allowed", maxTargets), http.StatusBadRequest)
+		return
+	}
+	for _, t := range targets {
+		if err := a.allowed(t); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	probe := a.probe
+	if probe == nil {
+		probe = Probe
+	}
+	results := make([]Result, len(targets))
+	var wg sync.WaitGroup
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = probe(req.Context(), targets[i], a.Timeout)
+		}(i)
+	}
+	wg.Wait()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		log.Error(err, "failed to write probe results")
+	}
+}
+
+func (a *Agent) authorized(req *http.Request) bool {
+	if a.Token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), []byte("Bearer "+a.Token)) == 1
+}
+
+// allowed returns an error if the address of the target is not in the subnet of the slice
+func (a *Agent) allowed(t Target) error {
+	host := t.Address
+	if t.Protocol == ProtocolTCP {
+		var err error
+		if host, _, err = net.SplitHostPort(t.Address); err != nil {
+			return fmt.Errorf("invalid address of target %s: %v", t.Name, err)
+		}
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || a.Subnet == nil || !a.Subnet.Contains(ip) {
+		return fmt.Errorf("target %s is not in the subnet of the slice", t.Address)
+	}
+	return nil
+}
+
+// Send sends the targets to the agent listening on the address and returns their results
+func Send(ctx context.Context, address, token string, targets []Target) ([]Result, error) {
+	body, err := json.Marshal(targets)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+address+agentPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("probe agent returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	results := []Result{}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, err
+	}
+	if len(results) != len(targets) {
+		return nil, fmt.Errorf("probe agent returned %d results for %d targets", len(results), len(targets))
+	}
+	return results, nil
+}
+
+// Run runs the probe subcommand of the operator, the agent of the probe pods
+func Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("probe", flag.ContinueOnError)
+	listen := fs.String("listen", fmt.Sprintf(":%d", AgentPort), "Address to serve the probe requests on.")
+	timeout := fs.Duration("timeout", 2*time.Second, "Timeout of the probe of a target.")
+	subnet := fs.String("subnet", "", "Subnet of the slice, only the targets in it are probed.")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	_, ipnet, err := net.ParseCIDR(*subnet)
+	if err != nil {
+		return fmt.Errorf("invalid subnet of the slice %q: %v", *subnet, err)
+	}
+	token := os.Getenv(TokenEnv)
+	if token == "" {
+		return fmt.Errorf("no token of the operator requests in %s", TokenEnv)
+	}
+
+	srv := &http.Server{
+		Addr:              *listen,
+		Handler:           &Agent{Timeout: *timeout, Token: token, Subnet: ipnet},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Error(err, "failed to shut down probe agent")
+		}
+	}()
+	log.Info("starting probe agent", "address", *listen, "timeout", *timeout, "subnet", ipnet)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package probe checks the connectivity of a slice beyond the presence of the nsm interfaces of the
+// pods. A probe pod is connected to the slice in every cluster where the probes are enabled. The
+// operator sends it the remote slice routers and the remote endpoints of the selected serviceimports,
+// the pod probes them over the slice network with icmp and tcp and returns the reachability and the
+// round trip time of each target.
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+const (
+	// ProtocolICMP probes a target with an icmp echo request
+	ProtocolICMP = "icmp"
+	// ProtocolTCP probes a target by opening a tcp connection
+	ProtocolTCP = "tcp"
+)
+
+// Target is a remote address probed over the slice
+type Target struct {
+	// Name of the target, eg: router or the name of a serviceimport endpoint
+	Name string `json:"name"`
+	// RemoteCluster is the cluster of the target
+	RemoteCluster string `json:"remoteCluster"`
+	// Protocol is icmp or tcp
+	Protocol string `json:"protocol"`
+	// Address is the ip of the target, with the port for tcp
+	Address string `json:"address"`
+}
+
+// Result is the outcome of the probe of a target
+type Result struct {
+	Target
+	Reachable bool          `json:"reachable"`
+	RTT       time.Duration `json:"rtt,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Probe probes a target once and returns its result
+func Probe(ctx context.Context, t Target, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var rtt time.Duration
+	var err error
+	switch t.Protocol {
+	case ProtocolICMP:
+		rtt, err = ping(ctx, t.Address)
+	case ProtocolTCP:
+		rtt, err = dial(ctx, t.Address)
+	default:
+		err = fmt.Errorf("unknown protocol %q", t.Protocol)
+	}
+	if err != nil {
+		return Result{Target: t, Error: err.Error()}
+	}
+	return Result{Target: t, Reachable: true, RTT: rtt}
+}
+
+func dial(ctx context.Context, address string) (time.Duration, error) {
+	start := time.Now()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return 0, err
+	}
+	rtt := time.Since(start)
+	conn.Close()
+	return rtt, nil
+}
+
+// ping sends an icmp echo request from an unprivileged datagram socket, the probe pod allows it with
+// the net.ipv4.ping_group_range sysctl. The kernel sets the identifier of the datagram sockets, the
+// replies are matched on the sequence number.
+func ping(ctx context.Context, address string) (time.Duration, error) {
+	ip := net.ParseIP(address)
+	if ip == nil || ip.To4() == nil {
+		return 0, fmt.Errorf("invalid ipv4 address %q", address)
+	}
+	conn, err := icmp.ListenPacket("udp4", "0.0.0.0")
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return 0, err
+		}
+	}
+
+	seq := int(time.Now().UnixNano() & 0xffff)
+	msg := icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Body: &icmp.Echo{ID: os.Getpid() & 0xffff, Seq: seq, Data: []byte("kubeslice-probe")},
+	}
+	b, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	if _, err := conn.WriteTo(b, &net.UDPAddr{IP: ip}); err != nil {
+		return 0, err
+	}
+	reply := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(reply)
+		if err != nil {
+			return 0, err
+		}
+		if addr, ok := peer.(*net.UDPAddr); !ok || !addr.IP.Equal(ip) {
+			continue
+		}
+		m, err := icmp.ParseMessage(ipv4.ICMPTypeEcho.Protocol(), reply[:n])
+		if err != nil {
+			continue
+		}
+		switch m.Type {
+		case ipv4.ICMPTypeEchoReply:
+			if echo, ok := m.Body.(*icmp.Echo); ok && echo.Seq == seq {
+				return time.Since(start), nil
+			}
+		case ipv4.ICMPTypeDestinationUnreachable:
+			return 0, errors.New("destination unreachable")
+		}
+	}
+}
@@ -0,0 +1,230 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newProbeTestObjects() []client.Object {
+	cp := controllers.ControlPlaneNamespace
+	gw := func(name, slice, remoteCluster, remoteSubnet string) *kubeslicev1beta1.SliceGateway {
+		return &kubeslicev1beta1.SliceGateway{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cp, Name: name,
+				Labels: map[string]string{controllers.ApplicationNamespaceSelectorLabelKey: slice}},
+			Spec: kubeslicev1beta1.SliceGatewaySpec{SliceName: slice},
+			Status: kubeslicev1beta1.SliceGatewayStatus{Config: kubeslicev1beta1.SliceGatewayConfig{
+				SliceGatewayRemoteClusterID: remoteCluster,
+				SliceGatewayRemoteSubnet:    remoteSubnet,
+			}},
+		}
+	}
+	return []client.Object{
+		&kubeslicev1beta1.Slice{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cp, Name: "green"},
+			Spec: kubeslicev1beta1.SliceSpec{ConnectivityProbe: &kubeslicev1beta1.ConnectivityProbe{
+				ServiceImports: []string{"iperf/iperf-server", "iperf/missing", "invalid"},
+			}},
+		},
+		&kubeslicev1beta1.Slice{ObjectMeta: metav1.ObjectMeta{Namespace: cp, Name: "red"}},
+		gw("green-w1-w2", "green", "w2", "10.1.2.0/24"),
+		gw("green-w1-w3", "green", "w3", "10.1.3.0/24"),
+		gw("red-w1-w2", "red", "w2", "10.2.2.0/24"),
+		&kubeslicev1beta1.ServiceImport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "iperf", Name: "iperf-server"},
+			Spec:       kubeslicev1beta1.ServiceImportSpec{Slice: "green"},
+			Status: kubeslicev1beta1.ServiceImportStatus{Endpoints: []kubeslicev1beta1.ServiceEndpoint{
+				{Name: "iperf-server-0", IP: "10.1.2.5", Port: 5201, ClusterID: "w2"},
+				{Name: "iperf-server-local", IP: "10.1.1.5", Port: 5201, ClusterID: controllers.ClusterName},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cp, Name: "green-probe-abc", Labels: PodLabels("green")},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "192.168.0.10"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cp, Name: SecretName("green")},
+			Data:       map[string][]byte{TokenKey: []byte("green-token")},
+		},
+	}
+}
+
+func TestTargets(t *testing.T) {
+	objs := newProbeTestObjects()
+	slice := objs[0].(*kubeslicev1beta1.Slice)
+	gateways := []kubeslicev1beta1.SliceGateway{
+		*objs[2].(*kubeslicev1beta1.SliceGateway),
+		*objs[3].(*kubeslicev1beta1.SliceGateway),
+		*objs[4].(*kubeslicev1beta1.SliceGateway),
+	}
+	// a second gateway to the same cluster adds no router target
+	dup := gateways[0].DeepCopy()
+	dup.Name = "green-w1-w2-1"
+	gateways = append(gateways, *dup)
+	imports := []kubeslicev1beta1.ServiceImport{*objs[5].(*kubeslicev1beta1.ServiceImport)}
+
+	got := Targets(slice, gateways, imports, controllers.ClusterName)
+	expected := []Target{
+		{Name: "iperf/iperf-server-0", RemoteCluster: "w2", Protocol: ProtocolTCP, Address: "10.1.2.5:5201"},
+		{Name: RouterTargetName, RemoteCluster: "w2", Protocol: ProtocolICMP, Address: "10.1.2.1"},
+		{Name: RouterTargetName, RemoteCluster: "w3", Protocol: ProtocolICMP, Address: "10.1.3.1"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected targets %v, got %v", expected, got)
+	}
+}
+
+func TestAgent(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := closed.Addr().String()
+	closed.Close()
+
+	_, subnet, _ := net.ParseCIDR("127.0.0.0/8")
+	srv := httptest.NewServer(&Agent{Timeout: time.Second, Token: "secret", Subnet: subnet})
+	defer srv.Close()
+	address := strings.TrimPrefix(srv.URL, "http://")
+	targets := []Target{
+		{Name: "open", RemoteCluster: "w2", Protocol: ProtocolTCP, Address: l.Addr().String()},
+		{Name: "closed", RemoteCluster: "w2", Protocol: ProtocolTCP, Address: closedAddr},
+		{Name: "unknown", RemoteCluster: "w2", Protocol: "udp", Address: "127.0.0.1"},
+	}
+	if _, err := Send(context.Background(), address, "wrong", targets); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected the request with a wrong token to be rejected, got %v", err)
+	}
+	outside := []Target{{Name: "metadata", RemoteCluster: "w2", Protocol: ProtocolTCP, Address: "169.254.169.254:80"}}
+	if _, err := Send(context.Background(), address, "secret", outside); err == nil || !strings.Contains(err.Error(), "not in the subnet") {
+		t.Errorf("expected the target outside the slice subnet to be rejected, got %v", err)
+	}
+	results, err := Send(context.Background(), address, "secret", targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(targets) {
+		t.Fatalf("expected %d results, got %d", len(targets), len(results))
+	}
+	if !results[0].Reachable || results[0].Error != "" {
+		t.Errorf("expected open port to be reachable, got %+v", results[0])
+	}
+	for _, r := range results[1:] {
+		if r.Reachable || r.Error == "" {
+			t.Errorf("expected %s to be unreachable with an error, got %+v", r.Name, r)
+		}
+	}
+	for i := range targets {
+		if results[i].Target != targets[i] {
+			t.Errorf("expected result %d for target %v, got %v", i, targets[i], results[i].Target)
+		}
+	}
+}
+
+func TestProbeSlices(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(kubeslicev1beta1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newProbeTestObjects()...).
+		WithStatusSubresource(&kubeslicev1beta1.Slice{}).Build()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := NewProber(c, nil)
+	p.now = func() time.Time { return now }
+	calls := 0
+	p.send = func(ctx context.Context, address, token string, targets []Target) ([]Result, error) {
+		calls++
+		if address != "192.168.0.10:8090" || token != "green-token" {
+			t.Errorf("expected probes to be sent to the probe pod with its token, got %s %s", address, token)
+		}
+		results := []Result{}
+		for _, target := range targets {
+			if target.RemoteCluster == "w3" {
+				results = append(results, Result{Target: target, Error: "i/o timeout"})
+				continue
+			}
+			results = append(results, Result{Target: target, Reachable: true, RTT: 1500 * time.Microsecond})
+		}
+		return results, nil
+	}
+
+	if err := p.ProbeSlices(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected only the slice with probes enabled to be probed, got %d calls", calls)
+	}
+	slice := &kubeslicev1beta1.Slice{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: controllers.ControlPlaneNamespace, Name: "green"}, slice); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]kubeslicev1beta1.ProbeResult{}
+	for _, r := range slice.Status.ProbeResults {
+		got[r.RemoteCluster+"/"+r.Name] = r
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 probe results, got %v", slice.Status.ProbeResults)
+	}
+	if r := got["w2/router"]; !r.Reachable || r.RTTMicroseconds != 1500 || r.Protocol != ProtocolICMP || !r.LastProbed.Time.Equal(now) {
+		t.Errorf("unexpected router result %+v", r)
+	}
+	if r := got["w2/iperf/iperf-server-0"]; !r.Reachable || r.Address != "10.1.2.5:5201" {
+		t.Errorf("unexpected endpoint result %+v", r)
+	}
+	if r := got["w3/router"]; r.Reachable || r.Error != "i/o timeout" {
+		t.Errorf("unexpected unreachable router result %+v", r)
+	}
+
+	// the interval has not elapsed
+	now = now.Add(10 * time.Second)
+	if err := p.ProbeSlices(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the slice not to be probed before its interval, got %d calls", calls)
+	}
+	now = now.Add(defaultProbeInterval)
+	if err := p.ProbeSlices(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the slice to be probed after its interval, got %d calls", calls)
+	}
+}
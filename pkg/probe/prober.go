@@ -0,0 +1,310 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubeslice/kubeslice-monitoring/pkg/metrics"
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	"github.com/kubeslice/worker-operator/pkg/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// ProbeTimeout is the timeout of a round of probes of a slice, eg: 10s
+	ProbeTimeout = utils.GetEnvOrDefault("CONNECTIVITY_PROBE_TIMEOUT", "10s")
+)
+
+const (
+	defaultProbeTimeout  = 10 * time.Second
+	defaultProbeInterval = 30 * time.Second
+	// tick is the interval at which the prober looks for slices due for a round of probes
+	tick = 5 * time.Second
+)
+
+// Prober periodically sends the targets of the slices with probes enabled to their probe pods and
+// records the results in the slice status and in metrics. It is run by the elected replica only.
+type Prober struct {
+	Client  client.Client
+	Timeout time.Duration
+
+	mu       sync.Mutex
+	lastRun  map[string]time.Time
+	now      func() time.Time
+	send     func(ctx context.Context, address, token string, targets []Target) ([]Result, error)
+	gaugeUp  *prometheus.GaugeVec
+	gaugeRTT *prometheus.GaugeVec
+}
+
+// NewProber returns a prober configured from the environment
+func NewProber(c client.Client, mf metrics.MetricsFactory) *Prober {
+	timeout, err := time.ParseDuration(ProbeTimeout)
+	if err != nil || timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+	p := &Prober{
+		Client:  c,
+		Timeout: timeout,
+	}
+	if mf != nil {
+		labels := []string{"slice", "slice_remote_cluster", "probe_target"}
+		p.gaugeUp = mf.NewGauge("slice_probe_reachable", "Whether the last connectivity probe of a remote target succeeded", labels)
+		p.gaugeRTT = mf.NewGauge("slice_probe_rtt_seconds", "Round trip time of the last connectivity probe of a remote target", labels)
+	}
+	return p
+}
+
+// Start probes the slices that are due every tick until the context is done
+func (p *Prober) Start(ctx context.Context) error {
+	log.Info("starting connectivity prober", "timeout", p.Timeout)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := p.ProbeSlices(ctx); err != nil {
+			log.Error(err, "connectivity probes failed")
+		}
+	}, tick)
+	return nil
+}
+
+// NeedLeaderElection runs the prober on the elected replica only
+func (p *Prober) NeedLeaderElection() bool {
+	return true
+}
+
+// ProbeSlices runs a round of probes for every slice with probes enabled whose interval has elapsed
+func (p *Prober) ProbeSlices(ctx context.Context) error {
+	slices := &kubeslicev1beta1.SliceList{}
+	if err := p.Client.List(ctx, slices, client.InNamespace(controllers.ControlPlaneNamespace)); err != nil {
+		return fmt.Errorf("failed to list slices: %w", err)
+	}
+	now := p.clock()
+	enabled := map[string]bool{}
+	var errs []error
+	for i := range slices.Items {
+		slice := &slices.Items[i]
+		if slice.Spec.ConnectivityProbe == nil || !slice.DeletionTimestamp.IsZero() {
+			continue
+		}
+		enabled[slice.Name] = true
+		if !p.due(slice, now) {
+			continue
+		}
+		if err := p.ProbeSlice(ctx, slice); err != nil {
+			errs = append(errs, fmt.Errorf("slice %s: %w", slice.Name, err))
+		}
+	}
+	p.forget(enabled)
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to probe %d slices, first error: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+// ProbeSlice runs a round of probes for a slice and records the results
+func (p *Prober) ProbeSlice(ctx context.Context, slice *kubeslicev1beta1.Slice) error {
+	p.mu.Lock()
+	if p.lastRun == nil {
+		p.lastRun = map[string]time.Time{}
+	}
+	p.lastRun[slice.Name] = p.clock()
+	p.mu.Unlock()
+
+	address, err := p.agentAddress(ctx, slice.Name)
+	if err != nil {
+		return err
+	}
+	token, err := p.agentToken(ctx, slice.Name)
+	if err != nil {
+		return err
+	}
+	targets, err := p.targets(ctx, slice)
+	if err != nil {
+		return err
+	}
+	results := []Result{}
+	if len(targets) > 0 {
+		send := p.send
+		if send == nil {
+			send = Send
+		}
+		ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+		if results, err = send(ctx, address, token, targets); err != nil {
+			return fmt.Errorf("failed to probe targets: %w", err)
+		}
+	}
+	p.exposeMetrics(slice.Name, results)
+	return p.updateStatus(ctx, slice.Name, results)
+}
+
+func (p *Prober) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+func (p *Prober) due(slice *kubeslicev1beta1.Slice, now time.Time) bool {
+	interval := defaultProbeInterval
+	if i := slice.Spec.ConnectivityProbe.Interval; i != nil && i.Duration > 0 {
+		interval = i.Duration
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	last, ok := p.lastRun[slice.Name]
+	return !ok || now.Sub(last) >= interval
+}
+
+// forget drops the state and the metrics of the slices whose probes are no longer enabled
+func (p *Prober) forget(enabled map[string]bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for name := range p.lastRun {
+		if enabled[name] {
+			continue
+		}
+		delete(p.lastRun, name)
+		p.deleteMetrics(name)
+	}
+}
+
+// agentAddress returns the address of the agent of a running probe pod of the slice
+func (p *Prober) agentAddress(ctx context.Context, sliceName string) (string, error) {
+	pods := &corev1.PodList{}
+	err := p.Client.List(ctx, pods, client.InNamespace(controllers.ControlPlaneNamespace), client.MatchingLabels(PodLabels(sliceName)))
+	if err != nil {
+		return "", fmt.Errorf("failed to list probe pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.Status.PodIP != "" && pod.DeletionTimestamp.IsZero() {
+			return net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(AgentPort)), nil
+		}
+	}
+	return "", fmt.Errorf("no running probe pod")
+}
+
+// agentToken returns the token the probe pod of the slice authenticates the requests with
+func (p *Prober) agentToken(ctx context.Context, sliceName string) (string, error) {
+	secret := &corev1.Secret{}
+	err := p.Client.Get(ctx, types.NamespacedName{Namespace: controllers.ControlPlaneNamespace, Name: SecretName(sliceName)}, secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to get probe secret: %w", err)
+	}
+	token := string(secret.Data[TokenKey])
+	if token == "" {
+		return "", fmt.Errorf("no token in probe secret %s", secret.Name)
+	}
+	return token, nil
+}
+
+func (p *Prober) targets(ctx context.Context, slice *kubeslicev1beta1.Slice) ([]Target, error) {
+	gateways := &kubeslicev1beta1.SliceGatewayList{}
+	err := p.Client.List(ctx, gateways, client.InNamespace(controllers.ControlPlaneNamespace),
+		client.MatchingLabels{controllers.ApplicationNamespaceSelectorLabelKey: slice.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list slice gateways: %w", err)
+	}
+	serviceImports := []kubeslicev1beta1.ServiceImport{}
+	for _, ref := range slice.Spec.ConnectivityProbe.ServiceImports {
+		ns, name, err := parseRef(ref)
+		if err != nil {
+			log.Error(err, "ignoring serviceimport of the connectivity probe", "slice", slice.Name)
+			continue
+		}
+		svcim := kubeslicev1beta1.ServiceImport{}
+		if err := p.Client.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, &svcim); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get serviceimport %s: %w", ref, err)
+		}
+		serviceImports = append(serviceImports, svcim)
+	}
+	return Targets(slice, gateways.Items, serviceImports, controllers.ClusterName), nil
+}
+
+func parseRef(ref string) (string, string, error) {
+	ns, name, ok := strings.Cut(ref, "/")
+	if !ok || ns == "" || name == "" {
+		return "", "", fmt.Errorf("invalid serviceimport %q, expected <namespace>/<name>", ref)
+	}
+	return ns, name, nil
+}
+
+func (p *Prober) exposeMetrics(sliceName string, results []Result) {
+	if p.gaugeUp == nil {
+		return
+	}
+	p.deleteMetrics(sliceName)
+	for _, r := range results {
+		up := 0.0
+		if r.Reachable {
+			up = 1
+			p.gaugeRTT.WithLabelValues(sliceName, r.RemoteCluster, r.Name).Set(r.RTT.Seconds())
+		}
+		p.gaugeUp.WithLabelValues(sliceName, r.RemoteCluster, r.Name).Set(up)
+	}
+}
+
+func (p *Prober) deleteMetrics(sliceName string) {
+	if p.gaugeUp == nil {
+		return
+	}
+	p.gaugeUp.DeletePartialMatch(prometheus.Labels{"slice": sliceName})
+	p.gaugeRTT.DeletePartialMatch(prometheus.Labels{"slice": sliceName})
+}
+
+func (p *Prober) updateStatus(ctx context.Context, sliceName string, results []Result) error {
+	probed := metav1.NewTime(p.clock())
+	status := make([]kubeslicev1beta1.ProbeResult, 0, len(results))
+	for _, r := range results {
+		status = append(status, kubeslicev1beta1.ProbeResult{
+			Name:            r.Name,
+			RemoteCluster:   r.RemoteCluster,
+			Address:         r.Address,
+			Protocol:        r.Protocol,
+			Reachable:       r.Reachable,
+			RTTMicroseconds: r.RTT.Microseconds(),
+			Error:           r.Error,
+			LastProbed:      probed,
+		})
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		slice := &kubeslicev1beta1.Slice{}
+		if err := p.Client.Get(ctx, types.NamespacedName{Namespace: controllers.ControlPlaneNamespace, Name: sliceName}, slice); err != nil {
+			return err
+		}
+		slice.Status.ProbeResults = status
+		return p.Client.Status().Update(ctx, slice)
+	})
+}
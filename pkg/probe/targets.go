@@ -0,0 +1,126 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package probe
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"github.com/kubeslice/worker-operator/controllers"
+	webhook "github.com/kubeslice/worker-operator/pkg/webhook/pod"
+)
+
+// RouterTargetName is the name of the targets of the remote slice routers
+const RouterTargetName = "router"
+
+// DeploymentName returns the name of the probe deployment of a slice
+func DeploymentName(sliceName string) string {
+	return sliceName + "-probe"
+}
+
+// SecretName returns the name of the secret with the token of the probe agent of a slice
+func SecretName(sliceName string) string {
+	return sliceName + "-probe"
+}
+
+// PodLabels returns the labels of the probe pods of a slice
+func PodLabels(sliceName string) map[string]string {
+	return map[string]string{
+		controllers.ApplicationNamespaceSelectorLabelKey: sliceName,
+		webhook.PodInjectLabelKey:                        "probe",
+	}
+}
+
+// Targets returns the targets of the probes of a slice: the slice router of every remote cluster
+// connected by a slice gateway, probed over icmp, and the remote endpoints of the serviceimports
+// selected by the slice, probed over tcp. The slice router holds the first address of the subnet of
+// its cluster.
+func Targets(slice *kubeslicev1beta1.Slice, gateways []kubeslicev1beta1.SliceGateway,
+	serviceImports []kubeslicev1beta1.ServiceImport, localCluster string) []Target {
+	targets := []Target{}
+	routers := map[string]bool{}
+	for _, gw := range gateways {
+		cfg := gw.Status.Config
+		if gw.Spec.SliceName != slice.Name || cfg.SliceGatewayRemoteClusterID == "" || routers[cfg.SliceGatewayRemoteClusterID] {
+			continue
+		}
+		ip, err := firstHost(cfg.SliceGatewayRemoteSubnet)
+		if err != nil {
+			continue
+		}
+		routers[cfg.SliceGatewayRemoteClusterID] = true
+		targets = append(targets, Target{
+			Name:          RouterTargetName,
+			RemoteCluster: cfg.SliceGatewayRemoteClusterID,
+			Protocol:      ProtocolICMP,
+			Address:       ip.String(),
+		})
+	}
+	for _, svcim := range serviceImports {
+		if svcim.Spec.Slice != slice.Name {
+			continue
+		}
+		for _, ep := range svcim.Status.Endpoints {
+			if ep.ClusterID == localCluster || ep.IP == "" || ep.Port == 0 {
+				continue
+			}
+			name := ep.Name
+			if name == "" {
+				name = svcim.Name
+			}
+			targets = append(targets, Target{
+				Name:          svcim.Namespace + "/" + name,
+				RemoteCluster: ep.ClusterID,
+				Protocol:      ProtocolTCP,
+				Address:       net.JoinHostPort(ep.IP, strconv.Itoa(int(ep.Port))),
+			})
+		}
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].RemoteCluster != targets[j].RemoteCluster {
+			return targets[i].RemoteCluster < targets[j].RemoteCluster
+		}
+		if targets[i].Name != targets[j].Name {
+			return targets[i].Name < targets[j].Name
+		}
+		return targets[i].Address < targets[j].Address
+	})
+	return targets
+}
+
+func firstHost(cidr string) (net.IP, error) {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	ip := ipnet.IP.To4()
+	if ip == nil {
+		return nil, fmt.Errorf("subnet %s is not ipv4", cidr)
+	}
+	host := make(net.IP, len(ip))
+	copy(host, ip)
+	host[3]++
+	if !ipnet.Contains(host) {
+		return nil, fmt.Errorf("subnet %s has no host address", cidr)
+	}
+	return host, nil
+}
@@ -0,0 +1,141 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package qos validates the QoS profile of a slice and maps it to the traffic control
+// discipline programmed by the netop and gateway sidecars.
+package qos
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TC types of a QoS profile
+const (
+	// TcTypeBandwidthControl shapes the slice traffic between the guaranteed and ceiling bandwidth
+	TcTypeBandwidthControl = "BANDWIDTH_CONTROL"
+)
+
+// Queue types of a QoS profile
+const (
+	QueueTypeHTB = "HTB"
+	QueueTypeTBF = "TBF"
+)
+
+// ErrInvalidProfile is returned for QoS profiles that cannot be applied by any node
+var ErrInvalidProfile = errors.New("invalid QoS profile")
+
+// Values of the TcType and ClassType enums of the netop and gateway sidecar apis. The protos only
+// define BANDWIDTH_CONTROL with HTB and TBF, the profiles with other types are rejected.
+var (
+	tcTypeValues = map[string]int32{
+		TcTypeBandwidthControl: 0,
+	}
+	classTypeValues = map[string]int32{
+		QueueTypeHTB: 0,
+		QueueTypeTBF: 1,
+	}
+	// queue types allowed for every TC type, the first one is the default
+	queueTypes = map[string][]string{
+		TcTypeBandwidthControl: {QueueTypeHTB, QueueTypeTBF},
+	}
+	dscpClasses = []string{
+		"Default", "AF11", "AF12", "AF13", "AF21", "AF22", "AF23",
+		"AF31", "AF32", "AF33", "AF41", "AF42", "AF43", "EF",
+	}
+)
+
+// Discipline is the traffic control discipline of a QoS profile
+type Discipline struct {
+	TcType    string
+	QueueType string
+	// TcTypeValue and ClassTypeValue are the wire values sent to the sidecars
+	TcTypeValue    int32
+	ClassTypeValue int32
+}
+
+func (d Discipline) String() string {
+	return d.TcType + "/" + d.QueueType
+}
+
+// Resolve validates the QoS profile and returns its traffic control discipline.
+// Profiles without a TC type or queue type default to HTB bandwidth control.
+func Resolve(profile kubeslicev1beta1.QosProfileDetails) (Discipline, error) {
+	tcType := strings.ToUpper(profile.TcType)
+	if tcType == "" {
+		tcType = TcTypeBandwidthControl
+	}
+	allowed, ok := queueTypes[tcType]
+	if !ok {
+		return Discipline{}, fmt.Errorf("%w: tc type %q is not supported by the netop and gateway sidecars", ErrInvalidProfile, profile.TcType)
+	}
+	queueType := strings.ToUpper(profile.QueueType)
+	if queueType == "" {
+		queueType = allowed[0]
+	}
+	if !contains(allowed, queueType) {
+		return Discipline{}, fmt.Errorf("%w: queue type %q is not supported by the netop and gateway sidecars", ErrInvalidProfile, profile.QueueType)
+	}
+
+	if profile.DscpClass != "" && !contains(dscpClasses, profile.DscpClass) {
+		return Discipline{}, fmt.Errorf("%w: unknown dscp class %q", ErrInvalidProfile, profile.DscpClass)
+	}
+	if profile.BandwidthCeilingKbps > 0 && profile.BandwidthGuaranteedKbps > profile.BandwidthCeilingKbps {
+		return Discipline{}, fmt.Errorf("%w: guaranteed bandwidth %dKbps exceeds the ceiling %dKbps",
+			ErrInvalidProfile, profile.BandwidthGuaranteedKbps, profile.BandwidthCeilingKbps)
+	}
+
+	d := Discipline{
+		TcType:         tcType,
+		QueueType:      queueType,
+		TcTypeValue:    tcTypeValues[tcType],
+		ClassTypeValue: classTypeValues[queueType],
+	}
+	return d, nil
+}
+
+// NotApplicable reports whether the error means the profile cannot be applied as it is,
+// either because it is invalid or because the sidecar does not support its discipline.
+// Retrying does not help for these errors.
+func NotApplicable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrInvalidProfile) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.Unimplemented, codes.FailedPrecondition:
+		return true
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
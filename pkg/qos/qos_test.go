@@ -0,0 +1,85 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qos
+
+import (
+	"errors"
+	"testing"
+
+	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  kubeslicev1beta1.QosProfileDetails
+		expected Discipline
+		invalid  bool
+	}{
+		{"defaults to htb bandwidth control", kubeslicev1beta1.QosProfileDetails{BandwidthCeilingKbps: 5000, BandwidthGuaranteedKbps: 4000},
+			Discipline{TcType: TcTypeBandwidthControl, QueueType: QueueTypeHTB, TcTypeValue: 0, ClassTypeValue: 0}, false},
+		{"tbf bandwidth control", kubeslicev1beta1.QosProfileDetails{TcType: "BANDWIDTH_CONTROL", QueueType: "tbf", BandwidthCeilingKbps: 5000},
+			Discipline{TcType: TcTypeBandwidthControl, QueueType: QueueTypeTBF, TcTypeValue: 0, ClassTypeValue: 1}, false},
+		{"guaranteed above ceiling", kubeslicev1beta1.QosProfileDetails{BandwidthCeilingKbps: 4000, BandwidthGuaranteedKbps: 5000},
+			Discipline{}, true},
+		{"unknown dscp class", kubeslicev1beta1.QosProfileDetails{BandwidthCeilingKbps: 5000, DscpClass: "AF99"}, Discipline{}, true},
+		{"dscp marking is not supported", kubeslicev1beta1.QosProfileDetails{TcType: "DSCP_MARKING", DscpClass: "AF21"}, Discipline{}, true},
+		{"priority queue is not supported", kubeslicev1beta1.QosProfileDetails{TcType: "PRIORITY_QUEUE", Priority: 2}, Discipline{}, true},
+		{"fq_codel is not supported", kubeslicev1beta1.QosProfileDetails{QueueType: "FQ_CODEL"}, Discipline{}, true},
+		{"unknown tc type", kubeslicev1beta1.QosProfileDetails{TcType: "POLICING"}, Discipline{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.profile)
+			if tt.invalid {
+				if !errors.Is(err, ErrInvalidProfile) {
+					t.Errorf("expected an invalid profile error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error: ", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNotApplicable(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{ErrInvalidProfile, true},
+		{status.Error(codes.InvalidArgument, "unknown tc type"), true},
+		{status.Error(codes.Unimplemented, "not implemented"), true},
+		{status.Error(codes.Unavailable, "connection refused"), false},
+		{errors.New("timeout"), false},
+	}
+	for _, tt := range tests {
+		if got := NotApplicable(tt.err); got != tt.expected {
+			t.Errorf("NotApplicable(%v) = %v, expected %v", tt.err, got, tt.expected)
+		}
+	}
+}
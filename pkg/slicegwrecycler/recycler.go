@@ -2,13 +2,14 @@ package slicegwrecycler
 
 import (
 	"context"
+	"fmt"
 
 	kubeslicev1beta1 "github.com/kubeslice/worker-operator/api/v1beta1"
 
 	"github.com/kubeslice/kubeslice-monitoring/pkg/events"
 	ossEvents "github.com/kubeslice/worker-operator/events"
 
-	hub "github.com/kubeslice/worker-operator/pkg/hub/hubclient"
+	"github.com/kubeslice/worker-operator/controllers"
 	"github.com/kubeslice/worker-operator/pkg/logger"
 	"github.com/kubeslice/worker-operator/pkg/utils"
 
@@ -19,15 +20,20 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// HubClientProvider creates the workerslicegwrecycler objects on the controller cluster
+type HubClientProvider interface {
+	CreateWorkerSliceGwRecycler(ctx context.Context, gwRecyclerName, clientID, serverID, sliceGwServer, sliceGwClient, slice string) error
+}
+
 type recyclerClient struct {
-	controllerClient client.Client
+	controllerClient HubClientProvider
 	workerClient     client.Client
 	ctx              context.Context
 	eventRecorder    *events.EventRecorder
 	scheme           *runtime.Scheme
 }
 
-func NewRecyclerClient(ctx context.Context, workerClient client.Client, controllerClient client.Client, er *events.EventRecorder, scheme *runtime.Scheme) (*recyclerClient, error) {
+func NewRecyclerClient(ctx context.Context, workerClient client.Client, controllerClient HubClientProvider, er *events.EventRecorder, scheme *runtime.Scheme) (*recyclerClient, error) {
 	return &recyclerClient{
 		controllerClient: controllerClient,
 		workerClient:     workerClient,
@@ -42,9 +48,12 @@ func (r recyclerClient) TriggerFSM(sliceGw *kubeslicev1beta1.SliceGateway, slice
 	// start FSM for graceful termination of gateway pods
 	// create workerslicegwrecycler on controller
 	log := logger.FromContext(r.ctx).WithName("fsm-recycler")
+	if controllers.SliceInMaintenance(slice) {
+		return fmt.Errorf("slice %s is in maintenance, not recycling the gateway pods", sliceGw.Spec.SliceName)
+	}
 
 	log.Info("creating workerslicegwrecycler", "gwRecyclerName", serverID, "slicegateway", sliceGw.Name)
-	err := r.controllerClient.(*hub.HubClientConfig).CreateWorkerSliceGwRecycler(r.ctx,
+	err := r.controllerClient.CreateWorkerSliceGwRecycler(r.ctx,
 		serverID,           // recycler name
 		clientID, serverID, // gateway pod pairs to recycle
 		sliceGw.Name, sliceGw.Status.Config.SliceGatewayRemoteGatewayID, // slice gateway server and client name
@@ -0,0 +1,136 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package tracing exports OpenTelemetry traces of the operator over OTLP. Spans cover the reconciles of the
+// controllers, the calls to the hub and the gRPC calls to the sidecars. The trace context is propagated
+// over the gRPC metadata, so that the sidecars can join the traces of the operator.
+package tracing
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	instrumentationName = "github.com/kubeslice/worker-operator"
+	serviceName         = "kubeslice-worker-operator"
+)
+
+var (
+	// Endpoint is the OTLP endpoint the traces are exported to, eg: otel-collector.monitoring:4317.
+	// Tracing is disabled if neither the endpoint of the traces nor the one of every signal is set.
+	// The exporter is further configured by the other OTEL_EXPORTER_OTLP_* environment variables,
+	// and the sampler by OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG.
+	Endpoint = firstNonEmpty(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"), os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))
+)
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Setup installs the global tracer provider exporting to Endpoint and the W3C trace context propagator.
+// The returned function flushes the pending spans and stops the exporter.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// StartSpan starts a span of the operator, it is a no-op span while tracing is disabled
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records err on span, if any, and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// DialOption returns the option that traces the calls of a gRPC client connection and propagates
+// the trace context to the server
+func DialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
+
+type tracingReconciler struct {
+	name       string
+	reconciler reconcile.Reconciler
+}
+
+// NewReconciler wraps r so that each of its reconciles is traced in a span named after the controller.
+// The span records the result of the reconcile, so that the time spent waiting on a requeue shows in the trace.
+func NewReconciler(name string, r reconcile.Reconciler) reconcile.Reconciler {
+	return &tracingReconciler{name: name, reconciler: r}
+}
+
+func (t *tracingReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	ctx, span := StartSpan(ctx, t.name+".Reconcile",
+		attribute.String("k8s.controller", t.name),
+		attribute.String("k8s.namespace.name", req.Namespace),
+		attribute.String("k8s.object.name", req.Name),
+	)
+	result, err := t.reconciler.Reconcile(ctx, req)
+	span.SetAttributes(
+		attribute.Bool("reconcile.requeue", result.Requeue),
+		attribute.String("reconcile.requeue_after", result.RequeueAfter.Round(time.Millisecond).String()),
+	)
+	EndSpan(span, err)
+	return result, err
+}
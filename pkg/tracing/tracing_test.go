@@ -0,0 +1,95 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type recordingExporter struct {
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (e *recordingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func TestReconcilerSpan(t *testing.T) {
+	exporter := &recordingExporter{}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(previous)
+
+	reconcileErr := errors.New("hub unavailable")
+	r := NewReconciler("slice", reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		// the spans started by the reconciler are children of the reconcile span
+		_, span := StartSpan(ctx, "hub.UpdateAppPodsList")
+		EndSpan(span, reconcileErr)
+		return reconcile.Result{RequeueAfter: 10 * time.Second}, reconcileErr
+	}))
+	_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "kubeslice-system", Name: "red"}})
+	if err != reconcileErr {
+		t.Fatalf("expected the error of the reconciler, got %v", err)
+	}
+
+	if len(exporter.spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(exporter.spans))
+	}
+	child, parent := exporter.spans[0], exporter.spans[1]
+	if parent.Name() != "slice.Reconcile" {
+		t.Errorf("expected slice.Reconcile, got %s", parent.Name())
+	}
+	if child.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expected %s to be a child of the reconcile span", child.Name())
+	}
+	if parent.Status().Code != codes.Error {
+		t.Errorf("expected the error status, got %v", parent.Status())
+	}
+	attrs := map[attribute.Key]string{}
+	for _, a := range parent.Attributes() {
+		attrs[a.Key] = a.Value.Emit()
+	}
+	expected := map[attribute.Key]string{
+		"k8s.controller":          "slice",
+		"k8s.namespace.name":      "kubeslice-system",
+		"k8s.object.name":         "red",
+		"reconcile.requeue_after": "10s",
+	}
+	for k, v := range expected {
+		if attrs[k] != v {
+			t.Errorf("expected %s=%s, got %s", k, v, attrs[k])
+		}
+	}
+}
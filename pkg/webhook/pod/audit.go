@@ -0,0 +1,142 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package pod
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeslice/kubeslice-monitoring/pkg/events"
+	"github.com/kubeslice/kubeslice-monitoring/pkg/metrics"
+	ossEvents "github.com/kubeslice/worker-operator/events"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/monitoring"
+	"github.com/kubeslice/worker-operator/pkg/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// AuditModeLabelKey puts the webhook in audit mode for the objects of a labelled namespace
+	AuditModeLabelKey = "kubeslice.io/webhook-audit"
+
+	auditDecisionMutate = "mutate"
+	auditDecisionDeny   = "deny"
+	auditDecisionError  = "error"
+
+	auditReportingInstance = "webhook"
+
+	// audit annotations added to the API server audit log of the requests allowed in audit mode
+	auditAnnotationDecision = "kubeslice.io/audit-decision"
+	auditAnnotationPatch    = "kubeslice.io/audit-patch"
+)
+
+// AuditRecorder records the decisions the webhook would have enforced while in audit mode
+type AuditRecorder struct {
+	EventRecorder *events.EventRecorder
+	// NamespaceEventRecorder records the decisions on objects created with generateName, which have no name
+	// yet, on their namespace
+	NamespaceEventRecorder *monitoring.EventRecorder
+	counterDecisions       *prometheus.CounterVec
+}
+
+func NewAuditRecorder(er *events.EventRecorder, nsEr *monitoring.EventRecorder, mf metrics.MetricsFactory) *AuditRecorder {
+	return &AuditRecorder{
+		EventRecorder:          er,
+		NamespaceEventRecorder: nsEr,
+		counterDecisions:       mf.NewCounter("webhook_audit_decisions_total", "Admission decisions not enforced by the webhook in audit mode", []string{"kind", "namespace", "decision"}),
+	}
+}
+
+// auditModeEnabled returns true if the webhook is in audit mode globally or for the namespace
+func (wh *WebhookServer) auditModeEnabled(ctx context.Context, namespace string) bool {
+	if wh.AuditMode {
+		return true
+	}
+	log := logger.FromContext(ctx)
+	nsLabels, err := wh.SliceInfoClient.GetNamespaceLabels(ctx, wh.Client, namespace)
+	if err != nil {
+		log.Error(err, "Error getting namespace labels")
+		return false
+	}
+	return nsLabels[AuditModeLabelKey] == "true"
+}
+
+// audit allows the request and records the decision the webhook would have enforced.
+// wouldBe is the response returned by the webhook outside of audit mode, its patch operations are logged and
+// added to the audit annotations of the request.
+func (wh *WebhookServer) audit(ctx context.Context, req admission.Request, obj client.Object, decision, reason string, wouldBe admission.Response) admission.Response {
+	log := logger.FromContext(ctx)
+	kind := req.Kind.Kind
+	patch, _ := json.Marshal(wouldBe.Patches)
+	log.Info("audit mode: admission decision not enforced", "kind", kind, "name", obj.GetName(), "namespace", obj.GetNamespace(),
+		"uid", req.UID, "decision", decision, "reason", reason, "patch", string(patch))
+
+	if wh.AuditRecorder != nil {
+		wh.AuditRecorder.counterDecisions.WithLabelValues(kind, obj.GetNamespace(), decision).Inc()
+		eventName := ossEvents.EventWebhookAuditMutationSkipped
+		if decision != auditDecisionMutate {
+			eventName = ossEvents.EventWebhookAuditDenialSkipped
+		}
+		if obj.GetName() == "" {
+			wh.recordNamespaceAuditEvent(ctx, req, obj, eventName, reason)
+		} else if wh.AuditRecorder.EventRecorder != nil {
+			utils.RecordEvent(ctx, wh.AuditRecorder.EventRecorder, obj, nil, eventName, auditReportingInstance)
+		}
+	}
+
+	resp := admission.Allowed("").WithWarnings(fmt.Sprintf("kubeslice webhook audit mode: %s", reason))
+	resp.AuditAnnotations = map[string]string{
+		auditAnnotationDecision: decision,
+	}
+	if len(wouldBe.Patches) > 0 {
+		resp.AuditAnnotations[auditAnnotationPatch] = string(patch)
+	}
+	return resp
+}
+
+// recordNamespaceAuditEvent records the audit event of an object created with generateName on its namespace.
+// The object has no name yet, the generateName prefix and the request UID in the message tell the requests apart.
+func (wh *WebhookServer) recordNamespaceAuditEvent(ctx context.Context, req admission.Request, obj client.Object, eventName events.EventName, reason string) {
+	if wh.AuditRecorder.NamespaceEventRecorder == nil {
+		return
+	}
+	log := logger.FromContext(ctx)
+	eventType := monitoring.EventTypeNormal
+	if eventName == ossEvents.EventWebhookAuditDenialSkipped {
+		eventType = monitoring.EventTypeWarning
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: obj.GetNamespace()}}
+	// namespaces are cluster scoped, the event is created in the namespace itself
+	err := wh.AuditRecorder.NamespaceEventRecorder.WithNamespace(ns.Name).RecordEvent(ctx, &monitoring.Event{
+		Object:            ns,
+		EventType:         eventType,
+		Reason:            string(eventName),
+		Message:           fmt.Sprintf("Webhook in audit mode - %s %s* (request %s): %s", req.Kind.Kind, obj.GetGenerateName(), req.UID, reason),
+		Action:            "None",
+		ReportingInstance: auditReportingInstance,
+	})
+	if err != nil {
+		log.Error(err, "unable to raise event")
+	}
+}
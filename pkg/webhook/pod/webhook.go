@@ -29,9 +29,9 @@ import (
 	"github.com/kubeslice/worker-operator/controllers"
 	"github.com/kubeslice/worker-operator/pkg/logger"
 	v1 "k8s.io/api/admission/v1"
-	appsv1 "k8s.io/api/apps/v1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -62,94 +62,16 @@ type WebhookServer struct {
 	Client          client.Client
 	Decoder         *admission.Decoder
 	SliceInfoClient SliceInfoProvider
+	// AuditMode allows every request and only records the decisions of the webhook.
+	// It can be enabled per namespace with the AuditModeLabelKey label.
+	AuditMode     bool
+	AuditRecorder *AuditRecorder
 }
 
 func (wh *WebhookServer) Handle(ctx context.Context, req admission.Request) admission.Response {
-	if req.Kind.Kind == "Pod" {
-		pod := &corev1.Pod{}
-		err := wh.Decoder.Decode(req, pod)
-		if err != nil {
-			return admission.Errored(http.StatusBadRequest, err)
-		}
-		log := logger.FromContext(ctx)
-
-		// handle empty namespace field when the pod is created by deployment
-		if pod.ObjectMeta.Namespace == "" {
-			pod.ObjectMeta.Namespace = req.Namespace
-		}
-
-		if mutate, sliceName := wh.MutationRequired(pod.ObjectMeta, ctx, req.Kind.Kind); !mutate {
-			log.Info("mutation not required for pod", "pod metadata", pod.ObjectMeta.Name)
-		} else {
-			log.Info("mutating pod", "pod metadata", pod.ObjectMeta.Name)
-			pod = MutatePod(pod, sliceName)
-		}
-
-		marshaled, err := json.Marshal(pod)
-		if err != nil {
-			return admission.Errored(http.StatusInternalServerError, err)
-		}
-		return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
-	} else if req.Kind.Kind == "Deployment" {
-		deploy := &appsv1.Deployment{}
-		log := logger.FromContext(ctx)
-		err := wh.Decoder.Decode(req, deploy)
-		if err != nil {
-			return admission.Errored(http.StatusBadRequest, err)
-		}
-
-		if mutate, sliceName := wh.MutationRequired(deploy.ObjectMeta, ctx, req.Kind.Kind); !mutate {
-			log.Info("mutation not required for deployment", "pod metadata", deploy.Spec.Template.ObjectMeta)
-		} else {
-			deploy = MutateDeployment(deploy, sliceName)
-			log.Info("mutated deploy", "pod metadata", deploy.Spec.Template.ObjectMeta)
-		}
-
-		marshaled, err := json.Marshal(deploy)
-		if err != nil {
-			return admission.Errored(http.StatusInternalServerError, err)
-		}
-		return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
-	} else if req.Kind.Kind == "StatefulSet" {
-		statefulset := &appsv1.StatefulSet{}
-		err := wh.Decoder.Decode(req, statefulset)
-		if err != nil {
-			return admission.Errored(http.StatusBadRequest, err)
-		}
-		log := logger.FromContext(ctx)
-
-		if mutate, sliceName := wh.MutationRequired(statefulset.ObjectMeta, ctx, req.Kind.Kind); !mutate {
-			log.Info("mutation not required for statefulsets", "pod metadata", statefulset.Spec.Template.ObjectMeta)
-		} else {
-			statefulset = MutateStatefulset(statefulset, sliceName)
-			log.Info("mutated statefulset", "pod metadata", statefulset.Spec.Template.ObjectMeta)
-		}
-
-		marshaled, err := json.Marshal(statefulset)
-		if err != nil {
-			return admission.Errored(http.StatusInternalServerError, err)
-		}
-		return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
-	} else if req.Kind.Kind == "DaemonSet" {
-		daemonset := &appsv1.DaemonSet{}
-		err := wh.Decoder.Decode(req, daemonset)
-		if err != nil {
-			return admission.Errored(http.StatusBadRequest, err)
-		}
-		log := logger.FromContext(ctx)
-
-		if mutate, sliceName := wh.MutationRequired(daemonset.ObjectMeta, ctx, req.Kind.Kind); !mutate {
-			log.Info("mutation not required for daemonset", "pod metadata", daemonset.Spec.Template.ObjectMeta)
-		} else {
-			daemonset = MutateDaemonSet(daemonset, sliceName)
-			log.Info("mutated daemonset", "pod metadata", daemonset.Spec.Template.ObjectMeta)
-		}
-
-		marshaled, err := json.Marshal(daemonset)
-		if err != nil {
-			return admission.Errored(http.StatusInternalServerError, err)
-		}
-		return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+	gk := schema.GroupKind{Group: req.Kind.Group, Kind: req.Kind.Kind}
+	if podTemplatePath, ok := getPodTemplatePath(gk); ok {
+		return wh.handleWorkload(ctx, req, podTemplatePath)
 	} else if req.Kind.Kind == "ServiceExport" {
 		serviceexport := &v1beta1.ServiceExport{}
 		err := wh.Decoder.Decode(req, serviceexport)
@@ -159,13 +81,38 @@ func (wh *WebhookServer) Handle(ctx context.Context, req admission.Request) admi
 		log := logger.FromContext(ctx)
 
 		log.Info("validating serviceexport", "serviceexport spec", serviceexport.Spec)
+		if serviceexport.Namespace == "" {
+			serviceexport.Namespace = req.Namespace
+		}
+		// new serviceexports are not allowed while the namespace is leaving a slice
+		if req.Operation == v1.Create {
+			if offboardingSlice := wh.getOffboardingSlice(ctx, serviceexport.Namespace); offboardingSlice != "" {
+				reason := fmt.Sprintf("Namespace %s is being offboarded from slice %s", serviceexport.Namespace, offboardingSlice)
+				log.Info("serviceexport validation failed: namespace is being offboarded", "serviceexport-name", serviceexport.ObjectMeta.Name)
+				resp := admission.Denied(reason)
+				if wh.auditModeEnabled(ctx, serviceexport.Namespace) {
+					return wh.audit(ctx, req, serviceexport, auditDecisionDeny, reason, resp)
+				}
+				return resp
+			}
+		}
 		validation, conflictingAlias, err := wh.ValidateServiceExport(serviceexport, ctx)
 		if err != nil {
-			return admission.Errored(http.StatusInternalServerError, err)
+			resp := admission.Errored(http.StatusInternalServerError, err)
+			if wh.auditModeEnabled(ctx, serviceexport.Namespace) {
+				reason := fmt.Sprintf("Unable to validate the aliases: %v", err)
+				return wh.audit(ctx, req, serviceexport, auditDecisionError, reason, resp)
+			}
+			return resp
 		}
 		if !validation {
 			log.Info("serviceexport validation failed: alias already exist", "serviceexport-name", serviceexport.ObjectMeta.Name)
-			return admission.Denied(fmt.Sprintf("Alias %s already exist", conflictingAlias))
+			reason := fmt.Sprintf("Alias %s already exist", conflictingAlias)
+			resp := admission.Denied(reason)
+			if wh.auditModeEnabled(ctx, serviceexport.Namespace) {
+				return wh.audit(ctx, req, serviceexport, auditDecisionDeny, reason, resp)
+			}
+			return resp
 		}
 		return admission.Allowed("")
 	}
@@ -177,117 +124,58 @@ func (wh *WebhookServer) Handle(ctx context.Context, req admission.Request) admi
 	}}
 }
 
-func MutatePod(pod *corev1.Pod, sliceName string) *corev1.Pod {
-	// Add injection status to pod annotations
-	if pod.ObjectMeta.Annotations == nil {
-		pod.ObjectMeta.Annotations = map[string]string{}
-	}
-	pod.ObjectMeta.Annotations[AdmissionWebhookAnnotationStatusKey] = "injected"
-
-	// Initialize the Labels field as an empty map
-	if pod.ObjectMeta.Labels == nil {
-		pod.ObjectMeta.Labels = map[string]string{}
+// handleWorkload mutates the pod template of any registered workload kind
+func (wh *WebhookServer) handleWorkload(ctx context.Context, req admission.Request, podTemplatePath []string) admission.Response {
+	obj := &unstructured.Unstructured{}
+	err := wh.Decoder.Decode(req, obj)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
 	}
+	log := logger.FromContext(ctx)
 
-	// Add vl3 annotation to pod template
-	annotations := pod.ObjectMeta.Annotations
-	annotations[nsmInjectAnnotaionKey1] = "vl3-service-" + sliceName
-	annotations[nsmInjectAnnotaionKey2] = fmt.Sprintf("kernel://vl3-service-%s/nsm0", sliceName)
-
-	// Add slice identifier labels to pod template
-	labels := pod.ObjectMeta.Labels
-	labels[PodInjectLabelKey] = "app"
-	labels[admissionWebhookAnnotationInjectKey] = sliceName
-
-	return pod
-}
-
-func MutateDeployment(deploy *appsv1.Deployment, sliceName string) *appsv1.Deployment {
-	// Add injection status to deployment annotations
-	if deploy.Spec.Template.ObjectMeta.Annotations == nil {
-		deploy.Spec.Template.ObjectMeta.Annotations = map[string]string{}
+	objMeta := metav1.ObjectMeta{
+		Name:            obj.GetName(),
+		Namespace:       obj.GetNamespace(),
+		Labels:          obj.GetLabels(),
+		Annotations:     obj.GetAnnotations(),
+		OwnerReferences: obj.GetOwnerReferences(),
 	}
-
-	deploy.Spec.Template.ObjectMeta.Annotations[AdmissionWebhookAnnotationStatusKey] = "injected"
-
-	// Add vl3 annotation to pod template
-	annotations := deploy.Spec.Template.ObjectMeta.Annotations
-	annotations[nsmInjectAnnotaionKey1] = "vl3-service-" + sliceName
-	annotations[nsmInjectAnnotaionKey2] = fmt.Sprintf("kernel://vl3-service-%s/nsm0", sliceName)
-
-	if deploy.Spec.Template.ObjectMeta.Labels == nil {
-		deploy.Spec.Template.ObjectMeta.Labels = map[string]string{}
+	// handle empty namespace field when the pod is created by deployment
+	if objMeta.Namespace == "" {
+		objMeta.Namespace = req.Namespace
 	}
-	// Add slice identifier labels to pod template
-	labels := deploy.Spec.Template.ObjectMeta.Labels
-	labels[PodInjectLabelKey] = "app"
-	labels[admissionWebhookAnnotationInjectKey] = sliceName
 
-	if deploy.ObjectMeta.Labels == nil {
-		deploy.ObjectMeta.Labels = make(map[string]string)
+	mutate, sliceName := wh.MutationRequired(objMeta, ctx, req.Kind.Kind)
+	if !mutate {
+		log.Info("mutation not required", "kind", req.Kind.Kind, "name", objMeta.Name)
+		return admission.Allowed("")
 	}
-	deploy.ObjectMeta.Labels[admissionWebhookAnnotationInjectKey] = sliceName
-
-	return deploy
-}
-
-func MutateStatefulset(ss *appsv1.StatefulSet, sliceName string) *appsv1.StatefulSet {
-	// Add injection status to statefulset annotations
-	if ss.Spec.Template.ObjectMeta.Annotations == nil {
-		ss.Spec.Template.ObjectMeta.Annotations = map[string]string{}
+	if err := MutateWorkload(obj, podTemplatePath, sliceName); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
 	}
-
-	ss.Spec.Template.ObjectMeta.Annotations[AdmissionWebhookAnnotationStatusKey] = "injected"
-
-	// Add vl3 annotation to pod template
-	annotations := ss.Spec.Template.ObjectMeta.Annotations
-	annotations[nsmInjectAnnotaionKey1] = "vl3-service-" + sliceName
-	annotations[nsmInjectAnnotaionKey2] = fmt.Sprintf("kernel://vl3-service-%s/nsm0", sliceName)
-
-	if ss.Spec.Template.ObjectMeta.Labels == nil {
-		ss.Spec.Template.ObjectMeta.Labels = map[string]string{}
+	marshaled, err := json.Marshal(obj.Object)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
 	}
-	// Add slice identifier labels to pod template
-	labels := ss.Spec.Template.ObjectMeta.Labels
-	labels[PodInjectLabelKey] = "app"
-	labels[admissionWebhookAnnotationInjectKey] = sliceName
-
-	if ss.ObjectMeta.Labels == nil {
-		ss.ObjectMeta.Labels = make(map[string]string)
+	resp := admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+	if wh.auditModeEnabled(ctx, objMeta.Namespace) {
+		obj.SetNamespace(objMeta.Namespace)
+		reason := fmt.Sprintf("%s %s would be onboarded to slice %s", req.Kind.Kind, objMeta.Name, sliceName)
+		return wh.audit(ctx, req, obj, auditDecisionMutate, reason, resp)
 	}
-	ss.ObjectMeta.Labels[admissionWebhookAnnotationInjectKey] = sliceName
-
-	return ss
+	log.Info("mutated workload", "kind", req.Kind.Kind, "name", objMeta.Name)
+	return resp
 }
 
-func MutateDaemonSet(ds *appsv1.DaemonSet, sliceName string) *appsv1.DaemonSet {
-	// Add injection status to statefulset annotations
-	if ds.Spec.Template.ObjectMeta.Annotations == nil {
-		ds.Spec.Template.ObjectMeta.Annotations = map[string]string{}
-	}
-
-	ds.Spec.Template.ObjectMeta.Annotations[AdmissionWebhookAnnotationStatusKey] = "injected"
-
-	// Add vl3 annotation to pod template
-	annotations := ds.Spec.Template.ObjectMeta.Annotations
-	annotations[nsmInjectAnnotaionKey1] = "vl3-service-" + sliceName
-	annotations[nsmInjectAnnotaionKey2] = fmt.Sprintf("kernel://vl3-service-%s/nsm0", sliceName)
-
-	if ds.Spec.Template.ObjectMeta.Labels == nil {
-		ds.Spec.Template.ObjectMeta.Labels = map[string]string{}
-	}
-	// Add slice identifier labels to pod template
-	labels := ds.Spec.Template.ObjectMeta.Labels
-	labels[PodInjectLabelKey] = "app"
-	labels[admissionWebhookAnnotationInjectKey] = sliceName
-
-	// add slice identifier labels to object
-	if ds.ObjectMeta.Labels == nil {
-		ds.ObjectMeta.Labels = make(map[string]string)
+// getOffboardingSlice returns the slice the namespace is being offboarded from, if any
+func (wh *WebhookServer) getOffboardingSlice(ctx context.Context, namespace string) string {
+	log := logger.FromContext(ctx)
+	nsLabels, err := wh.SliceInfoClient.GetNamespaceLabels(ctx, wh.Client, namespace)
+	if err != nil {
+		log.Error(err, "Error getting namespace labels")
+		return ""
 	}
-	ds.ObjectMeta.Labels[admissionWebhookAnnotationInjectKey] = sliceName
-
-	return ds
+	return nsLabels[controllers.ApplicationNamespaceOffboardingLabelKey]
 }
 
 func (wh *WebhookServer) ValidateServiceExport(svcex *v1beta1.ServiceExport, ctx context.Context) (bool, string, error) {
@@ -331,6 +219,14 @@ func (wh *WebhookServer) MutationRequired(metadata metav1.ObjectMeta, ctx contex
 		}
 	}
 
+	// only the top-level workload is mutated, the objects created by a registered workload, such as
+	// the replicasets of a deployment or the jobs of a cronjob, get the pod template of their owner.
+	// Objects created by other controllers are mutated themselves.
+	if owner := metav1.GetControllerOfNoCopy(&metadata); owner != nil && isWorkloadOwner(owner) {
+		log.Info("obj is owned by a workload", "kind", kind, "owner", owner.Kind)
+		return false, ""
+	}
+
 	//early exit if metadata in nil
 	//we allow empty annotation, but namespace should not be empty
 	if metadata.GetNamespace() == "" {
@@ -20,16 +20,27 @@ package pod_test
 
 import (
 	"context"
+	"errors"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/kubeslice/apis/pkg/controller/v1alpha1"
+	"github.com/kubeslice/kubeslice-monitoring/pkg/metrics"
 	"github.com/kubeslice/worker-operator/api/v1beta1"
 	"github.com/kubeslice/worker-operator/controllers"
+	"github.com/kubeslice/worker-operator/pkg/logger"
+	"github.com/kubeslice/worker-operator/pkg/monitoring"
 	"github.com/kubeslice/worker-operator/pkg/webhook/pod"
+	"github.com/prometheus/client_golang/prometheus"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 type fakeWebhookClient struct{}
@@ -264,3 +275,262 @@ var _ = Describe("Validating Webhook", func() {
 		})
 	})
 })
+
+var _ = Describe("Workload Webhook", func() {
+	webhookServer := pod.WebhookServer{
+		SliceInfoClient: new(fakeWebhookClient),
+		Decoder:         admission.NewDecoder(runtime.NewScheme()),
+	}
+	newRequest := func(gvk metav1.GroupVersionKind, raw string) admission.Request {
+		return admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Kind:      gvk,
+				Namespace: "test-ns",
+				Object:    runtime.RawExtension{Raw: []byte(raw)},
+			},
+		}
+	}
+	patchedPaths := func(resp admission.Response) []string {
+		paths := []string{}
+		for _, patch := range resp.Patches {
+			paths = append(paths, patch.Path)
+		}
+		return paths
+	}
+
+	Describe("Handle", func() {
+		It("should mutate the pod template of a job", func() {
+			req := newRequest(metav1.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"},
+				`{"apiVersion":"batch/v1","kind":"Job","metadata":{"name":"job","namespace":"test-ns"},"spec":{"template":{"spec":{"containers":[]}}}}`)
+			resp := webhookServer.Handle(context.Background(), req)
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(patchedPaths(resp)).To(ContainElements("/metadata/labels", "/spec/template/metadata"))
+		})
+
+		It("should mutate the job template of a cronjob", func() {
+			req := newRequest(metav1.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"},
+				`{"apiVersion":"batch/v1","kind":"CronJob","metadata":{"name":"cron","namespace":"test-ns"},"spec":{"jobTemplate":{"spec":{"template":{"spec":{"containers":[]}}}}}}`)
+			resp := webhookServer.Handle(context.Background(), req)
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(patchedPaths(resp)).To(ContainElements("/metadata/labels", "/spec/jobTemplate/spec/template/metadata"))
+		})
+
+		It("should skip an already injected replicaset", func() {
+			req := newRequest(metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"},
+				`{"apiVersion":"apps/v1","kind":"ReplicaSet","metadata":{"name":"rs","namespace":"test-ns","annotations":{"kubeslice.io/status":"injected"}},"spec":{"template":{}}}`)
+			resp := webhookServer.Handle(context.Background(), req)
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patches).To(BeEmpty())
+		})
+
+		It("should skip a replicaset owned by a deployment", func() {
+			req := newRequest(metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"},
+				`{"apiVersion":"apps/v1","kind":"ReplicaSet","metadata":{"name":"rs","namespace":"test-ns","ownerReferences":[{"apiVersion":"apps/v1","kind":"Deployment","name":"web","uid":"web-uid","controller":true}]},"spec":{"template":{}}}`)
+			resp := webhookServer.Handle(context.Background(), req)
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patches).To(BeEmpty())
+		})
+
+		It("should mutate a pod without a controller", func() {
+			req := newRequest(metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"},
+				`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"test-ns","ownerReferences":[{"apiVersion":"v1","kind":"ConfigMap","name":"cfg","uid":"cfg-uid"}]},"spec":{"containers":[]}}`)
+			resp := webhookServer.Handle(context.Background(), req)
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patches).NotTo(BeEmpty())
+		})
+
+		It("should mutate a pod controlled by an unregistered kind", func() {
+			req := newRequest(metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"},
+				`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"test-ns","ownerReferences":[{"apiVersion":"sparkoperator.k8s.io/v1beta2","kind":"SparkApplication","name":"spark","uid":"spark-uid","controller":true}]},"spec":{"containers":[]}}`)
+			resp := webhookServer.Handle(context.Background(), req)
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(patchedPaths(resp)).To(ContainElements("/metadata/annotations", "/metadata/labels"))
+		})
+
+		It("should skip a pod owned by a replicaset", func() {
+			req := newRequest(metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"},
+				`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"test-ns","ownerReferences":[{"apiVersion":"apps/v1","kind":"ReplicaSet","name":"rs","uid":"rs-uid","controller":true}]},"spec":{"containers":[]}}`)
+			resp := webhookServer.Handle(context.Background(), req)
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patches).To(BeEmpty())
+		})
+
+		It("should reject unregistered kinds", func() {
+			req := newRequest(metav1.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Workflow"},
+				`{"apiVersion":"argoproj.io/v1alpha1","kind":"Workflow","metadata":{"name":"wf","namespace":"test-ns"}}`)
+			resp := webhookServer.Handle(context.Background(), req)
+			Expect(resp.Allowed).To(BeFalse())
+		})
+
+		It("should mutate a registered custom workload", func() {
+			Expect(pod.RegisterCustomWorkloadKinds("Rollout.argoproj.io=spec.template")).To(Succeed())
+			req := newRequest(metav1.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"},
+				`{"apiVersion":"argoproj.io/v1alpha1","kind":"Rollout","metadata":{"name":"rollout","namespace":"test-ns"},"spec":{"template":{"metadata":{"labels":{"app":"web"}}}}}`)
+			resp := webhookServer.Handle(context.Background(), req)
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(patchedPaths(resp)).To(ContainElements("/metadata/labels", "/spec/template/metadata/annotations"))
+		})
+	})
+
+	Describe("RegisterCustomWorkloadKinds", func() {
+		It("should reject malformed entries", func() {
+			Expect(pod.RegisterCustomWorkloadKinds("Rollout.argoproj.io")).NotTo(Succeed())
+			Expect(pod.RegisterCustomWorkloadKinds("Rollout.argoproj.io=")).NotTo(Succeed())
+			Expect(pod.RegisterCustomWorkloadKinds(" , ")).To(Succeed())
+		})
+	})
+})
+
+var _ = Describe("Audit mode", func() {
+	mf, _ := metrics.NewMetricsFactory(prometheus.NewRegistry(), metrics.MetricsFactoryOptions{})
+	webhookServer := pod.WebhookServer{
+		SliceInfoClient: new(fakeWebhookClient),
+		Decoder:         admission.NewDecoder(runtime.NewScheme()),
+		AuditMode:       true,
+		AuditRecorder:   pod.NewAuditRecorder(nil, nil, mf),
+	}
+
+	It("should allow a workload without patching it", func() {
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+				Namespace: "test-ns",
+				Object: runtime.RawExtension{
+					Raw: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","namespace":"test-ns"},"spec":{"template":{}}}`),
+				},
+			},
+		}
+		resp := webhookServer.Handle(context.Background(), req)
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patches).To(BeEmpty())
+		Expect(resp.Warnings).To(HaveLen(1))
+		Expect(resp.Warnings[0]).To(ContainSubstring("would be onboarded to slice green"))
+		Expect(resp.AuditAnnotations).To(HaveKeyWithValue("kubeslice.io/audit-decision", "mutate"))
+		Expect(resp.AuditAnnotations).To(HaveKeyWithValue("kubeslice.io/audit-patch", ContainSubstring(`"op":"add"`)))
+	})
+
+	It("should allow a serviceexport with a conflicting alias", func() {
+		scheme := runtime.NewScheme()
+		Expect(v1beta1.AddToScheme(scheme)).To(Succeed())
+		webhookServer.Decoder = admission.NewDecoder(scheme)
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: "networking.kubeslice.io", Version: "v1beta1", Kind: "ServiceExport"},
+				Namespace: "test-ns",
+				Object: runtime.RawExtension{
+					Raw: []byte(`{"apiVersion":"networking.kubeslice.io/v1beta1","kind":"ServiceExport","metadata":{"name":"svcex","namespace":"test-ns"},"spec":{"slice":"test-slice","aliases":["server.com"]}}`),
+				},
+			},
+		}
+		resp := webhookServer.Handle(context.Background(), req)
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Warnings).To(HaveLen(1))
+		Expect(resp.Warnings[0]).To(ContainSubstring("Alias server.com already exist"))
+		Expect(resp.AuditAnnotations).To(HaveKeyWithValue("kubeslice.io/audit-decision", "deny"))
+	})
+
+	It("should allow a serviceexport whose aliases cannot be validated", func() {
+		scheme := runtime.NewScheme()
+		Expect(v1beta1.AddToScheme(scheme)).To(Succeed())
+		erroringServer := webhookServer
+		erroringServer.SliceInfoClient = new(erroringWebhookClient)
+		erroringServer.Decoder = admission.NewDecoder(scheme)
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: "networking.kubeslice.io", Version: "v1beta1", Kind: "ServiceExport"},
+				Namespace: "test-ns",
+				Object: runtime.RawExtension{
+					Raw: []byte(`{"apiVersion":"networking.kubeslice.io/v1beta1","kind":"ServiceExport","metadata":{"name":"svcex","namespace":"test-ns"},"spec":{"slice":"test-slice","aliases":["server.com"]}}`),
+				},
+			},
+		}
+		resp := erroringServer.Handle(context.Background(), req)
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Warnings).To(HaveLen(1))
+		Expect(resp.Warnings[0]).To(ContainSubstring("Unable to validate the aliases"))
+		Expect(resp.AuditAnnotations).To(HaveKeyWithValue("kubeslice.io/audit-decision", "error"))
+	})
+
+	It("should record the decision on a generateName pod on its namespace", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		mf, _ := metrics.NewMetricsFactory(prometheus.NewRegistry(), metrics.MetricsFactoryOptions{})
+		generateNameServer := webhookServer
+		generateNameServer.AuditRecorder = pod.NewAuditRecorder(nil, &monitoring.EventRecorder{
+			Client: c,
+			Scheme: scheme,
+			Logger: logger.NewLogger(),
+		}, mf)
+		generateNameServer.Decoder = admission.NewDecoder(scheme)
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UID:       "req-uid",
+				Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+				Namespace: "test-ns",
+				Object: runtime.RawExtension{
+					Raw: []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"generateName":"web-","namespace":"test-ns"},"spec":{}}`),
+				},
+			},
+		}
+		resp := generateNameServer.Handle(context.Background(), req)
+		Expect(resp.Allowed).To(BeTrue())
+
+		events := &corev1.EventList{}
+		Expect(c.List(context.Background(), events)).To(Succeed())
+		Expect(events.Items).To(HaveLen(1))
+		Expect(events.Items[0].Namespace).To(Equal("test-ns"))
+		Expect(events.Items[0].InvolvedObject.Kind).To(Equal("Namespace"))
+		Expect(events.Items[0].InvolvedObject.Name).To(Equal("test-ns"))
+		Expect(events.Items[0].Message).To(ContainSubstring("web-"))
+		Expect(events.Items[0].Message).To(ContainSubstring("req-uid"))
+	})
+})
+
+type erroringWebhookClient struct {
+	fakeWebhookClient
+}
+
+func (f erroringWebhookClient) GetAllServiceExports(ctx context.Context, client client.Client, slice string) (*v1beta1.ServiceExportList, error) {
+	return nil, errors.New("unable to list serviceexports")
+}
+
+type offboardingWebhookClient struct {
+	fakeWebhookClient
+}
+
+func (f offboardingWebhookClient) GetNamespaceLabels(ctx context.Context, client client.Client, namespace string) (map[string]string, error) {
+	return map[string]string{controllers.ApplicationNamespaceOffboardingLabelKey: "green"}, nil
+}
+
+var _ = Describe("Offboarding namespace", func() {
+	scheme := runtime.NewScheme()
+	_ = v1beta1.AddToScheme(scheme)
+	webhookServer := pod.WebhookServer{
+		SliceInfoClient: new(offboardingWebhookClient),
+		Decoder:         admission.NewDecoder(scheme),
+	}
+	newRequest := func(operation admissionv1.Operation) admission.Request {
+		return admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: "networking.kubeslice.io", Version: "v1beta1", Kind: "ServiceExport"},
+				Namespace: "test-ns",
+				Operation: operation,
+				Object: runtime.RawExtension{
+					Raw: []byte(`{"apiVersion":"networking.kubeslice.io/v1beta1","kind":"ServiceExport","metadata":{"name":"svcex","namespace":"test-ns"},"spec":{"slice":"green","aliases":["new.com"]}}`),
+				},
+			},
+		}
+	}
+
+	It("should reject new serviceexports", func() {
+		resp := webhookServer.Handle(context.Background(), newRequest(admissionv1.Create))
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Result.Message).To(ContainSubstring("is being offboarded from slice green"))
+	})
+
+	It("should allow updates to existing serviceexports", func() {
+		resp := webhookServer.Handle(context.Background(), newRequest(admissionv1.Update))
+		Expect(resp.Allowed).To(BeTrue())
+	})
+})
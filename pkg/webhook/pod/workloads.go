@@ -0,0 +1,128 @@
+/*
+ *  Copyright (c) 2022 Avesha, Inc. All rights reserved.
+ *
+ *  SPDX-License-Identifier: Apache-2.0
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package pod
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// workloadKinds maps the kinds handled by the mutating webhook to the path of their pod template.
+// An empty path means the object itself is the pod.
+var workloadKinds = map[schema.GroupKind][]string{
+	{Group: "", Kind: "Pod"}:             {},
+	{Group: "apps", Kind: "Deployment"}:  {"spec", "template"},
+	{Group: "apps", Kind: "StatefulSet"}: {"spec", "template"},
+	{Group: "apps", Kind: "DaemonSet"}:   {"spec", "template"},
+	{Group: "apps", Kind: "ReplicaSet"}:  {"spec", "template"},
+	{Group: "batch", Kind: "Job"}:        {"spec", "template"},
+	{Group: "batch", Kind: "CronJob"}:    {"spec", "jobTemplate", "spec", "template"},
+}
+
+// RegisterWorkloadKind adds a workload kind to the mutating webhook. podTemplatePath is the
+// path of the pod template in the object, eg: spec.template for an argo Rollout.
+// The MutatingWebhookConfiguration needs a matching rule for the kind to reach the webhook.
+func RegisterWorkloadKind(gk schema.GroupKind, podTemplatePath []string) {
+	workloadKinds[gk] = podTemplatePath
+}
+
+// RegisterCustomWorkloadKinds parses a comma separated list of <Kind>.<group>=<pod template path>
+// entries and registers them, eg: Rollout.argoproj.io=spec.template
+func RegisterCustomWorkloadKinds(cfg string) error {
+	for _, entry := range strings.Split(cfg, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kindAndPath := strings.SplitN(entry, "=", 2)
+		if len(kindAndPath) != 2 || kindAndPath[0] == "" || kindAndPath[1] == "" {
+			return fmt.Errorf("invalid workload kind %q, expected <Kind>.<group>=<pod template path>", entry)
+		}
+		gk := schema.ParseGroupKind(kindAndPath[0])
+		RegisterWorkloadKind(gk, strings.Split(kindAndPath[1], "."))
+	}
+	return nil
+}
+
+// getPodTemplatePath returns the pod template path of a registered workload kind
+func getPodTemplatePath(gk schema.GroupKind) ([]string, bool) {
+	path, ok := workloadKinds[gk]
+	return path, ok
+}
+
+// isWorkloadOwner returns true if the owner is a registered workload kind, whose pod template is
+// mutated by the webhook
+func isWorkloadOwner(owner *metav1.OwnerReference) bool {
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return false
+	}
+	_, ok := getPodTemplatePath(gv.WithKind(owner.Kind).GroupKind())
+	return ok
+}
+
+// MutateWorkload onboards the pod template of a workload to the slice. The object itself is
+// labelled with the slice name unless it is a pod.
+func MutateWorkload(obj *unstructured.Unstructured, podTemplatePath []string, sliceName string) error {
+	templateMeta := append(append([]string{}, podTemplatePath...), "metadata")
+
+	// Add injection status and vl3 annotation to pod template
+	annotations, _, err := unstructured.NestedStringMap(obj.Object, append(templateMeta, "annotations")...)
+	if err != nil {
+		return err
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AdmissionWebhookAnnotationStatusKey] = "injected"
+	annotations[nsmInjectAnnotaionKey1] = "vl3-service-" + sliceName
+	annotations[nsmInjectAnnotaionKey2] = fmt.Sprintf("kernel://vl3-service-%s/nsm0", sliceName)
+	if err := unstructured.SetNestedStringMap(obj.Object, annotations, append(templateMeta, "annotations")...); err != nil {
+		return err
+	}
+
+	// Add slice identifier labels to pod template
+	labels, _, err := unstructured.NestedStringMap(obj.Object, append(templateMeta, "labels")...)
+	if err != nil {
+		return err
+	}
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[PodInjectLabelKey] = "app"
+	labels[admissionWebhookAnnotationInjectKey] = sliceName
+	if err := unstructured.SetNestedStringMap(obj.Object, labels, append(templateMeta, "labels")...); err != nil {
+		return err
+	}
+
+	// add slice identifier labels to object
+	if len(podTemplatePath) > 0 {
+		objLabels := obj.GetLabels()
+		if objLabels == nil {
+			objLabels = map[string]string{}
+		}
+		objLabels[admissionWebhookAnnotationInjectKey] = sliceName
+		obj.SetLabels(objLabels)
+	}
+	return nil
+}
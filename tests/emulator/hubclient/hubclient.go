@@ -78,6 +78,10 @@ func (hubClientEmulator *HubClientEmulator) GetClusterNodeIP(ctx context.Context
 	return []string{"35.235.10.1"}, nil
 }
 
+func (hubClientEmulator *HubClientEmulator) GetClusterUnderlayMTU(ctx context.Context, sliceGwName, clusterName string) (int, error) {
+	return 0, nil
+}
+
 func (hubClientEmulator *HubClientEmulator) CreateWorkerSliceGwRecycler(ctx context.Context, gwRecyclerName, clientID, serverID, sliceGwServer, sliceGwClient, slice string) error {
 	return nil
 }